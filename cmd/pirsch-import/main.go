@@ -0,0 +1,192 @@
+// Command pirsch-import imports Nginx and Apache access logs into Pirsch.
+// Aggregated statistics exported from Google Analytics or Plausible (CSV or zip) can be imported using the -csv flag.
+//
+// Usage:
+//
+//	pirsch-import -client 1 -hostname example.com -format combined -from 2023-01-01 access.log access.log.1.gz
+//	pirsch-import -client 1 -csv -to 2023-01-01 plausible-export.zip
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/importer"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/geodb"
+	"log/slog"
+	"os"
+	"time"
+)
+
+func main() {
+	format := flag.String("format", "combined", "log format: combined, common, or a custom Nginx/Apache format string")
+	clientID := flag.Uint64("client", 0, "client ID to import the logs for")
+	hostname := flag.String("hostname", "", "hostname of the website, used if the log format does not contain the host")
+	from := flag.String("from", "", "optional start date (inclusive, YYYY-MM-DD)")
+	to := flag.String("to", "", "optional end date (exclusive, YYYY-MM-DD)")
+	salt := flag.String("salt", "", "salt used for fingerprinting (must match the live tracker)")
+	key0 := flag.Uint64("key0", 0, "first fingerprint key (must match the live tracker)")
+	key1 := flag.Uint64("key1", 0, "second fingerprint key (must match the live tracker)")
+	geoDBFile := flag.String("geodb", "", "optional path to a GeoLite2 City or other MaxMind format (.mmdb) database file")
+	dbHost := flag.String("db-host", "127.0.0.1", "ClickHouse hostname")
+	dbPort := flag.Int("db-port", 9000, "ClickHouse port")
+	dbName := flag.String("db-name", "pirsch", "ClickHouse database")
+	dbUser := flag.String("db-user", "", "ClickHouse user")
+	dbPassword := flag.String("db-password", "", "ClickHouse password")
+	dbSecure := flag.Bool("db-secure", false, "use TLS for the ClickHouse connection")
+	csvExport := flag.Bool("csv", false, "import aggregated statistics from Google Analytics or Plausible CSV exports instead of access logs")
+	verbose := flag.Bool("v", false, "log invalid lines")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: pirsch-import [flags] file...")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	logLevel := slog.LevelInfo
+
+	if *verbose {
+		logLevel = slog.LevelDebug
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel}))
+	logFormat, err := parseFormat(*format)
+
+	if err != nil {
+		exit(logger, "error parsing log format", err)
+	}
+
+	fromDate, err := parseDate(*from)
+
+	if err != nil {
+		exit(logger, "error parsing start date", err)
+	}
+
+	toDate, err := parseDate(*to)
+
+	if err != nil {
+		exit(logger, "error parsing end date", err)
+	}
+
+	client, err := db.NewClient(&db.ClientConfig{
+		Hostname: *dbHost,
+		Port:     *dbPort,
+		Database: *dbName,
+		Username: *dbUser,
+		Password: *dbPassword,
+		Secure:   *dbSecure,
+		Logger:   logger,
+	})
+
+	if err != nil {
+		exit(logger, "error connecting to database", err)
+	}
+
+	if *csvExport {
+		importCSV(logger, client, *clientID, fromDate, toDate)
+		return
+	}
+
+	var geoDB geodb.Locator
+
+	if *geoDBFile != "" {
+		db, err := geodb.NewMMDB(geodb.MMDBConfig{
+			Path:   *geoDBFile,
+			Logger: logger,
+		})
+
+		if err != nil {
+			exit(logger, "error loading GeoDB", err)
+		}
+
+		geoDB = db
+	}
+
+	t := tracker.NewTracker(tracker.Config{
+		Store:           client,
+		Salt:            *salt,
+		FingerprintKey0: *key0,
+		FingerprintKey1: *key1,
+		GeoDB:           geoDB,
+		Logger:          logger,
+	})
+	imp, err := importer.NewImporter(importer.Config{
+		Tracker:  t,
+		ClientID: *clientID,
+		Format:   logFormat,
+		Hostname: *hostname,
+		From:     fromDate,
+		To:       toDate,
+		Progress: func(progress importer.Progress) {
+			logger.Info("importing", "file", progress.File, "lines", progress.Lines, "imported", progress.Imported, "skipped", progress.Skipped, "invalid", progress.Invalid)
+		},
+		Logger: logger,
+	})
+
+	if err != nil {
+		exit(logger, "error creating importer", err)
+	}
+
+	for _, file := range flag.Args() {
+		if _, err := imp.ImportFile(file); err != nil {
+			t.Stop()
+			exit(logger, "error importing file", err)
+		}
+	}
+
+	t.Stop()
+	logger.Info("import done")
+}
+
+func importCSV(logger *slog.Logger, client *db.Client, clientID uint64, from, to time.Time) {
+	imp, err := importer.NewCSVImporter(importer.CSVConfig{
+		Store:    client,
+		ClientID: clientID,
+		From:     from,
+		To:       to,
+	})
+
+	if err != nil {
+		exit(logger, "error creating CSV importer", err)
+	}
+
+	for _, file := range flag.Args() {
+		results, err := imp.ImportFile(file)
+
+		if err != nil {
+			exit(logger, "error importing file", err)
+		}
+
+		for _, result := range results {
+			logger.Info("imported", "file", file, "table", result.Table, "rows", result.Rows, "skipped", result.Skipped)
+		}
+	}
+
+	logger.Info("import done")
+}
+
+func parseFormat(format string) (*importer.Format, error) {
+	switch format {
+	case "combined":
+		format = importer.Combined
+	case "common":
+		format = importer.Common
+	}
+
+	return importer.ParseFormat(format)
+}
+
+func parseDate(date string) (time.Time, error) {
+	if date == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.DateOnly, date)
+}
+
+func exit(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "err", err)
+	os.Exit(1)
+}
@@ -15,6 +15,8 @@ type Analyzer struct {
 	UTM          UTM
 	Events       Events
 	Time         Time
+	Bots         Bots
+	SiteSearch   SiteSearch
 	Options      FilterOptions
 }
 
@@ -49,6 +51,14 @@ func NewAnalyzer(store db.Store) *Analyzer {
 		analyzer: analyzer,
 		store:    store,
 	}
+	analyzer.Bots = Bots{
+		analyzer: analyzer,
+		store:    store,
+	}
+	analyzer.SiteSearch = SiteSearch{
+		analyzer: analyzer,
+		store:    store,
+	}
 	analyzer.Options = FilterOptions{
 		analyzer: analyzer,
 		store:    store,
@@ -0,0 +1,106 @@
+package analyzer
+
+import (
+	"fmt"
+	"github.com/pirsch-analytics/pirsch/v6/pkg"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/model"
+	"strings"
+)
+
+// Bots aggregates statistics regarding page views and events that have been ignored.
+// Only the client ID, time range (From, To, Timezone), Period, Offset, and Limit of the Filter are used.
+type Bots struct {
+	analyzer *Analyzer
+	store    db.Store
+}
+
+// ByPeriod returns the bot visitors and hits grouped by day, week, month, or year.
+func (bots *Bots) ByPeriod(filter *Filter) ([]model.BotStats, error) {
+	filter = bots.analyzer.getFilter(filter)
+	q := queryBuilder{filter: filter}
+	var period string
+
+	switch filter.Period {
+	case pkg.PeriodWeek:
+		period = fmt.Sprintf(`toStartOfWeek(toDate(time, '%s'), 1) week`, filter.Timezone.String())
+	case pkg.PeriodMonth:
+		period = fmt.Sprintf(`toStartOfMonth(toDate(time, '%s')) month`, filter.Timezone.String())
+	case pkg.PeriodYear:
+		period = fmt.Sprintf(`toStartOfYear(toDate(time, '%s')) year`, filter.Timezone.String())
+	default:
+		period = fmt.Sprintf(`toDate(time, '%s') "day"`, filter.Timezone.String())
+	}
+
+	name := period[strings.LastIndex(period, " ")+1:]
+	var query strings.Builder
+	query.WriteString(fmt.Sprintf(`SELECT %s,
+		uniq(visitor_id) visitors,
+		count(*) hits
+		FROM "bot" `, period))
+	query.WriteString(q.whereTime())
+	query.WriteString(fmt.Sprintf(`GROUP BY %s
+		ORDER BY %s ASC %s`, name, name, q.withFill()))
+	stats, err := bots.store.SelectBotStats(filter.Period, query.String(), q.args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// UserAgents returns the User-Agents of bots ordered by the number of hits.
+func (bots *Bots) UserAgents(filter *Filter) ([]model.BotUserAgentStats, error) {
+	query, args := bots.selectByField(filter, "user_agent", "")
+	stats, err := bots.store.SelectBotUserAgentStats(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// Paths returns the paths of page views by bots ordered by the number of hits.
+func (bots *Bots) Paths(filter *Filter) ([]model.BotPathStats, error) {
+	query, args := bots.selectByField(filter, "path", `AND event_name = '' `)
+	stats, err := bots.store.SelectBotPathStats(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// Events returns the event names sent by bots ordered by the number of hits.
+func (bots *Bots) Events(filter *Filter) ([]model.BotEventStats, error) {
+	query, args := bots.selectByField(filter, "event_name", `AND event_name != '' `)
+	stats, err := bots.store.SelectBotEventStats(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (bots *Bots) selectByField(filter *Filter, field, where string) (string, []any) {
+	filter = bots.analyzer.getFilter(filter)
+	q := queryBuilder{
+		filter: filter,
+		offset: filter.Offset,
+		limit:  filter.Limit,
+	}
+	q.q.WriteString(fmt.Sprintf(`SELECT %s,
+		uniq(visitor_id) visitors,
+		count(*) hits
+		FROM "bot" `, field))
+	q.q.WriteString(q.whereTime())
+	q.q.WriteString(where)
+	q.q.WriteString(fmt.Sprintf(`GROUP BY %s
+		ORDER BY hits DESC, %s ASC `, field, field))
+	q.withLimit()
+	return q.q.String(), q.args
+}
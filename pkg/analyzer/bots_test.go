@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"github.com/pirsch-analytics/pirsch/v6/pkg"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/model"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBots(t *testing.T) {
+	db.CleanupDB(t, dbClient)
+	assert.NoError(t, dbClient.SaveBots([]model.Bot{
+		{VisitorID: 1, Time: util.PastDay(2), UserAgent: "Bot A", Path: "/"},
+		{VisitorID: 1, Time: util.PastDay(2).Add(time.Minute), UserAgent: "Bot A", Path: "/foo"},
+		{VisitorID: 2, Time: util.PastDay(2), UserAgent: "Bot B", Path: "/"},
+		{VisitorID: 1, Time: util.Today(), UserAgent: "Bot A", Path: "/"},
+		{VisitorID: 3, Time: util.Today(), UserAgent: "Bot C", Path: "/bar", Event: "event"},
+		{VisitorID: 3, Time: util.Today(), UserAgent: "Bot C", Path: "/bar", Event: "event"},
+		{ClientID: 1, VisitorID: 4, Time: util.Today(), UserAgent: "Bot D", Path: "/"},
+	}))
+	time.Sleep(time.Millisecond * 20)
+	analyzer := NewAnalyzer(dbClient)
+	byPeriod, err := analyzer.Bots.ByPeriod(&Filter{From: util.PastDay(2), To: util.Today()})
+	assert.NoError(t, err)
+	assert.Len(t, byPeriod, 3)
+	assert.Equal(t, util.PastDay(2), byPeriod[0].Day.Time)
+	assert.Equal(t, util.PastDay(1), byPeriod[1].Day.Time)
+	assert.Equal(t, util.Today(), byPeriod[2].Day.Time)
+	assert.Equal(t, 2, byPeriod[0].Visitors)
+	assert.Equal(t, 0, byPeriod[1].Visitors)
+	assert.Equal(t, 2, byPeriod[2].Visitors)
+	assert.Equal(t, 3, byPeriod[0].Hits)
+	assert.Equal(t, 0, byPeriod[1].Hits)
+	assert.Equal(t, 3, byPeriod[2].Hits)
+	byPeriod, err = analyzer.Bots.ByPeriod(&Filter{From: util.PastDay(2), To: util.Today(), Period: pkg.PeriodYear})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, byPeriod)
+	assert.True(t, byPeriod[0].Year.Valid)
+	userAgents, err := analyzer.Bots.UserAgents(nil)
+	assert.NoError(t, err)
+	assert.Len(t, userAgents, 3)
+	assert.Equal(t, "Bot A", userAgents[0].UserAgent)
+	assert.Equal(t, "Bot C", userAgents[1].UserAgent)
+	assert.Equal(t, "Bot B", userAgents[2].UserAgent)
+	assert.Equal(t, 1, userAgents[0].Visitors)
+	assert.Equal(t, 3, userAgents[0].Hits)
+	userAgents, err = analyzer.Bots.UserAgents(&Filter{From: util.Today(), To: util.Today(), Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, userAgents, 1)
+	assert.Equal(t, "Bot C", userAgents[0].UserAgent)
+	paths, err := analyzer.Bots.Paths(nil)
+	assert.NoError(t, err)
+	assert.Len(t, paths, 2)
+	assert.Equal(t, "/", paths[0].Path)
+	assert.Equal(t, "/foo", paths[1].Path)
+	assert.Equal(t, 2, paths[0].Visitors)
+	assert.Equal(t, 3, paths[0].Hits)
+	events, err := analyzer.Bots.Events(nil)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "event", events[0].Name)
+	assert.Equal(t, 1, events[0].Visitors)
+	assert.Equal(t, 2, events[0].Hits)
+	paths, err = analyzer.Bots.Paths(&Filter{ClientID: 1})
+	assert.NoError(t, err)
+	assert.Len(t, paths, 1)
+	assert.Equal(t, 1, paths[0].Hits)
+}
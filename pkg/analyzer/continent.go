@@ -0,0 +1,158 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// ContinentAfrica is the continent code for Africa.
+	ContinentAfrica = "AF"
+
+	// ContinentAntarctica is the continent code for Antarctica.
+	ContinentAntarctica = "AN"
+
+	// ContinentAsia is the continent code for Asia.
+	ContinentAsia = "AS"
+
+	// ContinentEurope is the continent code for Europe.
+	ContinentEurope = "EU"
+
+	// ContinentNorthAmerica is the continent code for North America, including Central America and the Caribbean.
+	ContinentNorthAmerica = "NA"
+
+	// ContinentOceania is the continent code for Oceania.
+	ContinentOceania = "OC"
+
+	// ContinentSouthAmerica is the continent code for South America.
+	ContinentSouthAmerica = "SA"
+
+	// EconomicRegionEU is the economic region for member states of the European Union.
+	EconomicRegionEU = "EU"
+
+	// EconomicRegionEEA is the economic region for members of the European Economic Area that are not part of the European Union.
+	// Filter for both EconomicRegionEU and EconomicRegionEEA to select the whole EEA.
+	EconomicRegionEEA = "EEA"
+
+	// EconomicRegionOther is the economic region for all other countries.
+	EconomicRegionOther = "Other"
+)
+
+// subregions maps the subregions (based on the UN M49 standard) to their continent and lowercase ISO country codes.
+var subregions = []struct {
+	continent string
+	subregion string
+	countries []string
+}{
+	{ContinentAfrica, "Northern Africa", []string{"dz", "eg", "eh", "ly", "ma", "sd", "tn"}},
+	{ContinentAfrica, "Eastern Africa", []string{"bi", "dj", "er", "et", "io", "ke", "km", "mg", "mu", "mw", "mz", "re", "rw", "sc", "so", "ss", "tz", "ug", "yt", "zm", "zw"}},
+	{ContinentAfrica, "Middle Africa", []string{"ao", "cd", "cf", "cg", "cm", "ga", "gq", "st", "td"}},
+	{ContinentAfrica, "Southern Africa", []string{"bw", "ls", "na", "sz", "za"}},
+	{ContinentAfrica, "Western Africa", []string{"bf", "bj", "ci", "cv", "gh", "gm", "gn", "gw", "lr", "ml", "mr", "ne", "ng", "sh", "sl", "sn", "tg"}},
+	{ContinentAntarctica, "Antarctica", []string{"aq", "bv", "gs", "hm", "tf"}},
+	{ContinentAsia, "Central Asia", []string{"kg", "kz", "tj", "tm", "uz"}},
+	{ContinentAsia, "Eastern Asia", []string{"cn", "hk", "jp", "kp", "kr", "mn", "mo", "tw"}},
+	{ContinentAsia, "South-Eastern Asia", []string{"bn", "id", "kh", "la", "mm", "my", "ph", "sg", "th", "tl", "vn"}},
+	{ContinentAsia, "Southern Asia", []string{"af", "bd", "bt", "in", "ir", "lk", "mv", "np", "pk"}},
+	{ContinentAsia, "Western Asia", []string{"ae", "am", "az", "bh", "ge", "il", "iq", "jo", "kw", "lb", "om", "ps", "qa", "sa", "sy", "tr", "ye"}},
+	{ContinentEurope, "Eastern Europe", []string{"bg", "by", "cz", "hu", "md", "pl", "ro", "ru", "sk", "ua"}},
+	{ContinentEurope, "Northern Europe", []string{"ax", "dk", "ee", "fi", "fo", "gb", "gg", "ie", "im", "is", "je", "lt", "lv", "no", "se", "sj"}},
+	{ContinentEurope, "Southern Europe", []string{"ad", "al", "ba", "cy", "es", "gi", "gr", "hr", "it", "me", "mk", "mt", "pt", "rs", "si", "sm", "va", "xk"}},
+	{ContinentEurope, "Western Europe", []string{"at", "be", "ch", "de", "fr", "li", "lu", "mc", "nl"}},
+	{ContinentNorthAmerica, "Caribbean", []string{"ag", "ai", "aw", "bb", "bl", "bq", "bs", "cu", "cw", "dm", "do", "gd", "gp", "ht", "jm", "kn", "ky", "lc", "mf", "mq", "ms", "pr", "sx", "tc", "tt", "vc", "vg", "vi"}},
+	{ContinentNorthAmerica, "Central America", []string{"bz", "cr", "gt", "hn", "mx", "ni", "pa", "sv"}},
+	{ContinentNorthAmerica, "Northern America", []string{"bm", "ca", "gl", "pm", "us"}},
+	{ContinentOceania, "Australia and New Zealand", []string{"au", "cc", "cx", "nf", "nz"}},
+	{ContinentOceania, "Melanesia", []string{"fj", "nc", "pg", "sb", "vu"}},
+	{ContinentOceania, "Micronesia", []string{"fm", "gu", "ki", "mh", "mp", "nr", "pw", "um"}},
+	{ContinentOceania, "Polynesia", []string{"as", "ck", "nu", "pf", "pn", "tk", "to", "tv", "wf", "ws"}},
+	{ContinentSouthAmerica, "South America", []string{"ar", "bo", "br", "cl", "co", "ec", "fk", "gf", "gy", "pe", "py", "sr", "uy", "ve"}},
+}
+
+var (
+	euCountries  = []string{"at", "be", "bg", "cy", "cz", "de", "dk", "ee", "es", "fi", "fr", "gr", "hr", "hu", "ie", "it", "lt", "lu", "lv", "mt", "nl", "pl", "pt", "ro", "se", "si", "sk"}
+	eeaCountries = []string{"is", "li", "no"}
+
+	countryContinent, countrySubregion, countryEconomicRegion = countryMappings()
+
+	continentQuery      = countryTransform(countryContinent, "")
+	subregionQuery      = countryTransform(countrySubregion, "")
+	economicRegionQuery = countryTransform(countryEconomicRegion, EconomicRegionOther)
+)
+
+// countryMappings returns the continent, subregion, and economic region by country code.
+func countryMappings() (map[string]string, map[string]string, map[string]string) {
+	continents, subregionsByCountry, economicRegions := make(map[string]string), make(map[string]string), make(map[string]string)
+
+	for _, s := range subregions {
+		for _, country := range s.countries {
+			continents[country] = s.continent
+			subregionsByCountry[country] = s.subregion
+			economicRegions[country] = EconomicRegionOther
+		}
+	}
+
+	for _, country := range euCountries {
+		economicRegions[country] = EconomicRegionEU
+	}
+
+	for _, country := range eeaCountries {
+		economicRegions[country] = EconomicRegionEEA
+	}
+
+	return continents, subregionsByCountry, economicRegions
+}
+
+// Continent returns the continent code (like ContinentEurope) for given ISO country code.
+func Continent(countryCode string) string {
+	return countryContinent[strings.ToLower(countryCode)]
+}
+
+// Subregion returns the subregion (like "Western Europe") for given ISO country code.
+func Subregion(countryCode string) string {
+	return countrySubregion[strings.ToLower(countryCode)]
+}
+
+// EconomicRegion returns the economic region (like EconomicRegionEU) for given ISO country code.
+// Unknown countries (except for an empty country code) are returned as EconomicRegionOther.
+func EconomicRegion(countryCode string) string {
+	if countryCode == "" {
+		return ""
+	}
+
+	if region, found := countryEconomicRegion[strings.ToLower(countryCode)]; found {
+		return region
+	}
+
+	return EconomicRegionOther
+}
+
+// countryTransform returns a query mapping the country_code column to the values of given map.
+// Country codes not found in the map are mapped to the default value, and an empty country code to an empty string.
+func countryTransform(mapping map[string]string, defaultValue string) string {
+	countries := make([]string, 0, len(mapping))
+
+	for country := range mapping {
+		countries = append(countries, country)
+	}
+
+	sort.Strings(countries)
+	var from, to strings.Builder
+	from.WriteString("['',")
+	to.WriteString("['',")
+
+	for i, country := range countries {
+		from.WriteString(fmt.Sprintf("'%s'", country))
+		to.WriteString(fmt.Sprintf("'%s'", mapping[country]))
+
+		if i < len(countries)-1 {
+			from.WriteString(",")
+			to.WriteString(",")
+		}
+	}
+
+	from.WriteString("]")
+	to.WriteString("]")
+	return fmt.Sprintf("transform(country_code, %s, %s, '%s')", from.String(), to.String(), defaultValue)
+}
@@ -0,0 +1,54 @@
+package analyzer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestContinent(t *testing.T) {
+	assert.Equal(t, ContinentEurope, Continent("de"))
+	assert.Equal(t, ContinentEurope, Continent("DE"))
+	assert.Equal(t, ContinentNorthAmerica, Continent("us"))
+	assert.Equal(t, ContinentNorthAmerica, Continent("mx"))
+	assert.Equal(t, ContinentSouthAmerica, Continent("br"))
+	assert.Equal(t, ContinentAsia, Continent("jp"))
+	assert.Equal(t, ContinentAfrica, Continent("ng"))
+	assert.Equal(t, ContinentOceania, Continent("nz"))
+	assert.Equal(t, ContinentAntarctica, Continent("aq"))
+	assert.Empty(t, Continent(""))
+	assert.Empty(t, Continent("zz"))
+	assert.Equal(t, "Western Europe", Subregion("de"))
+	assert.Equal(t, "Northern America", Subregion("us"))
+	assert.Empty(t, Subregion("zz"))
+	assert.Equal(t, EconomicRegionEU, EconomicRegion("de"))
+	assert.Equal(t, EconomicRegionEEA, EconomicRegion("no"))
+	assert.Equal(t, EconomicRegionOther, EconomicRegion("ch"))
+	assert.Equal(t, EconomicRegionOther, EconomicRegion("gb"))
+	assert.Equal(t, EconomicRegionOther, EconomicRegion("zz"))
+	assert.Empty(t, EconomicRegion(""))
+}
+
+func TestContinentMapping(t *testing.T) {
+	found := make(map[string]bool)
+
+	for _, s := range subregions {
+		for _, country := range s.countries {
+			assert.False(t, found[country], country)
+			assert.Len(t, country, 2)
+			found[country] = true
+		}
+	}
+
+	assert.Len(t, euCountries, 27)
+
+	for _, country := range append(euCountries, eeaCountries...) {
+		assert.Equal(t, ContinentEurope, Continent(country), country)
+	}
+}
+
+func TestCountryTransform(t *testing.T) {
+	assert.Equal(t, "transform(country_code, ['','at','de'], ['','EU','EU'], 'Other')", countryTransform(map[string]string{"de": "EU", "at": "EU"}, "Other"))
+	assert.True(t, strings.HasPrefix(continentQuery, "transform(country_code, ['','ad',"))
+	assert.Equal(t, continentQuery, FieldContinent.querySessions)
+}
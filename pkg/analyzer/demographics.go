@@ -19,12 +19,49 @@ func (demographics *Demographics) Languages(filter *Filter) ([]model.LanguageSta
 
 // Countries returns the visitor count grouped by country.
 func (demographics *Demographics) Countries(filter *Filter) ([]model.CountryStats, error) {
-	q, args := demographics.analyzer.selectByAttribute(filter, FieldCountry)
-	return demographics.store.SelectCountryStats(q, args...)
+	filter = demographics.analyzer.getFilter(filter)
+	queryFilter := filter
+
+	if _, _, ok := filter.importedRange(); ok {
+		queryFilter = filter.withoutLimit()
+	}
+
+	q, args := demographics.analyzer.selectByAttribute(queryFilter, FieldCountry)
+	stats, err := demographics.store.SelectCountryStats(q, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return demographics.mergeImportedCountries(filter, stats)
+}
+
+// Continents returns the visitor count grouped by continent.
+func (demographics *Demographics) Continents(filter *Filter) ([]model.ContinentStats, error) {
+	q, args := demographics.analyzer.selectByAttribute(filter, FieldContinent)
+	return demographics.store.SelectContinentStats(q, args...)
+}
+
+// Subregions returns the visitor count grouped by subregion, like "Western Europe".
+func (demographics *Demographics) Subregions(filter *Filter) ([]model.SubregionStats, error) {
+	q, args := demographics.analyzer.selectByAttribute(filter, FieldSubregion)
+	return demographics.store.SelectSubregionStats(q, args...)
+}
+
+// EconomicRegions returns the visitor count grouped by economic region, like the EU.
+func (demographics *Demographics) EconomicRegions(filter *Filter) ([]model.EconomicRegionStats, error) {
+	q, args := demographics.analyzer.selectByAttribute(filter, FieldEconomicRegion)
+	return demographics.store.SelectEconomicRegionStats(q, args...)
+}
+
+// Regions returns the visitor count grouped by region.
+func (demographics *Demographics) Regions(filter *Filter) ([]model.RegionStats, error) {
+	q, args := demographics.analyzer.selectByAttribute(filter, FieldRegion, FieldCountryRegion)
+	return demographics.store.SelectRegionStats(q, args...)
 }
 
-// Cities returns the visitor count grouped by city.
+// Cities returns the visitor count grouped by country, region, and city.
 func (demographics *Demographics) Cities(filter *Filter) ([]model.CityStats, error) {
-	q, args := demographics.analyzer.selectByAttribute(filter, FieldCity, FieldCountryCity)
+	q, args := demographics.analyzer.selectByAttribute(filter, FieldCity, FieldCountryCity, FieldRegionCity)
 	return demographics.store.SelectCityStats(q, args...)
 }
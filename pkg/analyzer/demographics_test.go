@@ -107,6 +107,125 @@ func TestAnalyzer_Countries(t *testing.T) {
 	assert.Len(t, visitors, 2)
 }
 
+func TestAnalyzer_Continents(t *testing.T) {
+	db.CleanupDB(t, dbClient)
+	saveSessions(t, [][]model.Session{
+		{
+			{Sign: 1, VisitorID: 1, Time: time.Now(), Start: time.Now(), CountryCode: "jp"},
+		},
+		{
+			{Sign: -1, VisitorID: 1, Time: time.Now(), Start: time.Now(), CountryCode: "jp"},
+			{Sign: 1, VisitorID: 1, Time: time.Now(), Start: time.Now(), CountryCode: "de"},
+			{Sign: 1, VisitorID: 2, Time: time.Now(), Start: time.Now(), CountryCode: "fr"},
+			{Sign: 1, VisitorID: 3, Time: time.Now(), Start: time.Now(), CountryCode: "no"},
+			{Sign: 1, VisitorID: 4, Time: time.Now(), Start: time.Now(), CountryCode: "gb"},
+			{Sign: 1, VisitorID: 5, Time: time.Now(), Start: time.Now(), CountryCode: "us"},
+			{Sign: 1, VisitorID: 6, Time: time.Now(), Start: time.Now(), CountryCode: ""},
+		},
+	})
+	time.Sleep(time.Millisecond * 20)
+	analyzer := NewAnalyzer(dbClient)
+	continents, err := analyzer.Demographics.Continents(nil)
+	assert.NoError(t, err)
+	assert.Len(t, continents, 3)
+	assert.Equal(t, ContinentEurope, continents[0].Continent)
+	assert.Equal(t, 4, continents[0].Visitors)
+	assert.InDelta(t, 0.66, continents[0].RelativeVisitors, 0.01)
+	assert.Empty(t, continents[1].Continent)
+	assert.Equal(t, 1, continents[1].Visitors)
+	assert.Equal(t, ContinentNorthAmerica, continents[2].Continent)
+	assert.Equal(t, 1, continents[2].Visitors)
+	subregions, err := analyzer.Demographics.Subregions(&Filter{Continent: []string{ContinentEurope}})
+	assert.NoError(t, err)
+	assert.Len(t, subregions, 2)
+	assert.Equal(t, "Northern Europe", subregions[0].Subregion)
+	assert.Equal(t, 2, subregions[0].Visitors)
+	assert.Equal(t, "Western Europe", subregions[1].Subregion)
+	assert.Equal(t, 2, subregions[1].Visitors)
+	economicRegions, err := analyzer.Demographics.EconomicRegions(nil)
+	assert.NoError(t, err)
+	assert.Len(t, economicRegions, 4)
+	assert.Equal(t, EconomicRegionEU, economicRegions[0].EconomicRegion)
+	assert.Equal(t, 2, economicRegions[0].Visitors)
+	assert.Equal(t, EconomicRegionOther, economicRegions[1].EconomicRegion)
+	assert.Equal(t, 2, economicRegions[1].Visitors)
+	assert.Empty(t, economicRegions[2].EconomicRegion)
+	assert.Equal(t, EconomicRegionEEA, economicRegions[3].EconomicRegion)
+	countries, err := analyzer.Demographics.Countries(&Filter{EconomicRegion: []string{EconomicRegionEU, EconomicRegionEEA}})
+	assert.NoError(t, err)
+	assert.Len(t, countries, 3)
+	_, err = analyzer.Demographics.Continents(getMaxFilter(""))
+	assert.NoError(t, err)
+	_, err = analyzer.Demographics.Continents(getMaxFilter("event"))
+	assert.NoError(t, err)
+	_, err = analyzer.Demographics.Continents(&Filter{Offset: 1, Limit: 10, Sort: []Sort{
+		{
+			Field:     FieldContinent,
+			Direction: pkg.DirectionASC,
+		},
+	}, Search: []Search{
+		{
+			Field: FieldContinent,
+			Input: "EU",
+		},
+	}})
+	assert.NoError(t, err)
+}
+
+func TestAnalyzer_Regions(t *testing.T) {
+	db.CleanupDB(t, dbClient)
+	saveSessions(t, [][]model.Session{
+		{
+			{Sign: 1, VisitorID: 1, Time: time.Now(), Start: time.Now(), CountryCode: "no", Region: "Oslo County", City: "Oslo"},
+		},
+		{
+			{Sign: -1, VisitorID: 1, Time: time.Now(), Start: time.Now(), CountryCode: "no", Region: "Oslo County", City: "Oslo"},
+			{Sign: 1, VisitorID: 1, Time: time.Now(), Start: time.Now(), CountryCode: "gb", Region: "England", City: "London"},
+			{Sign: 1, VisitorID: 2, Time: time.Now(), Start: time.Now(), CountryCode: "us", Region: "Oregon", City: "Portland"},
+			{Sign: 1, VisitorID: 3, Time: time.Now(), Start: time.Now(), CountryCode: "us", Region: "", City: ""},
+			{Sign: 1, VisitorID: 4, Time: time.Now(), Start: time.Now(), CountryCode: "gb", Region: "England", City: "Manchester"},
+			{Sign: 1, VisitorID: 5, Time: time.Now(), Start: time.Now(), CountryCode: "gb", Region: "England", City: "London"},
+		},
+	})
+	time.Sleep(time.Millisecond * 20)
+	analyzer := NewAnalyzer(dbClient)
+	visitors, err := analyzer.Demographics.Regions(nil)
+	assert.NoError(t, err)
+	assert.Len(t, visitors, 3)
+	assert.Equal(t, "gb", visitors[0].CountryCode)
+	assert.Empty(t, visitors[1].CountryCode)
+	assert.Equal(t, "us", visitors[2].CountryCode)
+	assert.Equal(t, "England", visitors[0].Region)
+	assert.Empty(t, visitors[1].Region)
+	assert.Equal(t, "Oregon", visitors[2].Region)
+	assert.Equal(t, 3, visitors[0].Visitors)
+	assert.Equal(t, 1, visitors[1].Visitors)
+	assert.Equal(t, 1, visitors[2].Visitors)
+	assert.InDelta(t, 0.6, visitors[0].RelativeVisitors, 0.01)
+	assert.InDelta(t, 0.2, visitors[1].RelativeVisitors, 0.01)
+	assert.InDelta(t, 0.2, visitors[2].RelativeVisitors, 0.01)
+	visitors, err = analyzer.Demographics.Regions(&Filter{Region: []string{"Oregon"}})
+	assert.NoError(t, err)
+	assert.Len(t, visitors, 1)
+	assert.Equal(t, "Oregon", visitors[0].Region)
+	_, err = analyzer.Demographics.Regions(getMaxFilter(""))
+	assert.NoError(t, err)
+	_, err = analyzer.Demographics.Regions(getMaxFilter("event"))
+	assert.NoError(t, err)
+	_, err = analyzer.Demographics.Regions(&Filter{Offset: 1, Limit: 10, Sort: []Sort{
+		{
+			Field:     FieldRegion,
+			Direction: pkg.DirectionASC,
+		},
+	}, Search: []Search{
+		{
+			Field: FieldRegion,
+			Input: "England",
+		},
+	}})
+	assert.NoError(t, err)
+}
+
 func TestAnalyzer_Cities(t *testing.T) {
 	db.CleanupDB(t, dbClient)
 	saveSessions(t, [][]model.Session{
@@ -161,3 +280,27 @@ func TestAnalyzer_Cities(t *testing.T) {
 	}})
 	assert.NoError(t, err)
 }
+
+func TestAnalyzer_CitiesRegion(t *testing.T) {
+	db.CleanupDB(t, dbClient)
+	saveSessions(t, [][]model.Session{
+		{
+			{Sign: 1, VisitorID: 1, Time: time.Now(), Start: time.Now(), CountryCode: "us", Region: "Oregon", City: "Portland"},
+			{Sign: 1, VisitorID: 2, Time: time.Now(), Start: time.Now(), CountryCode: "us", Region: "Oregon", City: "Portland"},
+			{Sign: 1, VisitorID: 3, Time: time.Now(), Start: time.Now(), CountryCode: "us", Region: "Maine", City: "Portland"},
+		},
+	})
+	time.Sleep(time.Millisecond * 20)
+	analyzer := NewAnalyzer(dbClient)
+	visitors, err := analyzer.Demographics.Cities(nil)
+	assert.NoError(t, err)
+	assert.Len(t, visitors, 2)
+	assert.Equal(t, "us", visitors[0].CountryCode)
+	assert.Equal(t, "us", visitors[1].CountryCode)
+	assert.Equal(t, "Oregon", visitors[0].Region)
+	assert.Equal(t, "Maine", visitors[1].Region)
+	assert.Equal(t, "Portland", visitors[0].City)
+	assert.Equal(t, "Portland", visitors[1].City)
+	assert.Equal(t, 2, visitors[0].Visitors)
+	assert.Equal(t, 1, visitors[1].Visitors)
+}
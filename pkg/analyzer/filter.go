@@ -30,6 +30,10 @@ type Filter struct {
 	// This can either be PeriodDay (default), PeriodWeek, or PeriodYear.
 	Period pkg.Period
 
+	// Hostname filters for the hostname.
+	// Sessions are filtered by the hostname of the entry page, page views and events by their own hostname.
+	Hostname []string
+
 	// Path filters for the path.
 	// Note that if this and PathPattern are both set, Path will be preferred.
 	Path []string
@@ -58,6 +62,15 @@ type Filter struct {
 	// Country filters for the ISO country code.
 	Country []string
 
+	// Continent filters for the continent code (like "EU" for Europe) based on the country.
+	Continent []string
+
+	// EconomicRegion filters for the economic region (like "EU" or "EEA") based on the country.
+	EconomicRegion []string
+
+	// Region filters for the region (subdivision) name, like "England" or "Oregon".
+	Region []string
+
 	// City filters for the city name.
 	City []string
 
@@ -67,6 +80,12 @@ type Filter struct {
 	// ReferrerName filters for the referrer name.
 	ReferrerName []string
 
+	// Channel filters for the marketing channel, like "Organic Search" or "Social" (see referrer.Channel).
+	Channel []string
+
+	// ConnectionType filters for the connection type, like pkg.ConnectionTypeHosting or pkg.ConnectionTypeResidential.
+	ConnectionType []string
+
 	// OS filters for the operating system.
 	OS []string
 
@@ -110,6 +129,13 @@ type Filter struct {
 	// EventMeta filters for event metadata.
 	EventMeta map[string]string
 
+	// QueryParamKey filters for page views and events having a stored query parameter (see tracker.Config.QueryParams).
+	// The first key is used by Pages.QueryParam to group the results.
+	QueryParamKey []string
+
+	// QueryParam filters page views and events by the value of a stored query parameter, like "variant" = "b".
+	QueryParam map[string]string
+
 	// Search searches the results for given fields and inputs.
 	Search []Search
 
@@ -142,6 +168,13 @@ type Filter struct {
 	// IncludeCR indicates that Analyzer.Total and Analyzer.ByPeriod should contain the conversion rate.
 	IncludeCR bool
 
+	// ImportedUntil is the date (exclusive) up to which statistics have been imported from other analytics tools (the cut-over).
+	// If set, Visitors.ByPeriod, Visitors.Total, Visitors.Referrer, Pages.ByPath, and Demographics.Countries merge the imported statistics
+	// for the selected period before this date. As imported statistics are aggregated by day,
+	// they are only merged if no other filter (like Path or Country) is set and IncludeTime is false.
+	// The imported visitors are unique per day and summed up for longer periods.
+	ImportedUntil time.Time
+
 	// MaxTimeOnPageSeconds is an optional maximum for the time spent on page.
 	// Visitors who are idle artificially increase the average time spent on a page, this option can be used to limit the effect.
 	// Set to 0 to disable this option (default).
@@ -189,6 +222,10 @@ func (filter *Filter) validate() {
 		}
 	}
 
+	if !filter.ImportedUntil.IsZero() {
+		filter.ImportedUntil = filter.toDate(filter.ImportedUntil)
+	}
+
 	if !filter.To.IsZero() && filter.From.After(filter.To) {
 		filter.From, filter.To = filter.To, filter.From
 	}
@@ -222,15 +259,21 @@ func (filter *Filter) validate() {
 		filter.CustomMetricType = ""
 	}
 
+	filter.Hostname = filter.removeDuplicates(filter.Hostname)
 	filter.Path = filter.removeDuplicates(filter.Path)
 	filter.EntryPath = filter.removeDuplicates(filter.EntryPath)
 	filter.ExitPath = filter.removeDuplicates(filter.ExitPath)
 	filter.PathPattern = filter.removeDuplicates(filter.PathPattern)
 	filter.Language = filter.removeDuplicates(filter.Language)
 	filter.Country = filter.removeDuplicates(filter.Country)
+	filter.Continent = filter.removeDuplicates(filter.Continent)
+	filter.EconomicRegion = filter.removeDuplicates(filter.EconomicRegion)
+	filter.Region = filter.removeDuplicates(filter.Region)
 	filter.City = filter.removeDuplicates(filter.City)
 	filter.Referrer = filter.removeDuplicates(filter.Referrer)
 	filter.ReferrerName = filter.removeDuplicates(filter.ReferrerName)
+	filter.Channel = filter.removeDuplicates(filter.Channel)
+	filter.ConnectionType = filter.removeDuplicates(filter.ConnectionType)
 	filter.OS = filter.removeDuplicates(filter.OS)
 	filter.OSVersion = filter.removeDuplicates(filter.OSVersion)
 	filter.Browser = filter.removeDuplicates(filter.Browser)
@@ -243,6 +286,7 @@ func (filter *Filter) validate() {
 	filter.UTMTerm = filter.removeDuplicates(filter.UTMTerm)
 	filter.EventName = filter.removeDuplicates(filter.EventName)
 	filter.EventMetaKey = filter.removeDuplicates(filter.EventMetaKey)
+	filter.QueryParamKey = filter.removeDuplicates(filter.QueryParamKey)
 }
 
 func (filter *Filter) removeDuplicates(in []string) []string {
@@ -329,7 +373,9 @@ func (filter *Filter) table(fields []Field) table {
 		if !eventFilter &&
 			(len(filter.Path) != 0 ||
 				len(filter.PathPattern) != 0 ||
+				filter.hasQueryParamFilter() ||
 				filter.fieldsContain(fields, FieldPath) ||
+				filter.fieldsContain(fields, FieldQueryParamValue) ||
 				filter.searchContains(FieldPath)) {
 			return pageViews
 		}
@@ -396,7 +442,7 @@ func (filter *Filter) joinSessions(fields []Field) *queryBuilder {
 }
 
 func (filter *Filter) joinPageViews(fields []Field) *queryBuilder {
-	if len(filter.Path) != 0 || len(filter.PathPattern) != 0 || filter.searchContains(FieldPath) {
+	if len(filter.Path) != 0 || len(filter.PathPattern) != 0 || filter.hasQueryParamFilter() || filter.searchContains(FieldPath) {
 		pageViewFields := []Field{FieldVisitorID, FieldSessionID}
 
 		if len(filter.PathPattern) != 0 {
@@ -540,6 +586,10 @@ func (filter *Filter) valuesContainPrefix(haystack []string, prefix string) bool
 	return false
 }
 
+func (filter *Filter) hasQueryParamFilter() bool {
+	return len(filter.QueryParamKey) != 0 || len(filter.QueryParam) != 0
+}
+
 func (filter *Filter) searchContains(needle Field) bool {
 	for i := range filter.Search {
 		if filter.Search[i].Field == needle {
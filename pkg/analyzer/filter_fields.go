@@ -189,6 +189,22 @@ var (
 		Name:           "referrer_name",
 	}
 
+	// FieldChannel is a query result column.
+	FieldChannel = Field{
+		querySessions:  "channel",
+		queryPageViews: "channel",
+		queryDirection: "ASC",
+		Name:           "channel",
+	}
+
+	// FieldConnectionType is a query result column.
+	FieldConnectionType = Field{
+		querySessions:  "connection_type",
+		queryPageViews: "connection_type",
+		queryDirection: "ASC",
+		Name:           "connection_type",
+	}
+
 	// FieldReferrerIcon is a query result column.
 	FieldReferrerIcon = Field{
 		querySessions:  "any(referrer_icon)",
@@ -214,6 +230,51 @@ var (
 		Name:           "country_code",
 	}
 
+	// FieldRegionCity is a query result column.
+	// This field can only be used in combination with the FieldCity.
+	FieldRegionCity = Field{
+		querySessions:  "if(city = '', '', region)",
+		queryPageViews: "if(city = '', '', region)",
+		queryDirection: "ASC",
+		Name:           "region",
+	}
+
+	// FieldContinent is a query result column.
+	// The continent is mapped from the country code, see Continent.
+	FieldContinent = Field{
+		querySessions:  continentQuery,
+		queryPageViews: continentQuery,
+		queryDirection: "ASC",
+		Name:           "continent",
+	}
+
+	// FieldSubregion is a query result column.
+	// The subregion is mapped from the country code, see Subregion.
+	FieldSubregion = Field{
+		querySessions:  subregionQuery,
+		queryPageViews: subregionQuery,
+		queryDirection: "ASC",
+		Name:           "subregion",
+	}
+
+	// FieldEconomicRegion is a query result column.
+	// The economic region is mapped from the country code, see EconomicRegion.
+	FieldEconomicRegion = Field{
+		querySessions:  economicRegionQuery,
+		queryPageViews: economicRegionQuery,
+		queryDirection: "ASC",
+		Name:           "economic_region",
+	}
+
+	// FieldCountryRegion is a query result column.
+	// This field can only be used in combination with the FieldRegion.
+	FieldCountryRegion = Field{
+		querySessions:  "if(region = '', '', country_code)",
+		queryPageViews: "if(region = '', '', country_code)",
+		queryDirection: "ASC",
+		Name:           "country_code",
+	}
+
 	// FieldCountry is a query result column.
 	FieldCountry = Field{
 		querySessions:  "country_code",
@@ -222,6 +283,14 @@ var (
 		Name:           "country_code",
 	}
 
+	// FieldRegion is a query result column.
+	FieldRegion = Field{
+		querySessions:  "region",
+		queryPageViews: "region",
+		queryDirection: "ASC",
+		Name:           "region",
+	}
+
 	// FieldCity is a query result column.
 	FieldCity = Field{
 		querySessions:  "city",
@@ -230,6 +299,14 @@ var (
 		Name:           "city",
 	}
 
+	// FieldHostname is a query result column.
+	FieldHostname = Field{
+		querySessions:  "hostname",
+		queryPageViews: "hostname",
+		queryDirection: "ASC",
+		Name:           "hostname",
+	}
+
 	// FieldBrowser is a query result column.
 	FieldBrowser = Field{
 		querySessions:  "browser",
@@ -406,6 +483,14 @@ var (
 		Name:           "event_meta_values",
 	}
 
+	// FieldQueryParamValue is a query result column.
+	FieldQueryParamValue = Field{
+		querySessions:  "query_param_values[indexOf(query_param_keys, ?)]",
+		queryPageViews: "query_param_values[indexOf(query_param_keys, ?)]",
+		queryDirection: "ASC",
+		Name:           "query_param_value",
+	}
+
 	// FieldEventTimeSpent is a query result column.
 	FieldEventTimeSpent = Field{
 		querySessions:  "toUInt64(ifNotFinite(avg(duration_seconds), 0))",
@@ -13,6 +13,11 @@ type FilterOptions struct {
 	store    db.Store
 }
 
+// Hostnames returns all hostnames.
+func (options *FilterOptions) Hostnames(filter *Filter) ([]string, error) {
+	return options.selectFilterOptions(filter, "hostname", "page_view")
+}
+
 // Pages returns all paths.
 // This can also be used for the entry and exit pages.
 func (options *FilterOptions) Pages(filter *Filter) ([]string, error) {
@@ -64,6 +69,11 @@ func (options *FilterOptions) Countries(filter *Filter) ([]string, error) {
 	return options.selectFilterOptions(filter, "country_code", "session")
 }
 
+// Regions returns all regions.
+func (options *FilterOptions) Regions(filter *Filter) ([]string, error) {
+	return options.selectFilterOptions(filter, "region", "session")
+}
+
 // Cities returns all cities.
 func (options *FilterOptions) Cities(filter *Filter) ([]string, error) {
 	return options.selectFilterOptions(filter, "city", "session")
@@ -100,6 +110,37 @@ func (options *FilterOptions) EventMetadataValues(filter *Filter) ([]string, err
 	return options.store.SelectOptions(q, args...)
 }
 
+// QueryParamKeys returns all stored query parameter keys.
+func (options *FilterOptions) QueryParamKeys(filter *Filter) ([]string, error) {
+	filter = options.analyzer.getFilter(filter)
+	timeQuery, args := filter.buildTimeQuery()
+	q := fmt.Sprintf(`SELECT DISTINCT arrayJoin(query_param_keys) AS "keys"
+		FROM page_view
+		%s
+		AND length(query_param_keys) > 0
+		ORDER BY "keys" ASC`, timeQuery)
+	return options.store.SelectOptions(q, args...)
+}
+
+// QueryParamValues returns all values for the query parameter set by the first Filter.QueryParamKey.
+func (options *FilterOptions) QueryParamValues(filter *Filter) ([]string, error) {
+	if filter == nil || len(filter.QueryParamKey) == 0 {
+		return []string{}, nil
+	}
+
+	filter = options.analyzer.getFilter(filter)
+	timeQuery, args := filter.buildTimeQuery()
+	key := filter.QueryParamKey[0]
+	args = append([]any{key}, args...)
+	args = append(args, key)
+	q := fmt.Sprintf(`SELECT DISTINCT query_param_values[indexOf(query_param_keys, ?)] AS "values"
+		FROM page_view
+		%s
+		AND has(query_param_keys, ?)
+		ORDER BY "values" ASC`, timeQuery)
+	return options.store.SelectOptions(q, args...)
+}
+
 func (options *FilterOptions) selectFilterOptions(filter *Filter, field, table string) ([]string, error) {
 	filter = options.analyzer.getFilter(filter)
 	timeQuery, args := filter.buildTimeQuery()
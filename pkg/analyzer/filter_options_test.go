@@ -32,6 +32,48 @@ func TestFilterOptions_Pages(t *testing.T) {
 	assert.Equal(t, "/foo", options[1])
 }
 
+func TestFilterOptions_Hostnames(t *testing.T) {
+	db.CleanupDB(t, dbClient)
+	assert.NoError(t, dbClient.SavePageViews([]model.PageView{
+		{VisitorID: 1, SessionID: 1, Time: util.PastDay(4), Hostname: "www.example.com", Path: "/"},
+		{VisitorID: 1, SessionID: 1, Time: util.PastDay(2), Hostname: "shop.example.com", Path: "/"},
+		{VisitorID: 1, SessionID: 2, Time: util.PastDay(2), Hostname: "shop.example.com", Path: "/cart"},
+		{VisitorID: 1, SessionID: 1, Time: util.PastDay(1), Hostname: "docs.example.com", Path: "/"},
+	}))
+	time.Sleep(time.Millisecond * 20)
+	analyzer := NewAnalyzer(dbClient)
+	options, err := analyzer.Options.Hostnames(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"docs.example.com", "shop.example.com", "www.example.com"}, options)
+	options, err = analyzer.Options.Hostnames(&Filter{From: util.PastDay(3), To: util.Today()})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"docs.example.com", "shop.example.com"}, options)
+}
+
+func TestFilterOptions_QueryParams(t *testing.T) {
+	db.CleanupDB(t, dbClient)
+	assert.NoError(t, dbClient.SavePageViews([]model.PageView{
+		{VisitorID: 1, SessionID: 1, Time: util.PastDay(4), Path: "/", QueryParamKeys: []string{"variant"}, QueryParamValues: []string{"a"}},
+		{VisitorID: 1, SessionID: 1, Time: util.PastDay(2), Path: "/search", QueryParamKeys: []string{"q", "variant"}, QueryParamValues: []string{"shoes", "b"}},
+		{VisitorID: 1, SessionID: 2, Time: util.PastDay(1), Path: "/search", QueryParamKeys: []string{"q"}, QueryParamValues: []string{"boots"}},
+		{VisitorID: 1, SessionID: 2, Time: util.PastDay(1), Path: "/"},
+	}))
+	time.Sleep(time.Millisecond * 20)
+	analyzer := NewAnalyzer(dbClient)
+	options, err := analyzer.Options.QueryParamKeys(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"q", "variant"}, options)
+	options, err = analyzer.Options.QueryParamValues(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, options)
+	options, err = analyzer.Options.QueryParamValues(&Filter{QueryParamKey: []string{"variant"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, options)
+	options, err = analyzer.Options.QueryParamValues(&Filter{From: util.PastDay(3), To: util.Today(), QueryParamKey: []string{"variant"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b"}, options)
+}
+
 func TestFilterOptions_Referrer(t *testing.T) {
 	db.CleanupDB(t, dbClient)
 	assert.NoError(t, dbClient.SaveSessions([]model.Session{
@@ -179,6 +221,24 @@ func TestFilterOptions_Countries(t *testing.T) {
 	assert.Equal(t, "ja", options[1])
 }
 
+func TestFilterOptions_Regions(t *testing.T) {
+	db.CleanupDB(t, dbClient)
+	assert.NoError(t, dbClient.SaveSessions([]model.Session{
+		{Sign: 1, VisitorID: 1, SessionID: 1, Time: util.PastDay(4), Start: util.PastDay(4), Region: "Massachusetts"},
+		{Sign: 1, VisitorID: 1, SessionID: 1, Time: util.PastDay(2), Start: util.PastDay(2), Region: "Tokyo"},
+		{Sign: 1, VisitorID: 1, SessionID: 2, Time: util.PastDay(2), Start: util.PastDay(2), Region: "Tokyo"},
+		{Sign: 1, VisitorID: 1, SessionID: 1, Time: util.PastDay(1), Start: util.PastDay(1), Region: "Bavaria"},
+	}))
+	time.Sleep(time.Millisecond * 20)
+	analyzer := NewAnalyzer(dbClient)
+	options, err := analyzer.Options.Regions(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bavaria", "Massachusetts", "Tokyo"}, options)
+	options, err = analyzer.Options.Regions(&Filter{From: util.PastDay(3), To: util.Today()})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bavaria", "Tokyo"}, options)
+}
+
 func TestFilterOptions_Cities(t *testing.T) {
 	db.CleanupDB(t, dbClient)
 	assert.NoError(t, dbClient.SaveSessions([]model.Session{
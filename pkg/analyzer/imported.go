@@ -0,0 +1,405 @@
+package analyzer
+
+import (
+	"fmt"
+	"github.com/pirsch-analytics/pirsch/v6/pkg"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/model"
+	"sort"
+	"strings"
+	"time"
+)
+
+const importedBounceRate = "if(sum(sessions) = 0, 0, sum(bounces) / sum(sessions))"
+
+// importedRange returns the date range (inclusive) for imported statistics and whether they should be merged.
+// Imported statistics are aggregated by day and cannot be filtered, so they are only merged if no other filter is set.
+func (filter *Filter) importedRange() (time.Time, time.Time, bool) {
+	if filter.ImportedUntil.IsZero() || filter.IncludeTime || filter.hasFieldFilter() {
+		return time.Time{}, time.Time{}, false
+	}
+
+	to := filter.ImportedUntil.Add(-time.Hour * 24)
+
+	if !filter.To.IsZero() && filter.To.Before(to) {
+		to = filter.To
+	}
+
+	if !filter.From.IsZero() && filter.From.After(to) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	return filter.From, to, true
+}
+
+func (filter *Filter) hasFieldFilter() bool {
+	return len(filter.Hostname) != 0 ||
+		len(filter.Path) != 0 ||
+		len(filter.AnyPath) != 0 ||
+		len(filter.EntryPath) != 0 ||
+		len(filter.ExitPath) != 0 ||
+		len(filter.PathPattern) != 0 ||
+		len(filter.Language) != 0 ||
+		len(filter.Country) != 0 ||
+		len(filter.Continent) != 0 ||
+		len(filter.EconomicRegion) != 0 ||
+		len(filter.Region) != 0 ||
+		len(filter.City) != 0 ||
+		len(filter.Referrer) != 0 ||
+		len(filter.ReferrerName) != 0 ||
+		len(filter.Channel) != 0 ||
+		len(filter.ConnectionType) != 0 ||
+		len(filter.OS) != 0 ||
+		len(filter.OSVersion) != 0 ||
+		len(filter.Browser) != 0 ||
+		len(filter.BrowserVersion) != 0 ||
+		filter.Platform != "" ||
+		len(filter.ScreenClass) != 0 ||
+		len(filter.UTMSource) != 0 ||
+		len(filter.UTMMedium) != 0 ||
+		len(filter.UTMCampaign) != 0 ||
+		len(filter.UTMContent) != 0 ||
+		len(filter.UTMTerm) != 0 ||
+		len(filter.EventName) != 0 ||
+		len(filter.EventMetaKey) != 0 ||
+		len(filter.EventMeta) != 0 ||
+		len(filter.QueryParamKey) != 0 ||
+		len(filter.QueryParam) != 0 ||
+		len(filter.Search) != 0
+}
+
+// importedQuery returns the query for given imported table.
+// The fields are selected and grouped by the groupBy column (if set).
+func (filter *Filter) importedQuery(table, fields, groupBy, orderBy string) (string, []any) {
+	from, to, _ := filter.importedRange()
+	args := []any{filter.ClientID, to.Format(dateFormat)}
+	var q strings.Builder
+	q.WriteString(fmt.Sprintf(`SELECT %s FROM "%s" FINAL WHERE client_id = ? AND "date" <= toDate(?) `, fields, table))
+
+	if !from.IsZero() {
+		args = append(args, from.Format(dateFormat))
+		q.WriteString(`AND "date" >= toDate(?) `)
+	}
+
+	if groupBy != "" {
+		q.WriteString(fmt.Sprintf("GROUP BY %s ORDER BY %s ", groupBy, orderBy))
+	}
+
+	return q.String(), args
+}
+
+// withoutLimit returns a copy of the filter without offset and limit.
+// This is used to select all native statistics, so that they can be merged with the imported statistics before limiting the results.
+func (filter *Filter) withoutLimit() *Filter {
+	filterCopy := *filter
+	filterCopy.Offset = 0
+	filterCopy.Limit = 0
+	return &filterCopy
+}
+
+// totalImported returns the total imported statistics for the filter.
+// The imported visitors are unique per day and summed up for the period,
+// so visitors returning on different days are counted once per day.
+func totalImported(store db.Store, filter *Filter) (*model.TotalVisitorStats, error) {
+	q, args := filter.importedQuery("imported_visitors", fmt.Sprintf(`sum(visitors), sum(sessions), sum(views), sum(bounces), %s`, importedBounceRate), "", "")
+	return store.GetTotalVisitorStats(q, false, false, args...)
+}
+
+// totalWithImported returns the total visitors and views for the filter including the imported statistics.
+// It is used to calculate the relative visitors and views after the imported statistics have been merged.
+func totalWithImported(store db.Store, filter *Filter) (*model.TotalVisitorsPageViewsStats, error) {
+	filterCopy := filter.withoutLimit()
+	filterCopy.Sort = nil
+	q, args := filterCopy.buildQuery([]Field{
+		FieldVisitors,
+		FieldViews,
+	}, nil, nil)
+	total, err := store.GetTotalVisitorsPageViewsStats(q, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	imported, err := totalImported(store, filter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(model.TotalVisitorsPageViewsStats)
+
+	if total != nil {
+		result.Visitors = total.Visitors
+		result.Views = total.Views
+	}
+
+	if imported != nil {
+		result.Visitors += imported.Visitors
+		result.Views += imported.Views
+	}
+
+	return result, nil
+}
+
+func (visitors *Visitors) mergeImportedTotal(filter *Filter, stats *model.TotalVisitorStats) error {
+	if _, _, ok := filter.importedRange(); !ok {
+		return nil
+	}
+
+	imported, err := totalImported(visitors.store, filter)
+
+	if err != nil {
+		return err
+	}
+
+	stats.Visitors += imported.Visitors
+	stats.Sessions += imported.Sessions
+	stats.Views += imported.Views
+	stats.Bounces += imported.Bounces
+	stats.BounceRate = bounceRate(stats.Bounces, stats.Sessions)
+	return nil
+}
+
+func (visitors *Visitors) mergeImportedByPeriod(filter *Filter, stats []model.VisitorStats) ([]model.VisitorStats, error) {
+	if _, _, ok := filter.importedRange(); !ok {
+		return stats, nil
+	}
+
+	var period string
+
+	switch filter.Period {
+	case pkg.PeriodWeek:
+		period = `toStartOfWeek("date", 1) week`
+	case pkg.PeriodMonth:
+		period = `toStartOfMonth("date") month`
+	case pkg.PeriodYear:
+		period = `toStartOfYear("date") year`
+	default:
+		period = `"date" "day"`
+	}
+
+	name := period[strings.LastIndex(period, " ")+1:]
+	q, args := filter.importedQuery("imported_visitors", fmt.Sprintf(`%s, sum(visitors), sum(sessions), sum(views), sum(bounces), %s`, period, importedBounceRate), name, name)
+	imported, err := visitors.store.SelectVisitorStats(filter.Period, q, false, false, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stats = mergeImported(stats, imported, func(s *model.VisitorStats) string {
+		return visitorStatsDate(s).Format(dateFormat)
+	}, func(s, imported *model.VisitorStats) {
+		s.Visitors += imported.Visitors
+		s.Sessions += imported.Sessions
+		s.Views += imported.Views
+		s.Bounces += imported.Bounces
+		s.BounceRate = bounceRate(s.Bounces, s.Sessions)
+	})
+	sort.SliceStable(stats, func(i, j int) bool {
+		return visitorStatsDate(&stats[i]).Before(visitorStatsDate(&stats[j]))
+	})
+	return stats, nil
+}
+
+func (visitors *Visitors) mergeImportedReferrer(filter *Filter, stats []model.ReferrerStats) ([]model.ReferrerStats, error) {
+	if _, _, ok := filter.importedRange(); !ok {
+		return stats, nil
+	}
+
+	q, args := filter.importedQuery("imported_referrer", fmt.Sprintf(`referrer, '', sum(visitors), sum(sessions), toFloat64(0), sum(bounces), %s, ''`, importedBounceRate), "referrer", "referrer")
+	imported, err := visitors.store.SelectReferrerStats(q, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := totalWithImported(visitors.store, filter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stats = mergeImported(stats, imported, func(s *model.ReferrerStats) string {
+		return s.ReferrerName
+	}, func(s, imported *model.ReferrerStats) {
+		s.Visitors += imported.Visitors
+		s.Sessions += imported.Sessions
+		s.Bounces += imported.Bounces
+		s.BounceRate = bounceRate(s.Bounces, s.Sessions)
+	})
+
+	for i := range stats {
+		stats[i].RelativeVisitors = relative(stats[i].Visitors, total.Visitors)
+	}
+
+	sortImported(filter, stats, func(s *model.ReferrerStats) (int, string) {
+		return s.Visitors, s.ReferrerName
+	})
+	return limitImported(filter, stats), nil
+}
+
+func (pages *Pages) mergeImportedByPath(filter *Filter, stats []model.PageStats) ([]model.PageStats, error) {
+	if _, _, ok := filter.importedRange(); !ok {
+		return stats, nil
+	}
+
+	fields := fmt.Sprintf(`path, sum(visitors), sum(sessions), toFloat64(0), sum(views), toFloat64(0), sum(bounces), %s`, importedBounceRate)
+
+	if filter.IncludeTitle {
+		fields += ", ''"
+	}
+
+	q, args := filter.importedQuery("imported_page", fields, "path", "path")
+	imported, err := pages.store.SelectPageStats(filter.IncludeTitle, false, q, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := totalWithImported(pages.store, filter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stats = mergeImported(stats, imported, func(s *model.PageStats) string {
+		return s.Path
+	}, func(s, imported *model.PageStats) {
+		s.Visitors += imported.Visitors
+		s.Sessions += imported.Sessions
+		s.Views += imported.Views
+		s.Bounces += imported.Bounces
+		s.BounceRate = bounceRate(s.Bounces, s.Sessions)
+	})
+
+	for i := range stats {
+		stats[i].RelativeVisitors = relative(stats[i].Visitors, total.Visitors)
+		stats[i].RelativeViews = relative(stats[i].Views, total.Views)
+	}
+
+	sortImported(filter, stats, func(s *model.PageStats) (int, string) {
+		return s.Visitors, s.Path
+	})
+	return limitImported(filter, stats), nil
+}
+
+func (demographics *Demographics) mergeImportedCountries(filter *Filter, stats []model.CountryStats) ([]model.CountryStats, error) {
+	if _, _, ok := filter.importedRange(); !ok {
+		return stats, nil
+	}
+
+	q, args := filter.importedQuery("imported_country", "country_code, sum(visitors), toFloat64(0)", "country_code", "country_code")
+	imported, err := demographics.store.SelectCountryStats(q, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := totalWithImported(demographics.store, filter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stats = mergeImported(stats, imported, func(s *model.CountryStats) string {
+		return s.CountryCode
+	}, func(s, imported *model.CountryStats) {
+		s.Visitors += imported.Visitors
+	})
+
+	for i := range stats {
+		stats[i].RelativeVisitors = relative(stats[i].Visitors, total.Visitors)
+	}
+
+	sortImported(filter, stats, func(s *model.CountryStats) (int, string) {
+		return s.Visitors, s.CountryCode
+	})
+	return limitImported(filter, stats), nil
+}
+
+// mergeImported adds the imported statistics to the native statistics with the same key.
+// Imported statistics without native counterpart are appended.
+func mergeImported[T any](stats, imported []T, key func(*T) string, add func(*T, *T)) []T {
+	index := make(map[string]int, len(stats))
+
+	for i := range stats {
+		k := key(&stats[i])
+
+		if _, ok := index[k]; !ok {
+			index[k] = i
+		}
+	}
+
+	for i := range imported {
+		if j, ok := index[key(&imported[i])]; ok {
+			add(&stats[j], &imported[i])
+		} else {
+			stats = append(stats, imported[i])
+		}
+	}
+
+	return stats
+}
+
+// sortImported sorts the statistics by visitors (descending) and key (ascending), unless a custom order has been set.
+func sortImported[T any](filter *Filter, stats []T, sortBy func(*T) (int, string)) {
+	if len(filter.Sort) > 0 {
+		return
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		visitorsA, keyA := sortBy(&stats[i])
+		visitorsB, keyB := sortBy(&stats[j])
+
+		if visitorsA != visitorsB {
+			return visitorsA > visitorsB
+		}
+
+		return keyA < keyB
+	})
+}
+
+// limitImported applies the offset and limit of the filter after the imported statistics have been merged.
+func limitImported[T any](filter *Filter, stats []T) []T {
+	if filter.Offset > 0 {
+		if filter.Offset >= len(stats) {
+			return stats[:0]
+		}
+
+		stats = stats[filter.Offset:]
+	}
+
+	if filter.Limit > 0 && filter.Limit < len(stats) {
+		stats = stats[:filter.Limit]
+	}
+
+	return stats
+}
+
+func visitorStatsDate(stats *model.VisitorStats) time.Time {
+	if stats.Week.Valid {
+		return stats.Week.Time
+	} else if stats.Month.Valid {
+		return stats.Month.Time
+	} else if stats.Year.Valid {
+		return stats.Year.Time
+	}
+
+	return stats.Day.Time
+}
+
+func relative(value, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+
+	return float64(value) / float64(total)
+}
+
+func bounceRate(bounces, sessions int) float64 {
+	if sessions == 0 {
+		return 0
+	}
+
+	return float64(bounces) / float64(sessions)
+}
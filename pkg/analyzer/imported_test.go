@@ -0,0 +1,146 @@
+package analyzer
+
+import (
+	"github.com/pirsch-analytics/pirsch/v6/pkg"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/model"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAnalyzer_Imported(t *testing.T) {
+	db.CleanupDB(t, dbClient)
+	saveSessions(t, [][]model.Session{
+		{
+			{Sign: 1, VisitorID: 1, Time: util.PastDay(1), Start: util.PastDay(1), EntryPath: "/", ExitPath: "/", PageViews: 1, IsBounce: true, Referrer: "https://google.com", ReferrerName: "Google", CountryCode: "de"},
+			{Sign: 1, VisitorID: 2, Time: util.PastDay(1), Start: util.PastDay(1), EntryPath: "/", ExitPath: "/about", PageViews: 2, CountryCode: "us"},
+		},
+	})
+	assert.NoError(t, dbClient.SavePageViews([]model.PageView{
+		{VisitorID: 1, Time: util.PastDay(1), Path: "/"},
+		{VisitorID: 2, Time: util.PastDay(1), Path: "/"},
+		{VisitorID: 2, Time: util.PastDay(1), Path: "/about"},
+	}))
+	assert.NoError(t, dbClient.SaveImportedVisitors([]model.ImportedVisitors{
+		{Date: util.PastDay(4), Visitors: 10, Views: 20, Sessions: 12, Bounces: 6},
+		{Date: util.PastDay(3), Visitors: 5, Views: 5, Sessions: 5, Bounces: 5},
+		{Date: util.PastDay(1), Visitors: 100, Views: 100, Sessions: 100, Bounces: 100},
+	}))
+	assert.NoError(t, dbClient.SaveImportedPages([]model.ImportedPage{
+		{Date: util.PastDay(4), Path: "/", Visitors: 10, Views: 15, Sessions: 12, Bounces: 6},
+		{Date: util.PastDay(3), Path: "/blog", Visitors: 5, Views: 5, Sessions: 5, Bounces: 5},
+	}))
+	assert.NoError(t, dbClient.SaveImportedReferrers([]model.ImportedReferrer{
+		{Date: util.PastDay(4), Referrer: "Google", Visitors: 4, Sessions: 4, Bounces: 2},
+		{Date: util.PastDay(3), Referrer: "Newsletter", Visitors: 5, Sessions: 5, Bounces: 5},
+	}))
+	assert.NoError(t, dbClient.SaveImportedCountries([]model.ImportedCountry{
+		{Date: util.PastDay(4), CountryCode: "de", Visitors: 10},
+		{Date: util.PastDay(3), CountryCode: "fr", Visitors: 5},
+	}))
+	time.Sleep(time.Millisecond * 20)
+	analyzer := NewAnalyzer(dbClient)
+	filter := &Filter{From: util.PastDay(4), To: util.Today(), ImportedUntil: util.PastDay(2)}
+	total, err := analyzer.Visitors.Total(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 17, total.Visitors)
+	assert.Equal(t, 19, total.Sessions)
+	assert.Equal(t, 28, total.Views)
+	assert.Equal(t, 12, total.Bounces)
+	byPeriod, err := analyzer.Visitors.ByPeriod(filter)
+	assert.NoError(t, err)
+	assert.Len(t, byPeriod, 5)
+	assert.Equal(t, 10, byPeriod[0].Visitors)
+	assert.Equal(t, 5, byPeriod[1].Visitors)
+	assert.Equal(t, 0, byPeriod[2].Visitors)
+	assert.Equal(t, 2, byPeriod[3].Visitors)
+	assert.Equal(t, 0, byPeriod[4].Visitors)
+	pages, err := analyzer.Pages.ByPath(filter)
+	assert.NoError(t, err)
+	assert.Len(t, pages, 3)
+	assert.Equal(t, "/", pages[0].Path)
+	assert.Equal(t, 12, pages[0].Visitors)
+	assert.Equal(t, 17, pages[0].Views)
+	assert.InDelta(t, 12.0/17.0, pages[0].RelativeVisitors, 0.01)
+	assert.Equal(t, "/blog", pages[1].Path)
+	assert.Equal(t, "/about", pages[2].Path)
+	referrer, err := analyzer.Visitors.Referrer(filter)
+	assert.NoError(t, err)
+	assert.Len(t, referrer, 3)
+	assert.Equal(t, "Google", referrer[0].ReferrerName)
+	assert.Equal(t, 5, referrer[0].Visitors)
+	assert.Equal(t, "Newsletter", referrer[1].ReferrerName)
+	assert.Equal(t, 5, referrer[1].Visitors)
+	countries, err := analyzer.Demographics.Countries(filter)
+	assert.NoError(t, err)
+	assert.Len(t, countries, 3)
+	assert.Equal(t, "de", countries[0].CountryCode)
+	assert.Equal(t, 11, countries[0].Visitors)
+	assert.Equal(t, "fr", countries[1].CountryCode)
+	assert.Equal(t, "us", countries[2].CountryCode)
+	countries, err = analyzer.Demographics.Countries(&Filter{From: util.PastDay(4), To: util.Today(), ImportedUntil: util.PastDay(2), Offset: 1, Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, countries, 1)
+	assert.Equal(t, "fr", countries[0].CountryCode)
+
+	// imported statistics are not merged if another filter is set
+	total, err = analyzer.Visitors.Total(&Filter{From: util.PastDay(4), To: util.Today(), ImportedUntil: util.PastDay(2), Path: []string{"/"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total.Visitors)
+	total, err = analyzer.Visitors.Total(&Filter{From: util.PastDay(4), To: util.Today()})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total.Visitors)
+}
+
+func TestFilter_importedRange(t *testing.T) {
+	from, to, ok := (&Filter{}).importedRange()
+	assert.False(t, ok)
+	assert.True(t, from.IsZero())
+	assert.True(t, to.IsZero())
+	importedUntil := time.Date(2023, 10, 10, 0, 0, 0, 0, time.UTC)
+	from, to, ok = (&Filter{From: importedUntil.Add(-time.Hour * 24 * 30), To: importedUntil.Add(time.Hour * 24 * 30), ImportedUntil: importedUntil}).importedRange()
+	assert.True(t, ok)
+	assert.Equal(t, importedUntil.Add(-time.Hour*24*30), from)
+	assert.Equal(t, importedUntil.Add(-time.Hour*24), to)
+	_, to, ok = (&Filter{To: importedUntil.Add(-time.Hour * 24 * 5), ImportedUntil: importedUntil}).importedRange()
+	assert.True(t, ok)
+	assert.Equal(t, importedUntil.Add(-time.Hour*24*5), to)
+	_, _, ok = (&Filter{From: importedUntil, ImportedUntil: importedUntil}).importedRange()
+	assert.False(t, ok)
+	_, _, ok = (&Filter{ImportedUntil: importedUntil, Country: []string{"de"}}).importedRange()
+	assert.False(t, ok)
+	_, _, ok = (&Filter{ImportedUntil: importedUntil, IncludeTime: true}).importedRange()
+	assert.False(t, ok)
+}
+
+func TestMergeImported(t *testing.T) {
+	stats := []model.CountryStats{
+		{CountryCode: "de", MetaStats: model.MetaStats{Visitors: 3, RelativeVisitors: 0.75}},
+		{CountryCode: "us", MetaStats: model.MetaStats{Visitors: 1, RelativeVisitors: 0.25}},
+	}
+	stats = mergeImported(stats, []model.CountryStats{
+		{CountryCode: "us", MetaStats: model.MetaStats{Visitors: 5}},
+		{CountryCode: "fr", MetaStats: model.MetaStats{Visitors: 1}},
+	}, func(s *model.CountryStats) string {
+		return s.CountryCode
+	}, func(s, imported *model.CountryStats) {
+		s.Visitors += imported.Visitors
+	})
+	sortImported(&Filter{}, stats, func(s *model.CountryStats) (int, string) {
+		return s.Visitors, s.CountryCode
+	})
+	assert.Len(t, stats, 3)
+	assert.Equal(t, "us", stats[0].CountryCode)
+	assert.Equal(t, 6, stats[0].Visitors)
+	assert.Equal(t, "de", stats[1].CountryCode)
+	assert.Equal(t, "fr", stats[2].CountryCode)
+	sortImported(&Filter{Sort: []Sort{{Field: FieldCountry, Direction: pkg.DirectionASC}}}, stats, func(s *model.CountryStats) (int, string) {
+		return 0, ""
+	})
+	assert.Equal(t, "us", stats[0].CountryCode)
+	assert.Len(t, limitImported(&Filter{Offset: 1, Limit: 1}, stats), 1)
+	assert.Equal(t, "de", limitImported(&Filter{Offset: 1, Limit: 1}, stats)[0].CountryCode)
+	assert.Empty(t, limitImported(&Filter{Offset: 3}, stats))
+}
@@ -67,13 +67,28 @@ func (pages *Pages) byPath(filter *Filter, eventPath bool) ([]model.PageStats, e
 		}
 	}
 
-	q, args := filter.buildQuery(fields, groupBy, orderBy)
+	queryFilter := filter
+	_, _, importing := filter.importedRange()
+
+	if importing && !eventPath {
+		queryFilter = filter.withoutLimit()
+	}
+
+	q, args := queryFilter.buildQuery(fields, groupBy, orderBy)
 	stats, err := pages.store.SelectPageStats(filter.IncludeTitle, false, q, args...)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if importing && !eventPath {
+		stats, err = pages.mergeImportedByPath(filter, stats)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if filter.IncludeTimeOnPage {
 		pathList := getPathList(stats)
 		top, err := pages.avgTimeOnPage(filter, pathList)
@@ -95,6 +110,25 @@ func (pages *Pages) byPath(filter *Filter, eventPath bool) ([]model.PageStats, e
 	return stats, nil
 }
 
+// Hostname returns the visitor count grouped by hostname.
+func (pages *Pages) Hostname(filter *Filter) ([]model.HostnameStats, error) {
+	q, args := pages.analyzer.selectByAttribute(filter, FieldHostname)
+	return pages.store.SelectHostnameStats(q, args...)
+}
+
+// QueryParam returns the visitor count grouped by the value of the query parameter set by the first Filter.QueryParamKey.
+// The Filter.QueryParamKey must be set, or otherwise the result set will be empty.
+func (pages *Pages) QueryParam(filter *Filter) ([]model.QueryParamStats, error) {
+	filter = pages.analyzer.getFilter(filter)
+
+	if len(filter.QueryParamKey) == 0 {
+		return []model.QueryParamStats{}, nil
+	}
+
+	q, args := pages.analyzer.selectByAttribute(filter, FieldQueryParamValue)
+	return pages.store.SelectQueryParamStats(q, args...)
+}
+
 // Entry returns the visitor count and time on page grouped by path and (optional) page title for the first page visited.
 func (pages *Pages) Entry(filter *Filter) ([]model.EntryStats, error) {
 	filter = pages.analyzer.getFilter(filter)
@@ -804,6 +804,91 @@ func TestAnalyzer_PathPattern(t *testing.T) {
 	assert.Len(t, visitors, 1)
 }
 
+func TestAnalyzer_Hostname(t *testing.T) {
+	db.CleanupDB(t, dbClient)
+	assert.NoError(t, dbClient.SavePageViews([]model.PageView{
+		{VisitorID: 1, Time: util.Today(), Hostname: "www.example.com", Path: "/"},
+		{VisitorID: 1, Time: util.Today(), Hostname: "shop.example.com", Path: "/"},
+		{VisitorID: 2, Time: util.Today(), Hostname: "www.example.com", Path: "/"},
+		{VisitorID: 3, Time: util.Today(), Hostname: "docs.example.com", Path: "/"},
+		{VisitorID: 3, Time: util.Today(), Hostname: "docs.example.com", Path: "/install"},
+	}))
+	saveSessions(t, [][]model.Session{
+		{
+			{Sign: 1, VisitorID: 1, Time: util.Today(), Start: time.Now(), Hostname: "www.example.com", EntryPath: "/", ExitPath: "/"},
+			{Sign: 1, VisitorID: 2, Time: util.Today(), Start: time.Now(), Hostname: "www.example.com", EntryPath: "/", ExitPath: "/"},
+			{Sign: 1, VisitorID: 3, Time: util.Today(), Start: time.Now(), Hostname: "docs.example.com", EntryPath: "/", ExitPath: "/install"},
+		},
+	})
+	time.Sleep(time.Millisecond * 20)
+	analyzer := NewAnalyzer(dbClient)
+	hostnames, err := analyzer.Pages.Hostname(nil)
+	assert.NoError(t, err)
+	assert.Len(t, hostnames, 2)
+	assert.Equal(t, "www.example.com", hostnames[0].Hostname)
+	assert.Equal(t, 2, hostnames[0].Visitors)
+	assert.Equal(t, "docs.example.com", hostnames[1].Hostname)
+	assert.Equal(t, 1, hostnames[1].Visitors)
+	visitors, err := analyzer.Pages.ByPath(&Filter{Hostname: []string{"www.example.com"}})
+	assert.NoError(t, err)
+	assert.Len(t, visitors, 1)
+	assert.Equal(t, "/", visitors[0].Path)
+	assert.Equal(t, 2, visitors[0].Visitors)
+	visitors, err = analyzer.Pages.ByPath(&Filter{Hostname: []string{"~shop"}})
+	assert.NoError(t, err)
+	assert.Len(t, visitors, 1)
+	assert.Equal(t, 1, visitors[0].Visitors)
+	visitors, err = analyzer.Pages.ByPath(&Filter{Hostname: []string{"!www.example.com"}})
+	assert.NoError(t, err)
+	assert.Len(t, visitors, 2)
+	total, err := analyzer.Visitors.Total(&Filter{Hostname: []string{"docs.example.com"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total.Visitors)
+}
+
+func TestAnalyzer_QueryParam(t *testing.T) {
+	db.CleanupDB(t, dbClient)
+	assert.NoError(t, dbClient.SavePageViews([]model.PageView{
+		{VisitorID: 1, Time: util.Today(), Path: "/", QueryParamKeys: []string{"variant"}, QueryParamValues: []string{"a"}},
+		{VisitorID: 1, Time: util.Today(), Path: "/search", QueryParamKeys: []string{"q", "variant"}, QueryParamValues: []string{"shoes", "a"}},
+		{VisitorID: 2, Time: util.Today(), Path: "/", QueryParamKeys: []string{"variant"}, QueryParamValues: []string{"b"}},
+		{VisitorID: 3, Time: util.Today(), Path: "/search", QueryParamKeys: []string{"q"}, QueryParamValues: []string{"boots"}},
+		{VisitorID: 3, Time: util.Today(), Path: "/"},
+	}))
+	saveSessions(t, [][]model.Session{
+		{
+			{Sign: 1, VisitorID: 1, Time: util.Today(), Start: time.Now(), EntryPath: "/", ExitPath: "/search"},
+			{Sign: 1, VisitorID: 2, Time: util.Today(), Start: time.Now(), EntryPath: "/", ExitPath: "/"},
+			{Sign: 1, VisitorID: 3, Time: util.Today(), Start: time.Now(), EntryPath: "/search", ExitPath: "/"},
+		},
+	})
+	time.Sleep(time.Millisecond * 20)
+	analyzer := NewAnalyzer(dbClient)
+	stats, err := analyzer.Pages.QueryParam(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, stats)
+	stats, err = analyzer.Pages.QueryParam(&Filter{QueryParamKey: []string{"variant"}})
+	assert.NoError(t, err)
+	assert.Len(t, stats, 2)
+	assert.Equal(t, "a", stats[0].Value)
+	assert.Equal(t, 1, stats[0].Visitors)
+	assert.Equal(t, "b", stats[1].Value)
+	assert.Equal(t, 1, stats[1].Visitors)
+	stats, err = analyzer.Pages.QueryParam(&Filter{QueryParamKey: []string{"q"}})
+	assert.NoError(t, err)
+	assert.Len(t, stats, 2)
+	visitors, err := analyzer.Pages.ByPath(&Filter{QueryParam: map[string]string{"variant": "a"}})
+	assert.NoError(t, err)
+	assert.Len(t, visitors, 2)
+	visitors, err = analyzer.Pages.ByPath(&Filter{QueryParam: map[string]string{"q": "~sho"}})
+	assert.NoError(t, err)
+	assert.Len(t, visitors, 1)
+	assert.Equal(t, "/search", visitors[0].Path)
+	total, err := analyzer.Visitors.Total(&Filter{QueryParamKey: []string{"q"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total.Visitors)
+}
+
 func TestAnalyzer_EntryExitPagePathFilter(t *testing.T) {
 	db.CleanupDB(t, dbClient)
 	assert.NoError(t, dbClient.SavePageViews([]model.PageView{
@@ -62,6 +62,7 @@ func (query *queryBuilder) query() (string, []any) {
 
 func (query *queryBuilder) getFields() []string {
 	fields := make([]string, 0, 25)
+	query.appendField(&fields, FieldHostname.Name, query.filter.Hostname)
 
 	if query.from == sessions {
 		query.appendField(&fields, FieldEntryPath.Name, query.filter.EntryPath)
@@ -72,6 +73,10 @@ func (query *queryBuilder) getFields() []string {
 		if len(query.filter.Path) == 0 && (len(query.filter.PathPattern) != 0 || len(query.filter.AnyPath) != 0) {
 			fields = append(fields, FieldPath.Name)
 		}
+
+		if query.filter.hasQueryParamFilter() {
+			fields = append(fields, "query_param_keys", "query_param_values")
+		}
 	}
 
 	if query.from == events {
@@ -86,9 +91,17 @@ func (query *queryBuilder) getFields() []string {
 
 	query.appendField(&fields, FieldLanguage.Name, query.filter.Language)
 	query.appendField(&fields, FieldCountry.Name, query.filter.Country)
+
+	if len(query.filter.Country) == 0 && (len(query.filter.Continent) != 0 || len(query.filter.EconomicRegion) != 0) {
+		fields = append(fields, FieldCountry.Name)
+	}
+
+	query.appendField(&fields, FieldRegion.Name, query.filter.Region)
 	query.appendField(&fields, FieldCity.Name, query.filter.City)
 	query.appendField(&fields, FieldReferrer.Name, query.filter.Referrer)
 	query.appendField(&fields, FieldReferrerName.Name, query.filter.ReferrerName)
+	query.appendField(&fields, FieldChannel.Name, query.filter.Channel)
+	query.appendField(&fields, FieldConnectionType.Name, query.filter.ConnectionType)
 	query.appendField(&fields, FieldOS.Name, query.filter.OS)
 	query.appendField(&fields, FieldOSVersion.Name, query.filter.OSVersion)
 	query.appendField(&fields, FieldBrowser.Name, query.filter.Browser)
@@ -158,6 +171,11 @@ func (query *queryBuilder) selectFields() bool {
 					query.args = append(query.args, query.filter.EventMetaKey[0])
 					q.WriteString(fmt.Sprintf("%s %s,", query.selectField(query.fields[i]), query.fields[i].Name))
 				}
+			} else if query.fields[i] == FieldQueryParamValue {
+				if len(query.filter.QueryParamKey) > 0 {
+					query.args = append(query.args, query.filter.QueryParamKey[0])
+					q.WriteString(fmt.Sprintf("%s %s,", query.selectField(query.fields[i]), query.fields[i].Name))
+				}
 			} else if query.fields[i] == FieldEventMetaCustomMetricAvg || query.fields[i] == FieldEventMetaCustomMetricTotal {
 				query.args = append(query.args, query.filter.CustomMetricKey)
 				q.WriteString(fmt.Sprintf("%s %s,", fmt.Sprintf(query.selectField(query.fields[i]), query.filter.CustomMetricType), query.fields[i].Name))
@@ -290,6 +308,8 @@ func (query *queryBuilder) whereTime() string {
 }
 
 func (query *queryBuilder) whereFields() {
+	query.whereField(FieldHostname.Name, query.filter.Hostname)
+
 	if query.from == sessions {
 		query.whereField(FieldEntryPath.Name, query.filter.EntryPath)
 		query.whereField(FieldExitPath.Name, query.filter.ExitPath)
@@ -297,6 +317,8 @@ func (query *queryBuilder) whereFields() {
 		query.whereField(FieldPath.Name, query.filter.Path)
 		query.whereFieldPathPattern()
 		query.whereFieldPathIn()
+		query.whereField("query_param_keys", query.filter.QueryParamKey)
+		query.whereFieldQueryParam()
 	}
 
 	if query.from == events || query.includeEventFilter {
@@ -308,9 +330,14 @@ func (query *queryBuilder) whereFields() {
 
 	query.whereField(FieldLanguage.Name, query.filter.Language)
 	query.whereField(FieldCountry.Name, query.filter.Country)
+	query.whereField(continentQuery, query.filter.Continent)
+	query.whereField(economicRegionQuery, query.filter.EconomicRegion)
+	query.whereField(FieldRegion.Name, query.filter.Region)
 	query.whereField(FieldCity.Name, query.filter.City)
 	query.whereField(FieldReferrer.Name, query.filter.Referrer)
 	query.whereField(FieldReferrerName.Name, query.filter.ReferrerName)
+	query.whereField(FieldChannel.Name, query.filter.Channel)
+	query.whereField(FieldConnectionType.Name, query.filter.ConnectionType)
 	query.whereField(FieldOS.Name, query.filter.OS)
 	query.whereField(FieldOSVersion.Name, query.filter.OSVersion)
 	query.whereField(FieldBrowser.Name, query.filter.Browser)
@@ -359,7 +386,7 @@ func (query *queryBuilder) whereField(field string, value []string) {
 			comparator := "%s = ? "
 			not := strings.HasPrefix(v, "!")
 
-			if field == "event_meta_keys" {
+			if field == "event_meta_keys" || field == "query_param_keys" {
 				if not {
 					v = v[1:]
 					comparator = "!has(%s, ?) "
@@ -499,6 +526,30 @@ func (query *queryBuilder) whereFieldMeta() {
 	}
 }
 
+func (query *queryBuilder) whereFieldQueryParam() {
+	if len(query.filter.QueryParam) != 0 {
+		var group where
+
+		for k, v := range query.filter.QueryParam {
+			comparator := "query_param_values[indexOf(query_param_keys, ?)] = ? "
+
+			if strings.HasPrefix(v, "!") {
+				v = v[1:]
+				comparator = "query_param_values[indexOf(query_param_keys, ?)] != ? "
+			} else if strings.HasPrefix(v, "~") {
+				v = fmt.Sprintf("%%%s%%", v[1:])
+				comparator = "ilike(query_param_values[indexOf(query_param_keys, ?)], ?) = 1 "
+			}
+
+			// use notEq because they will all be joined using AND
+			query.args = append(query.args, k, query.nullValue(v))
+			group.notEq = append(group.notEq, comparator)
+		}
+
+		query.where = append(query.where, group)
+	}
+}
+
 func (query *queryBuilder) whereFieldPlatform() {
 	if query.filter.Platform != "" {
 		if strings.HasPrefix(query.filter.Platform, "!") {
@@ -1,6 +1,7 @@
 package analyzer
 
 import (
+	"fmt"
 	"github.com/pirsch-analytics/pirsch/v6/pkg"
 	"github.com/pirsch-analytics/pirsch/v6/pkg/util"
 	"github.com/stretchr/testify/assert"
@@ -109,6 +110,198 @@ func TestQueryAnyPath(t *testing.T) {
 	assert.Equal(t, `SELECT path path,uniq(t.visitor_id) visitors FROM "page_view" t WHERE client_id = ? AND toDate(time, 'UTC') >= toDate(?) AND toDate(time, 'UTC') <= toDate(?) AND path IN (?,?) GROUP BY path `, queryStr)
 }
 
+func TestQueryHostname(t *testing.T) {
+	q := queryBuilder{
+		filter: &Filter{
+			ClientID: 42,
+			From:     util.PastDay(7),
+			To:       util.Today(),
+			Hostname: []string{"www.example.com", "~shop", "!docs.example.com"},
+		},
+		fields: []Field{
+			FieldPath,
+			FieldVisitors,
+		},
+		from: pageViews,
+		groupBy: []Field{
+			FieldPath,
+		},
+	}
+	queryStr, args := q.query()
+	assert.Len(t, args, 6)
+	assert.Equal(t, "www.example.com", args[3])
+	assert.Equal(t, "%shop%", args[4])
+	assert.Equal(t, "docs.example.com", args[5])
+	assert.Equal(t, `SELECT path path,uniq(t.visitor_id) visitors FROM "page_view" t WHERE client_id = ? AND toDate(time, 'UTC') >= toDate(?) AND toDate(time, 'UTC') <= toDate(?) AND (hostname = ? OR ilike(hostname, ?) = 1 ) AND hostname != ? GROUP BY path `, queryStr)
+}
+
+func TestQueryQueryParam(t *testing.T) {
+	q := queryBuilder{
+		filter: &Filter{
+			ClientID:      42,
+			From:          util.PastDay(7),
+			To:            util.Today(),
+			QueryParamKey: []string{"q"},
+			QueryParam:    map[string]string{"variant": "!b"},
+		},
+		fields: []Field{
+			FieldQueryParamValue,
+			FieldVisitors,
+		},
+		from: pageViews,
+		groupBy: []Field{
+			FieldQueryParamValue,
+		},
+	}
+	queryStr, args := q.query()
+	assert.Len(t, args, 7)
+	assert.Equal(t, "q", args[0])
+	assert.Equal(t, "q", args[4])
+	assert.Equal(t, "variant", args[5])
+	assert.Equal(t, "b", args[6])
+	assert.Equal(t, `SELECT query_param_values[indexOf(query_param_keys, ?)] query_param_value,uniq(t.visitor_id) visitors FROM "page_view" t WHERE client_id = ? AND toDate(time, 'UTC') >= toDate(?) AND toDate(time, 'UTC') <= toDate(?) AND has(query_param_keys, ?) AND query_param_values[indexOf(query_param_keys, ?)] != ? GROUP BY query_param_value `, queryStr)
+}
+
+func TestQueryChannel(t *testing.T) {
+	q := queryBuilder{
+		filter: &Filter{
+			ClientID: 42,
+			From:     util.PastDay(7),
+			To:       util.Today(),
+			Channel:  []string{"Social", "!Direct"},
+		},
+		fields: []Field{
+			FieldChannel,
+			FieldVisitors,
+		},
+		from: sessions,
+		groupBy: []Field{
+			FieldChannel,
+		},
+	}
+	queryStr, args := q.query()
+	assert.Len(t, args, 5)
+	assert.Equal(t, "Social", args[3])
+	assert.Equal(t, "Direct", args[4])
+	assert.Equal(t, `SELECT channel channel,uniq(t.visitor_id) visitors FROM "session" t WHERE client_id = ? AND toDate(time, 'UTC') >= toDate(?) AND toDate(time, 'UTC') <= toDate(?) AND channel = ? AND channel != ? GROUP BY channel HAVING sum(sign) > 0 `, queryStr)
+}
+
+func TestQueryConnectionType(t *testing.T) {
+	q := queryBuilder{
+		filter: &Filter{
+			ClientID:       42,
+			From:           util.PastDay(7),
+			To:             util.Today(),
+			ConnectionType: []string{"!hosting"},
+		},
+		fields: []Field{
+			FieldConnectionType,
+			FieldVisitors,
+		},
+		from: sessions,
+		groupBy: []Field{
+			FieldConnectionType,
+		},
+	}
+	queryStr, args := q.query()
+	assert.Len(t, args, 4)
+	assert.Equal(t, "hosting", args[3])
+	assert.Equal(t, `SELECT connection_type connection_type,uniq(t.visitor_id) visitors FROM "session" t WHERE client_id = ? AND toDate(time, 'UTC') >= toDate(?) AND toDate(time, 'UTC') <= toDate(?) AND connection_type != ? GROUP BY connection_type HAVING sum(sign) > 0 `, queryStr)
+}
+
+func TestQueryRegion(t *testing.T) {
+	q := queryBuilder{
+		filter: &Filter{
+			ClientID: 42,
+			From:     util.PastDay(7),
+			To:       util.Today(),
+			Region:   []string{"Oregon", "!England"},
+		},
+		fields: []Field{
+			FieldRegion,
+			FieldCountryRegion,
+			FieldVisitors,
+		},
+		from: sessions,
+		groupBy: []Field{
+			FieldRegion,
+			FieldCountryRegion,
+		},
+	}
+	queryStr, args := q.query()
+	assert.Len(t, args, 5)
+	assert.Equal(t, "Oregon", args[3])
+	assert.Equal(t, "England", args[4])
+	assert.Equal(t, `SELECT region region,if(region = '', '', country_code) country_code,uniq(t.visitor_id) visitors FROM "session" t WHERE client_id = ? AND toDate(time, 'UTC') >= toDate(?) AND toDate(time, 'UTC') <= toDate(?) AND region = ? AND region != ? GROUP BY region,country_code HAVING sum(sign) > 0 `, queryStr)
+}
+
+func TestQueryCity(t *testing.T) {
+	q := queryBuilder{
+		filter: &Filter{
+			ClientID: 42,
+			From:     util.PastDay(7),
+			To:       util.Today(),
+			City:     []string{"Portland"},
+		},
+		fields: []Field{
+			FieldCity,
+			FieldCountryCity,
+			FieldRegionCity,
+			FieldVisitors,
+		},
+		from: sessions,
+		groupBy: []Field{
+			FieldCity,
+			FieldCountryCity,
+			FieldRegionCity,
+		},
+	}
+	queryStr, args := q.query()
+	assert.Len(t, args, 4)
+	assert.Equal(t, "Portland", args[3])
+	assert.Equal(t, `SELECT city city,if(city = '', '', country_code) country_code,if(city = '', '', region) region,uniq(t.visitor_id) visitors FROM "session" t WHERE client_id = ? AND toDate(time, 'UTC') >= toDate(?) AND toDate(time, 'UTC') <= toDate(?) AND city = ? GROUP BY city,country_code,region HAVING sum(sign) > 0 `, queryStr)
+}
+
+func TestQueryContinent(t *testing.T) {
+	q := queryBuilder{
+		filter: &Filter{
+			ClientID:       42,
+			From:           util.PastDay(7),
+			To:             util.Today(),
+			Continent:      []string{"EU"},
+			EconomicRegion: []string{"!EU"},
+		},
+		fields: []Field{
+			FieldContinent,
+			FieldVisitors,
+		},
+		from: sessions,
+		groupBy: []Field{
+			FieldContinent,
+		},
+	}
+	queryStr, args := q.query()
+	assert.Len(t, args, 5)
+	assert.Equal(t, "EU", args[3])
+	assert.Equal(t, "EU", args[4])
+	assert.Equal(t, fmt.Sprintf(`SELECT %s continent,uniq(t.visitor_id) visitors FROM "session" t WHERE client_id = ? AND toDate(time, 'UTC') >= toDate(?) AND toDate(time, 'UTC') <= toDate(?) AND %s = ? AND %s != ? GROUP BY continent HAVING sum(sign) > 0 `, continentQuery, continentQuery, economicRegionQuery), queryStr)
+	q = queryBuilder{
+		filter: &Filter{
+			ClientID:  42,
+			From:      util.PastDay(7),
+			To:        util.Today(),
+			Continent: []string{"EU"},
+			EventName: []string{"event"},
+		},
+		fields: []Field{
+			FieldVisitors,
+		},
+		from: events,
+	}
+	queryStr, _ = q.query()
+	assert.Contains(t, queryStr, fmt.Sprintf("AND %s = ? ", continentQuery))
+}
+
 func TestQueryPlatformSession(t *testing.T) {
 	q := queryBuilder{
 		filter: &Filter{
@@ -0,0 +1,98 @@
+package analyzer
+
+import (
+	"fmt"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/model"
+)
+
+// SiteSearch aggregates statistics regarding the site search, based on the search terms stored for page views.
+// Only the client ID, time range (From, To, Timezone), Offset, and Limit of the Filter are used.
+type SiteSearch struct {
+	analyzer *Analyzer
+	store    db.Store
+}
+
+// Terms returns the search terms ordered by the number of visitors.
+func (search *SiteSearch) Terms(filter *Filter) ([]model.SearchTermStats, error) {
+	filter = search.analyzer.getFilter(filter)
+	q := queryBuilder{
+		filter: filter,
+		offset: filter.Offset,
+		limit:  filter.Limit,
+	}
+	q.q.WriteString(`SELECT search_term,
+		uniq(visitor_id) visitors,
+		count(*) searches
+		FROM "page_view" `)
+	q.q.WriteString(q.whereTime())
+	q.q.WriteString(`AND search_term != ''
+		GROUP BY search_term
+		ORDER BY visitors DESC, search_term ASC `)
+	q.withLimit()
+	stats, err := search.store.SelectSearchTermStats(q.q.String(), q.args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// Exits returns the search terms for which visitors left the site without viewing another page, ordered by the number of exits.
+func (search *SiteSearch) Exits(filter *Filter) ([]model.SearchExitStats, error) {
+	q := search.selectNextPath(filter, `SELECT search_term,
+		uniq(visitor_id) visitors,
+		count(*) searches,
+		countIf(next_path = '') exits,
+		exits / searches exit_rate`, `GROUP BY search_term
+		HAVING exits > 0
+		ORDER BY exits DESC, search_term ASC `)
+	stats, err := search.store.SelectSearchExitStats(q.q.String(), q.args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// Transitions returns the pages viewed right after a search, grouped by search term and path and ordered by the number of visitors.
+func (search *SiteSearch) Transitions(filter *Filter) ([]model.SearchTransitionStats, error) {
+	q := search.selectNextPath(filter, `SELECT search_term,
+		next_path path,
+		uniq(visitor_id) visitors,
+		count(*) views`, `AND next_path != ''
+		GROUP BY search_term, path
+		ORDER BY visitors DESC, search_term ASC, path ASC `)
+	stats, err := search.store.SelectSearchTransitionStats(q.q.String(), q.args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// selectNextPath selects the search terms together with the path of the next page view within the same session.
+// The next path is empty if the search was the last page view of the session.
+func (search *SiteSearch) selectNextPath(filter *Filter, fields, groupBy string) *queryBuilder {
+	filter = search.analyzer.getFilter(filter)
+	q := &queryBuilder{
+		filter: filter,
+		offset: filter.Offset,
+		limit:  filter.Limit,
+	}
+	q.q.WriteString(fmt.Sprintf(`%s
+		FROM (
+			SELECT visitor_id,
+			search_term,
+			leadInFrame(path) OVER (PARTITION BY visitor_id, session_id ORDER BY time ASC ROWS BETWEEN CURRENT ROW AND 1 FOLLOWING) next_path
+			FROM "page_view"
+			%s
+		)
+		WHERE search_term != ''
+		%s`, fields, q.whereTime(), groupBy))
+	q.withLimit()
+	return q
+}
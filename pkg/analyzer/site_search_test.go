@@ -0,0 +1,56 @@
+package analyzer
+
+import (
+	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/model"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSiteSearch(t *testing.T) {
+	db.CleanupDB(t, dbClient)
+	assert.NoError(t, dbClient.SavePageViews([]model.PageView{
+		{VisitorID: 1, SessionID: 1, Time: util.Today(), Path: "/"},
+		{VisitorID: 1, SessionID: 1, Time: util.Today().Add(time.Minute), Path: "/search", SearchTerm: "shoes"},
+		{VisitorID: 1, SessionID: 1, Time: util.Today().Add(time.Minute * 2), Path: "/shoes/red"},
+		{VisitorID: 2, SessionID: 1, Time: util.Today(), Path: "/search", SearchTerm: "shoes"},
+		{VisitorID: 2, SessionID: 1, Time: util.Today().Add(time.Minute), Path: "/shoes/blue"},
+		{VisitorID: 3, SessionID: 1, Time: util.Today(), Path: "/search", SearchTerm: "boots"},
+		{VisitorID: 4, SessionID: 1, Time: util.Today(), Path: "/search", SearchTerm: "shoes"},
+		{VisitorID: 5, SessionID: 1, Time: util.PastDay(2), Path: "/search", SearchTerm: "socks"},
+		{ClientID: 1, VisitorID: 6, SessionID: 1, Time: util.Today(), Path: "/search", SearchTerm: "shoes"},
+	}))
+	time.Sleep(time.Millisecond * 20)
+	analyzer := NewAnalyzer(dbClient)
+	terms, err := analyzer.SiteSearch.Terms(&Filter{From: util.PastDay(1), To: util.Today()})
+	assert.NoError(t, err)
+	assert.Len(t, terms, 2)
+	assert.Equal(t, "shoes", terms[0].SearchTerm)
+	assert.Equal(t, 3, terms[0].Visitors)
+	assert.Equal(t, 3, terms[0].Searches)
+	assert.Equal(t, "boots", terms[1].SearchTerm)
+	assert.Equal(t, 1, terms[1].Visitors)
+	terms, err = analyzer.SiteSearch.Terms(&Filter{From: util.PastDay(2), To: util.Today(), Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, terms, 1)
+	exits, err := analyzer.SiteSearch.Exits(&Filter{From: util.PastDay(1), To: util.Today()})
+	assert.NoError(t, err)
+	assert.Len(t, exits, 2)
+	assert.Equal(t, "boots", exits[0].SearchTerm)
+	assert.Equal(t, 1, exits[0].Exits)
+	assert.InDelta(t, 1, exits[0].ExitRate, 0.001)
+	assert.Equal(t, "shoes", exits[1].SearchTerm)
+	assert.Equal(t, 3, exits[1].Searches)
+	assert.Equal(t, 1, exits[1].Exits)
+	assert.InDelta(t, 0.333, exits[1].ExitRate, 0.001)
+	transitions, err := analyzer.SiteSearch.Transitions(&Filter{From: util.PastDay(1), To: util.Today()})
+	assert.NoError(t, err)
+	assert.Len(t, transitions, 2)
+	assert.Equal(t, "shoes", transitions[0].SearchTerm)
+	assert.Equal(t, "/shoes/blue", transitions[0].Path)
+	assert.Equal(t, 1, transitions[0].Visitors)
+	assert.Equal(t, "shoes", transitions[1].SearchTerm)
+	assert.Equal(t, "/shoes/red", transitions[1].Path)
+}
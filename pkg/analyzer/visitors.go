@@ -84,6 +84,10 @@ func (visitors *Visitors) Total(filter *Filter) (*model.TotalVisitorStats, error
 		return nil, err
 	}
 
+	if err := visitors.mergeImportedTotal(filter, stats); err != nil {
+		return nil, err
+	}
+
 	return stats, nil
 }
 
@@ -160,7 +164,7 @@ func (visitors *Visitors) ByPeriod(filter *Filter) ([]model.VisitorStats, error)
 		return nil, err
 	}
 
-	return stats, nil
+	return visitors.mergeImportedByPeriod(filter, stats)
 }
 
 // ByHour returns the visitor count grouped by time of day.
@@ -314,14 +318,32 @@ func (visitors *Visitors) Referrer(filter *Filter) ([]model.ReferrerStats, error
 		fields = append(fields, FieldAnyReferrer)
 	}
 
-	q, args := filter.buildQuery(fields, groupBy, orderBy)
+	queryFilter := filter
+
+	if _, _, ok := filter.importedRange(); ok {
+		queryFilter = filter.withoutLimit()
+	}
+
+	q, args := queryFilter.buildQuery(fields, groupBy, orderBy)
 	stats, err := visitors.store.SelectReferrerStats(q, args...)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return stats, nil
+	return visitors.mergeImportedReferrer(filter, stats)
+}
+
+// Channels returns the visitor count grouped by marketing channel.
+func (visitors *Visitors) Channels(filter *Filter) ([]model.ChannelStats, error) {
+	q, args := visitors.analyzer.selectByAttribute(filter, FieldChannel)
+	return visitors.store.SelectChannelStats(q, args...)
+}
+
+// ConnectionTypes returns the visitor count grouped by connection type (hosting or residential).
+func (visitors *Visitors) ConnectionTypes(filter *Filter) ([]model.ConnectionTypeStats, error) {
+	q, args := visitors.analyzer.selectByAttribute(filter, FieldConnectionType)
+	return visitors.store.SelectConnectionTypeStats(q, args...)
 }
 
 func (visitors *Visitors) getPreviousPeriod(filter *Filter) {
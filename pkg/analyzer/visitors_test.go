@@ -1417,6 +1417,62 @@ func TestAnalyzer_ReferrerUnknown(t *testing.T) {
 	assert.InDelta(t, 0.5, visitors[0].BounceRate, 0.01)
 }
 
+func TestAnalyzer_Channels(t *testing.T) {
+	db.CleanupDB(t, dbClient)
+	saveSessions(t, [][]model.Session{
+		{
+			{Sign: 1, VisitorID: 1, Time: util.Today(), Start: time.Now(), ExitPath: "/", Channel: "Organic Search"},
+			{Sign: 1, VisitorID: 2, Time: util.Today(), Start: time.Now(), ExitPath: "/", Channel: "Organic Search"},
+			{Sign: 1, VisitorID: 3, Time: util.Today(), Start: time.Now(), ExitPath: "/", Channel: "Social"},
+			{Sign: 1, VisitorID: 4, Time: util.Today(), Start: time.Now(), ExitPath: "/", Channel: "Direct"},
+		},
+	})
+	time.Sleep(time.Millisecond * 20)
+	analyzer := NewAnalyzer(dbClient)
+	channels, err := analyzer.Visitors.Channels(nil)
+	assert.NoError(t, err)
+	assert.Len(t, channels, 3)
+	assert.Equal(t, "Organic Search", channels[0].Channel)
+	assert.Equal(t, 2, channels[0].Visitors)
+	assert.InDelta(t, 0.5, channels[0].RelativeVisitors, 0.01)
+	assert.Equal(t, "Direct", channels[1].Channel)
+	assert.Equal(t, "Social", channels[2].Channel)
+	total, err := analyzer.Visitors.Total(&Filter{Channel: []string{"Social", "Direct"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total.Visitors)
+	total, err = analyzer.Visitors.Total(&Filter{Channel: []string{"!Organic Search"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total.Visitors)
+}
+
+func TestAnalyzer_ConnectionTypes(t *testing.T) {
+	db.CleanupDB(t, dbClient)
+	saveSessions(t, [][]model.Session{
+		{
+			{Sign: 1, VisitorID: 1, Time: util.Today(), Start: time.Now(), ExitPath: "/", ConnectionType: pkg.ConnectionTypeResidential},
+			{Sign: 1, VisitorID: 2, Time: util.Today(), Start: time.Now(), ExitPath: "/", ConnectionType: pkg.ConnectionTypeResidential},
+			{Sign: 1, VisitorID: 3, Time: util.Today(), Start: time.Now(), ExitPath: "/", ConnectionType: pkg.ConnectionTypeHosting},
+			{Sign: 1, VisitorID: 4, Time: util.Today(), Start: time.Now(), ExitPath: "/"},
+		},
+	})
+	time.Sleep(time.Millisecond * 20)
+	analyzer := NewAnalyzer(dbClient)
+	connectionTypes, err := analyzer.Visitors.ConnectionTypes(nil)
+	assert.NoError(t, err)
+	assert.Len(t, connectionTypes, 3)
+	assert.Equal(t, pkg.ConnectionTypeResidential, connectionTypes[0].ConnectionType)
+	assert.Equal(t, 2, connectionTypes[0].Visitors)
+	assert.InDelta(t, 0.5, connectionTypes[0].RelativeVisitors, 0.01)
+	assert.Empty(t, connectionTypes[1].ConnectionType)
+	assert.Equal(t, pkg.ConnectionTypeHosting, connectionTypes[2].ConnectionType)
+	total, err := analyzer.Visitors.Total(&Filter{ConnectionType: []string{pkg.ConnectionTypeHosting}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total.Visitors)
+	total, err = analyzer.Visitors.Total(&Filter{ConnectionType: []string{"!" + pkg.ConnectionTypeHosting}})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total.Visitors)
+}
+
 func TestAnalyzer_Timezone(t *testing.T) {
 	db.CleanupDB(t, dbClient)
 	assert.NoError(t, dbClient.SaveSessions([]model.Session{
@@ -49,6 +49,12 @@ const (
 	// PlatformUnknown filters for everything where the platform is unspecified.
 	PlatformUnknown = "unknown"
 
+	// ConnectionTypeHosting is the connection type for visitors from hosting providers and data centers, like AWS or Hetzner.
+	ConnectionTypeHosting = "hosting"
+
+	// ConnectionTypeResidential is the connection type for visitors from all other networks, like ISPs and mobile carriers.
+	ConnectionTypeResidential = "residential"
+
 	// Unknown filters for an unknown (empty) value.
 	// This is a synonym for "null".
 	Unknown = "null"
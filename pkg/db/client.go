@@ -149,9 +149,9 @@ func (client *Client) SavePageViews(pageViews []model.PageView) error {
 	}
 
 	query, err := tx.Prepare(`INSERT INTO "page_view" (client_id, visitor_id, session_id, time, duration_seconds,
-		path, title, language, country_code, city, referrer, referrer_name, referrer_icon, os, os_version,
+		hostname, path, title, language, country_code, region, city, referrer, referrer_name, referrer_icon, channel, ad_network, connection_type, os, os_version,
 		browser, browser_version, desktop, mobile, screen_class,
-		utm_source, utm_medium, utm_campaign, utm_content, utm_term) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`)
+		utm_source, utm_medium, utm_campaign, utm_content, utm_term, query_param_keys, query_param_values, search_term, status_code) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`)
 
 	if err != nil {
 		return err
@@ -163,14 +163,19 @@ func (client *Client) SavePageViews(pageViews []model.PageView) error {
 			pageView.SessionID,
 			pageView.Time,
 			pageView.DurationSeconds,
+			pageView.Hostname,
 			pageView.Path,
 			pageView.Title,
 			pageView.Language,
 			pageView.CountryCode,
+			pageView.Region,
 			pageView.City,
 			pageView.Referrer,
 			pageView.ReferrerName,
 			pageView.ReferrerIcon,
+			pageView.Channel,
+			pageView.AdNetwork,
+			pageView.ConnectionType,
 			pageView.OS,
 			pageView.OSVersion,
 			pageView.Browser,
@@ -182,7 +187,11 @@ func (client *Client) SavePageViews(pageViews []model.PageView) error {
 			pageView.UTMMedium,
 			pageView.UTMCampaign,
 			pageView.UTMContent,
-			pageView.UTMTerm)
+			pageView.UTMTerm,
+			pageView.QueryParamKeys,
+			pageView.QueryParamValues,
+			pageView.SearchTerm,
+			pageView.StatusCode)
 
 		if err != nil {
 			if e := tx.Rollback(); e != nil {
@@ -213,10 +222,10 @@ func (client *Client) SaveSessions(sessions []model.Session) error {
 	}
 
 	query, err := tx.Prepare(`INSERT INTO "session" (sign, client_id, visitor_id, session_id, time, start, duration_seconds,
-		entry_path, exit_path, page_views, is_bounce, entry_title, exit_title, language, country_code, city, referrer, referrer_name, referrer_icon, os, os_version,
+		hostname, entry_path, exit_path, page_views, is_bounce, entry_title, exit_title, language, country_code, region, city, referrer, referrer_name, referrer_icon, channel, ad_network, connection_type, os, os_version,
 		browser, browser_version, desktop, mobile, screen_class,
 		utm_source, utm_medium, utm_campaign, utm_content, utm_term, extended)
-		VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`)
+		VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`)
 
 	if err != nil {
 		return err
@@ -230,6 +239,7 @@ func (client *Client) SaveSessions(sessions []model.Session) error {
 			session.Time,
 			session.Start,
 			session.DurationSeconds,
+			session.Hostname,
 			session.EntryPath,
 			session.ExitPath,
 			session.PageViews,
@@ -238,10 +248,14 @@ func (client *Client) SaveSessions(sessions []model.Session) error {
 			session.ExitTitle,
 			session.Language,
 			session.CountryCode,
+			session.Region,
 			session.City,
 			session.Referrer,
 			session.ReferrerName,
 			session.ReferrerIcon,
+			session.Channel,
+			session.AdNetwork,
+			session.ConnectionType,
 			session.OS,
 			session.OSVersion,
 			session.Browser,
@@ -285,9 +299,9 @@ func (client *Client) SaveEvents(events []model.Event) error {
 	}
 
 	query, err := tx.Prepare(`INSERT INTO "event" (client_id, visitor_id, time, session_id, event_name, event_meta_keys, event_meta_values, duration_seconds,
-		path, title, language, country_code, city, referrer, referrer_name, referrer_icon, os, os_version,
+		hostname, path, title, language, country_code, region, city, referrer, referrer_name, referrer_icon, channel, ad_network, connection_type, os, os_version,
 		browser, browser_version, desktop, mobile, screen_class,
-		utm_source, utm_medium, utm_campaign, utm_content, utm_term) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`)
+		utm_source, utm_medium, utm_campaign, utm_content, utm_term, query_param_keys, query_param_values) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`)
 
 	if err != nil {
 		return err
@@ -302,14 +316,19 @@ func (client *Client) SaveEvents(events []model.Event) error {
 			event.MetaKeys,
 			event.MetaValues,
 			event.DurationSeconds,
+			event.Hostname,
 			event.Path,
 			event.Title,
 			event.Language,
 			event.CountryCode,
+			event.Region,
 			event.City,
 			event.Referrer,
 			event.ReferrerName,
 			event.ReferrerIcon,
+			event.Channel,
+			event.AdNetwork,
+			event.ConnectionType,
 			event.OS,
 			event.OSVersion,
 			event.Browser,
@@ -321,7 +340,9 @@ func (client *Client) SaveEvents(events []model.Event) error {
 			event.UTMMedium,
 			event.UTMCampaign,
 			event.UTMContent,
-			event.UTMTerm)
+			event.UTMTerm,
+			event.QueryParamKeys,
+			event.QueryParamValues)
 
 		if err != nil {
 			if e := tx.Rollback(); e != nil {
@@ -387,14 +408,14 @@ func (client *Client) SaveBots(bots []model.Bot) error {
 		return err
 	}
 
-	query, err := tx.Prepare(`INSERT INTO "bot" (client_id, visitor_id, time, user_agent, path, event_name) VALUES (?,?,?,?,?,?)`)
+	query, err := tx.Prepare(`INSERT INTO "bot" (client_id, visitor_id, time, user_agent, path, event_name, reason) VALUES (?,?,?,?,?,?,?)`)
 
 	if err != nil {
 		return err
 	}
 
 	for _, bot := range bots {
-		_, err := query.Exec(bot.ClientID, bot.VisitorID, bot.Time, bot.UserAgent, bot.Path, bot.Event)
+		_, err := query.Exec(bot.ClientID, bot.VisitorID, bot.Time, bot.UserAgent, bot.Path, bot.Event, bot.Reason)
 
 		if err != nil {
 			if e := tx.Rollback(); e != nil {
@@ -416,6 +437,85 @@ func (client *Client) SaveBots(bots []model.Bot) error {
 	return nil
 }
 
+// SaveImportedVisitors implements the Store interface.
+func (client *Client) SaveImportedVisitors(visitors []model.ImportedVisitors) error {
+	return client.saveImported("visitors", `INSERT INTO "imported_visitors" (client_id, date, visitors, views, sessions, bounces, session_duration) VALUES (?,?,?,?,?,?,?)`, len(visitors), func(query *sql.Stmt, i int) error {
+		v := visitors[i]
+		_, err := query.Exec(v.ClientID, v.Date, v.Visitors, v.Views, v.Sessions, v.Bounces, v.SessionDuration)
+		return err
+	})
+}
+
+// SaveImportedPages implements the Store interface.
+func (client *Client) SaveImportedPages(pages []model.ImportedPage) error {
+	return client.saveImported("pages", `INSERT INTO "imported_page" (client_id, date, path, visitors, views, sessions, bounces) VALUES (?,?,?,?,?,?,?)`, len(pages), func(query *sql.Stmt, i int) error {
+		p := pages[i]
+		_, err := query.Exec(p.ClientID, p.Date, p.Path, p.Visitors, p.Views, p.Sessions, p.Bounces)
+		return err
+	})
+}
+
+// SaveImportedReferrers implements the Store interface.
+func (client *Client) SaveImportedReferrers(referrers []model.ImportedReferrer) error {
+	return client.saveImported("referrers", `INSERT INTO "imported_referrer" (client_id, date, referrer, visitors, sessions, bounces) VALUES (?,?,?,?,?,?)`, len(referrers), func(query *sql.Stmt, i int) error {
+		r := referrers[i]
+		_, err := query.Exec(r.ClientID, r.Date, r.Referrer, r.Visitors, r.Sessions, r.Bounces)
+		return err
+	})
+}
+
+// SaveImportedCountries implements the Store interface.
+func (client *Client) SaveImportedCountries(countries []model.ImportedCountry) error {
+	return client.saveImported("countries", `INSERT INTO "imported_country" (client_id, date, country_code, visitors) VALUES (?,?,?,?)`, len(countries), func(query *sql.Stmt, i int) error {
+		c := countries[i]
+		_, err := query.Exec(c.ClientID, c.Date, c.CountryCode, c.Visitors)
+		return err
+	})
+}
+
+// SaveImportedDevices implements the Store interface.
+func (client *Client) SaveImportedDevices(devices []model.ImportedDevice) error {
+	return client.saveImported("devices", `INSERT INTO "imported_device" (client_id, date, device, visitors) VALUES (?,?,?,?)`, len(devices), func(query *sql.Stmt, i int) error {
+		d := devices[i]
+		_, err := query.Exec(d.ClientID, d.Date, d.Device, d.Visitors)
+		return err
+	})
+}
+
+func (client *Client) saveImported(name, insert string, n int, exec func(*sql.Stmt, int) error) error {
+	tx, err := client.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	query, err := tx.Prepare(insert)
+
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < n; i++ {
+		if err := exec(query, i); err != nil {
+			if e := tx.Rollback(); e != nil {
+				client.logger.Error("error rolling back transaction to save imported "+name, "err", err)
+			}
+
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if client.debug {
+		client.logger.Debug("saved imported "+name, "count", n)
+	}
+
+	return nil
+}
+
 // Session implements the Store interface.
 func (client *Client) Session(clientID, fingerprint uint64, maxAge time.Time) (*model.Session, error) {
 	query := `SELECT sign,
@@ -425,6 +525,7 @@ func (client *Client) Session(clientID, fingerprint uint64, maxAge time.Time) (*
 		time,
 		start,
 		duration_seconds,
+		hostname,
 		entry_path,
 		exit_path,
 		page_views,
@@ -433,10 +534,14 @@ func (client *Client) Session(clientID, fingerprint uint64, maxAge time.Time) (*
 		exit_title,
 		language,
 		country_code,
+		region,
 		city,
 		referrer,
 		referrer_name,
 		referrer_icon,
+		channel,
+		ad_network,
+		connection_type,
 		os,
 		os_version,
 		browser,
@@ -464,6 +569,7 @@ func (client *Client) Session(clientID, fingerprint uint64, maxAge time.Time) (*
 		&session.Time,
 		&session.Start,
 		&session.DurationSeconds,
+		&session.Hostname,
 		&session.EntryPath,
 		&session.ExitPath,
 		&session.PageViews,
@@ -472,10 +578,14 @@ func (client *Client) Session(clientID, fingerprint uint64, maxAge time.Time) (*
 		&session.ExitTitle,
 		&session.Language,
 		&session.CountryCode,
+		&session.Region,
 		&session.City,
 		&session.Referrer,
 		&session.ReferrerName,
 		&session.ReferrerIcon,
+		&session.Channel,
+		&session.AdNetwork,
+		&session.ConnectionType,
 		&session.OS,
 		&session.OSVersion,
 		&session.Browser,
@@ -1341,6 +1451,186 @@ func (client *Client) SelectEventListStats(query string, args ...any) ([]model.E
 	return results, nil
 }
 
+// SelectBotStats implements the Store interface.
+func (client *Client) SelectBotStats(period pkg.Period, query string, args ...any) ([]model.BotStats, error) {
+	rows, err := client.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.closeRows(rows)
+	var results []model.BotStats
+
+	for rows.Next() {
+		var result model.BotStats
+		var err error
+
+		switch period {
+		case pkg.PeriodWeek:
+			err = rows.Scan(&result.Week, &result.Visitors, &result.Hits)
+		case pkg.PeriodMonth:
+			err = rows.Scan(&result.Month, &result.Visitors, &result.Hits)
+		case pkg.PeriodYear:
+			err = rows.Scan(&result.Year, &result.Visitors, &result.Hits)
+		default:
+			err = rows.Scan(&result.Day, &result.Visitors, &result.Hits)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// SelectBotUserAgentStats implements the Store interface.
+func (client *Client) SelectBotUserAgentStats(query string, args ...any) ([]model.BotUserAgentStats, error) {
+	rows, err := client.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.closeRows(rows)
+	var results []model.BotUserAgentStats
+
+	for rows.Next() {
+		var result model.BotUserAgentStats
+
+		if err := rows.Scan(&result.UserAgent, &result.Visitors, &result.Hits); err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// SelectBotPathStats implements the Store interface.
+func (client *Client) SelectBotPathStats(query string, args ...any) ([]model.BotPathStats, error) {
+	rows, err := client.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.closeRows(rows)
+	var results []model.BotPathStats
+
+	for rows.Next() {
+		var result model.BotPathStats
+
+		if err := rows.Scan(&result.Path, &result.Visitors, &result.Hits); err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// SelectBotEventStats implements the Store interface.
+func (client *Client) SelectBotEventStats(query string, args ...any) ([]model.BotEventStats, error) {
+	rows, err := client.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.closeRows(rows)
+	var results []model.BotEventStats
+
+	for rows.Next() {
+		var result model.BotEventStats
+
+		if err := rows.Scan(&result.Name, &result.Visitors, &result.Hits); err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// SelectSearchTermStats implements the Store interface.
+func (client *Client) SelectSearchTermStats(query string, args ...any) ([]model.SearchTermStats, error) {
+	rows, err := client.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.closeRows(rows)
+	var results []model.SearchTermStats
+
+	for rows.Next() {
+		var result model.SearchTermStats
+
+		if err := rows.Scan(&result.SearchTerm, &result.Visitors, &result.Searches); err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// SelectSearchExitStats implements the Store interface.
+func (client *Client) SelectSearchExitStats(query string, args ...any) ([]model.SearchExitStats, error) {
+	rows, err := client.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.closeRows(rows)
+	var results []model.SearchExitStats
+
+	for rows.Next() {
+		var result model.SearchExitStats
+
+		if err := rows.Scan(&result.SearchTerm, &result.Visitors, &result.Searches, &result.Exits, &result.ExitRate); err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// SelectSearchTransitionStats implements the Store interface.
+func (client *Client) SelectSearchTransitionStats(query string, args ...any) ([]model.SearchTransitionStats, error) {
+	rows, err := client.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.closeRows(rows)
+	var results []model.SearchTransitionStats
+
+	for rows.Next() {
+		var result model.SearchTransitionStats
+
+		if err := rows.Scan(&result.SearchTerm, &result.Path, &result.Visitors, &result.Views); err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
 // SelectReferrerStats implements the Store interface.
 func (client *Client) SelectReferrerStats(query string, args ...any) ([]model.ReferrerStats, error) {
 	rows, err := client.Query(query, args...)
@@ -1436,6 +1726,102 @@ func (client *Client) SelectCountryStats(query string, args ...any) ([]model.Cou
 	return results, nil
 }
 
+// SelectContinentStats implements the Store interface.
+func (client *Client) SelectContinentStats(query string, args ...any) ([]model.ContinentStats, error) {
+	rows, err := client.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.closeRows(rows)
+	var results []model.ContinentStats
+
+	for rows.Next() {
+		var result model.ContinentStats
+
+		if err := rows.Scan(&result.Continent, &result.Visitors, &result.RelativeVisitors); err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// SelectSubregionStats implements the Store interface.
+func (client *Client) SelectSubregionStats(query string, args ...any) ([]model.SubregionStats, error) {
+	rows, err := client.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.closeRows(rows)
+	var results []model.SubregionStats
+
+	for rows.Next() {
+		var result model.SubregionStats
+
+		if err := rows.Scan(&result.Subregion, &result.Visitors, &result.RelativeVisitors); err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// SelectEconomicRegionStats implements the Store interface.
+func (client *Client) SelectEconomicRegionStats(query string, args ...any) ([]model.EconomicRegionStats, error) {
+	rows, err := client.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.closeRows(rows)
+	var results []model.EconomicRegionStats
+
+	for rows.Next() {
+		var result model.EconomicRegionStats
+
+		if err := rows.Scan(&result.EconomicRegion, &result.Visitors, &result.RelativeVisitors); err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// SelectRegionStats implements the Store interface.
+func (client *Client) SelectRegionStats(query string, args ...any) ([]model.RegionStats, error) {
+	rows, err := client.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.closeRows(rows)
+	var results []model.RegionStats
+
+	for rows.Next() {
+		var result model.RegionStats
+
+		if err := rows.Scan(&result.Region, &result.CountryCode, &result.Visitors, &result.RelativeVisitors); err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
 // SelectCityStats implements the Store interface.
 func (client *Client) SelectCityStats(query string, args ...any) ([]model.CityStats, error) {
 	rows, err := client.Query(query, args...)
@@ -1450,7 +1836,7 @@ func (client *Client) SelectCityStats(query string, args ...any) ([]model.CitySt
 	for rows.Next() {
 		var result model.CityStats
 
-		if err := rows.Scan(&result.City, &result.CountryCode, &result.Visitors, &result.RelativeVisitors); err != nil {
+		if err := rows.Scan(&result.City, &result.CountryCode, &result.Region, &result.Visitors, &result.RelativeVisitors); err != nil {
 			return nil, err
 		}
 
@@ -1532,6 +1918,102 @@ func (client *Client) SelectScreenClassStats(query string, args ...any) ([]model
 	return results, nil
 }
 
+// SelectHostnameStats implements the Store interface.
+func (client *Client) SelectHostnameStats(query string, args ...any) ([]model.HostnameStats, error) {
+	rows, err := client.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.closeRows(rows)
+	var results []model.HostnameStats
+
+	for rows.Next() {
+		var result model.HostnameStats
+
+		if err := rows.Scan(&result.Hostname, &result.Visitors, &result.RelativeVisitors); err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// SelectChannelStats implements the Store interface.
+func (client *Client) SelectChannelStats(query string, args ...any) ([]model.ChannelStats, error) {
+	rows, err := client.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.closeRows(rows)
+	var results []model.ChannelStats
+
+	for rows.Next() {
+		var result model.ChannelStats
+
+		if err := rows.Scan(&result.Channel, &result.Visitors, &result.RelativeVisitors); err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// SelectConnectionTypeStats implements the Store interface.
+func (client *Client) SelectConnectionTypeStats(query string, args ...any) ([]model.ConnectionTypeStats, error) {
+	rows, err := client.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.closeRows(rows)
+	var results []model.ConnectionTypeStats
+
+	for rows.Next() {
+		var result model.ConnectionTypeStats
+
+		if err := rows.Scan(&result.ConnectionType, &result.Visitors, &result.RelativeVisitors); err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// SelectQueryParamStats implements the Store interface.
+func (client *Client) SelectQueryParamStats(query string, args ...any) ([]model.QueryParamStats, error) {
+	rows, err := client.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.closeRows(rows)
+	var results []model.QueryParamStats
+
+	for rows.Next() {
+		var result model.QueryParamStats
+
+		if err := rows.Scan(&result.Value, &result.Visitors, &result.RelativeVisitors); err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
 // SelectUTMSourceStats implements the Store interface.
 func (client *Client) SelectUTMSourceStats(query string, args ...any) ([]model.UTMSourceStats, error) {
 	rows, err := client.Query(query, args...)
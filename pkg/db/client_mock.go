@@ -15,7 +15,9 @@ type ClientMock struct {
 	events        []model.Event
 	userAgents    []model.UserAgent
 	bots          []model.Bot
+	imported      Imported
 	ReturnSession *model.Session
+	saveErr       error
 	m             sync.Mutex
 }
 
@@ -93,6 +95,28 @@ func (client *ClientMock) GetUserAgents() []model.UserAgent {
 	return data
 }
 
+// Imported is the imported statistics saved to the ClientMock.
+type Imported struct {
+	Visitors  []model.ImportedVisitors
+	Pages     []model.ImportedPage
+	Referrers []model.ImportedReferrer
+	Countries []model.ImportedCountry
+	Devices   []model.ImportedDevice
+}
+
+// GetImported returns a copy of the imported statistics.
+func (client *ClientMock) GetImported() Imported {
+	client.m.Lock()
+	defer client.m.Unlock()
+	return Imported{
+		Visitors:  append([]model.ImportedVisitors(nil), client.imported.Visitors...),
+		Pages:     append([]model.ImportedPage(nil), client.imported.Pages...),
+		Referrers: append([]model.ImportedReferrer(nil), client.imported.Referrers...),
+		Countries: append([]model.ImportedCountry(nil), client.imported.Countries...),
+		Devices:   append([]model.ImportedDevice(nil), client.imported.Devices...),
+	}
+}
+
 // GetBots returns a copy of the bots slice.
 func (client *ClientMock) GetBots() []model.Bot {
 	client.m.Lock()
@@ -109,10 +133,22 @@ func (client *ClientMock) GetBots() []model.Bot {
 	return data
 }
 
+// SetSaveError sets the error returned by all Save* methods. Set it to nil to accept writes again.
+func (client *ClientMock) SetSaveError(err error) {
+	client.m.Lock()
+	defer client.m.Unlock()
+	client.saveErr = err
+}
+
 // SavePageViews implements the Store interface.
 func (client *ClientMock) SavePageViews(pageViews []model.PageView) error {
 	client.m.Lock()
 	defer client.m.Unlock()
+
+	if client.saveErr != nil {
+		return client.saveErr
+	}
+
 	client.pageViews = append(client.pageViews, pageViews...)
 	return nil
 }
@@ -121,6 +157,11 @@ func (client *ClientMock) SavePageViews(pageViews []model.PageView) error {
 func (client *ClientMock) SaveSessions(sessions []model.Session) error {
 	client.m.Lock()
 	defer client.m.Unlock()
+
+	if client.saveErr != nil {
+		return client.saveErr
+	}
+
 	client.sessions = append(client.sessions, sessions...)
 	return nil
 }
@@ -129,6 +170,11 @@ func (client *ClientMock) SaveSessions(sessions []model.Session) error {
 func (client *ClientMock) SaveEvents(events []model.Event) error {
 	client.m.Lock()
 	defer client.m.Unlock()
+
+	if client.saveErr != nil {
+		return client.saveErr
+	}
+
 	client.events = append(client.events, events...)
 	return nil
 }
@@ -137,6 +183,11 @@ func (client *ClientMock) SaveEvents(events []model.Event) error {
 func (client *ClientMock) SaveUserAgents(userAgents []model.UserAgent) error {
 	client.m.Lock()
 	defer client.m.Unlock()
+
+	if client.saveErr != nil {
+		return client.saveErr
+	}
+
 	client.userAgents = append(client.userAgents, userAgents...)
 	return nil
 }
@@ -144,10 +195,80 @@ func (client *ClientMock) SaveUserAgents(userAgents []model.UserAgent) error {
 func (client *ClientMock) SaveBots(bots []model.Bot) error {
 	client.m.Lock()
 	defer client.m.Unlock()
+
+	if client.saveErr != nil {
+		return client.saveErr
+	}
+
 	client.bots = append(client.bots, bots...)
 	return nil
 }
 
+// SaveImportedVisitors implements the Store interface.
+func (client *ClientMock) SaveImportedVisitors(visitors []model.ImportedVisitors) error {
+	client.m.Lock()
+	defer client.m.Unlock()
+
+	if client.saveErr != nil {
+		return client.saveErr
+	}
+
+	client.imported.Visitors = append(client.imported.Visitors, visitors...)
+	return nil
+}
+
+// SaveImportedPages implements the Store interface.
+func (client *ClientMock) SaveImportedPages(pages []model.ImportedPage) error {
+	client.m.Lock()
+	defer client.m.Unlock()
+
+	if client.saveErr != nil {
+		return client.saveErr
+	}
+
+	client.imported.Pages = append(client.imported.Pages, pages...)
+	return nil
+}
+
+// SaveImportedReferrers implements the Store interface.
+func (client *ClientMock) SaveImportedReferrers(referrers []model.ImportedReferrer) error {
+	client.m.Lock()
+	defer client.m.Unlock()
+
+	if client.saveErr != nil {
+		return client.saveErr
+	}
+
+	client.imported.Referrers = append(client.imported.Referrers, referrers...)
+	return nil
+}
+
+// SaveImportedCountries implements the Store interface.
+func (client *ClientMock) SaveImportedCountries(countries []model.ImportedCountry) error {
+	client.m.Lock()
+	defer client.m.Unlock()
+
+	if client.saveErr != nil {
+		return client.saveErr
+	}
+
+	client.imported.Countries = append(client.imported.Countries, countries...)
+	return nil
+}
+
+// SaveImportedDevices implements the Store interface.
+func (client *ClientMock) SaveImportedDevices(devices []model.ImportedDevice) error {
+	client.m.Lock()
+	defer client.m.Unlock()
+
+	if client.saveErr != nil {
+		return client.saveErr
+	}
+
+	client.imported.Devices = append(client.imported.Devices, devices...)
+	return nil
+}
+
 // Session implements the Store interface.
 func (client *ClientMock) Session(uint64, uint64, time.Time) (*model.Session, error) {
 	if client.ReturnSession != nil {
@@ -242,6 +363,41 @@ func (client *ClientMock) SelectEventListStats(string, ...any) ([]model.EventLis
 	return nil, nil
 }
 
+// SelectBotStats implements the Store interface.
+func (client *ClientMock) SelectBotStats(pkg.Period, string, ...any) ([]model.BotStats, error) {
+	return nil, nil
+}
+
+// SelectBotUserAgentStats implements the Store interface.
+func (client *ClientMock) SelectBotUserAgentStats(string, ...any) ([]model.BotUserAgentStats, error) {
+	return nil, nil
+}
+
+// SelectBotPathStats implements the Store interface.
+func (client *ClientMock) SelectBotPathStats(string, ...any) ([]model.BotPathStats, error) {
+	return nil, nil
+}
+
+// SelectBotEventStats implements the Store interface.
+func (client *ClientMock) SelectBotEventStats(string, ...any) ([]model.BotEventStats, error) {
+	return nil, nil
+}
+
+// SelectSearchTermStats implements the Store interface.
+func (client *ClientMock) SelectSearchTermStats(string, ...any) ([]model.SearchTermStats, error) {
+	return nil, nil
+}
+
+// SelectSearchExitStats implements the Store interface.
+func (client *ClientMock) SelectSearchExitStats(string, ...any) ([]model.SearchExitStats, error) {
+	return nil, nil
+}
+
+// SelectSearchTransitionStats implements the Store interface.
+func (client *ClientMock) SelectSearchTransitionStats(string, ...any) ([]model.SearchTransitionStats, error) {
+	return nil, nil
+}
+
 // SelectReferrerStats implements the Store interface.
 func (client *ClientMock) SelectReferrerStats(string, ...any) ([]model.ReferrerStats, error) {
 	return nil, nil
@@ -262,6 +418,26 @@ func (client *ClientMock) SelectCountryStats(string, ...any) ([]model.CountrySta
 	return nil, nil
 }
 
+// SelectContinentStats implements the Store interface.
+func (client *ClientMock) SelectContinentStats(string, ...any) ([]model.ContinentStats, error) {
+	return nil, nil
+}
+
+// SelectSubregionStats implements the Store interface.
+func (client *ClientMock) SelectSubregionStats(string, ...any) ([]model.SubregionStats, error) {
+	return nil, nil
+}
+
+// SelectEconomicRegionStats implements the Store interface.
+func (client *ClientMock) SelectEconomicRegionStats(string, ...any) ([]model.EconomicRegionStats, error) {
+	return nil, nil
+}
+
+// SelectRegionStats implements the Store interface.
+func (client *ClientMock) SelectRegionStats(string, ...any) ([]model.RegionStats, error) {
+	return nil, nil
+}
+
 // SelectCityStats implements the Store interface.
 func (client *ClientMock) SelectCityStats(string, ...any) ([]model.CityStats, error) {
 	return nil, nil
@@ -282,6 +458,26 @@ func (client *ClientMock) SelectScreenClassStats(string, ...any) ([]model.Screen
 	return nil, nil
 }
 
+// SelectHostnameStats implements the Store interface.
+func (client *ClientMock) SelectHostnameStats(string, ...any) ([]model.HostnameStats, error) {
+	return nil, nil
+}
+
+// SelectChannelStats implements the Store interface.
+func (client *ClientMock) SelectChannelStats(string, ...any) ([]model.ChannelStats, error) {
+	return nil, nil
+}
+
+// SelectConnectionTypeStats implements the Store interface.
+func (client *ClientMock) SelectConnectionTypeStats(string, ...any) ([]model.ConnectionTypeStats, error) {
+	return nil, nil
+}
+
+// SelectQueryParamStats implements the Store interface.
+func (client *ClientMock) SelectQueryParamStats(string, ...any) ([]model.QueryParamStats, error) {
+	return nil, nil
+}
+
 // SelectUTMSourceStats implements the Store interface.
 func (client *ClientMock) SelectUTMSourceStats(string, ...any) ([]model.UTMSourceStats, error) {
 	return nil, nil
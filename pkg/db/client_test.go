@@ -46,6 +46,7 @@ func TestClient_SavePageViews(t *testing.T) {
 			Desktop:         true,
 			Mobile:          false,
 			ScreenClass:     "XL",
+			StatusCode:      200,
 		},
 		{
 			VisitorID: 1,
@@ -205,6 +206,7 @@ func TestClient_SaveBots(t *testing.T) {
 			UserAgent: "ua1",
 			Path:      "/foo",
 			Event:     "event",
+			Reason:    "dnt",
 		},
 		{
 			ClientID:  2,
@@ -212,6 +214,7 @@ func TestClient_SaveBots(t *testing.T) {
 			Time:      time.Now(),
 			UserAgent: "ua2",
 			Path:      "/bar",
+			Reason:    "user_agent_blacklist",
 		},
 	}))
 }
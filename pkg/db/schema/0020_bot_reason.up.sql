@@ -0,0 +1 @@
+ALTER TABLE "bot" ADD COLUMN "reason" LowCardinality(String) DEFAULT '';
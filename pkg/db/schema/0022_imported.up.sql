@@ -0,0 +1,52 @@
+CREATE TABLE "imported_visitors" (
+    `client_id` UInt64,
+    `date` Date,
+    `visitors` UInt32,
+    `views` UInt32,
+    `sessions` UInt32,
+    `bounces` UInt32,
+    `session_duration` UInt64
+) ENGINE = ReplacingMergeTree()
+PARTITION BY toYYYYMM(date)
+ORDER BY (client_id, date);
+
+CREATE TABLE "imported_page" (
+    `client_id` UInt64,
+    `date` Date,
+    `path` String,
+    `visitors` UInt32,
+    `views` UInt32,
+    `sessions` UInt32,
+    `bounces` UInt32
+) ENGINE = ReplacingMergeTree()
+PARTITION BY toYYYYMM(date)
+ORDER BY (client_id, date, path);
+
+CREATE TABLE "imported_referrer" (
+    `client_id` UInt64,
+    `date` Date,
+    `referrer` String,
+    `visitors` UInt32,
+    `sessions` UInt32,
+    `bounces` UInt32
+) ENGINE = ReplacingMergeTree()
+PARTITION BY toYYYYMM(date)
+ORDER BY (client_id, date, referrer);
+
+CREATE TABLE "imported_country" (
+    `client_id` UInt64,
+    `date` Date,
+    `country_code` LowCardinality(FixedString(2)),
+    `visitors` UInt32
+) ENGINE = ReplacingMergeTree()
+PARTITION BY toYYYYMM(date)
+ORDER BY (client_id, date, country_code);
+
+CREATE TABLE "imported_device" (
+    `client_id` UInt64,
+    `date` Date,
+    `device` LowCardinality(String),
+    `visitors` UInt32
+) ENGINE = ReplacingMergeTree()
+PARTITION BY toYYYYMM(date)
+ORDER BY (client_id, date, device);
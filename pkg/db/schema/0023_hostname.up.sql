@@ -0,0 +1,3 @@
+ALTER TABLE "session" ADD COLUMN "hostname" LowCardinality(String) DEFAULT '';
+ALTER TABLE "page_view" ADD COLUMN "hostname" LowCardinality(String) DEFAULT '';
+ALTER TABLE "event" ADD COLUMN "hostname" LowCardinality(String) DEFAULT '';
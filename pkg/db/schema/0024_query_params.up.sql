@@ -0,0 +1,4 @@
+ALTER TABLE "page_view" ADD COLUMN "query_param_keys" Array(String);
+ALTER TABLE "page_view" ADD COLUMN "query_param_values" Array(String);
+ALTER TABLE "event" ADD COLUMN "query_param_keys" Array(String);
+ALTER TABLE "event" ADD COLUMN "query_param_values" Array(String);
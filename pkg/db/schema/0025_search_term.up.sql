@@ -0,0 +1 @@
+ALTER TABLE "page_view" ADD COLUMN "search_term" String DEFAULT '';
@@ -0,0 +1,3 @@
+ALTER TABLE "session" ADD COLUMN "ad_network" LowCardinality(String) DEFAULT '';
+ALTER TABLE "page_view" ADD COLUMN "ad_network" LowCardinality(String) DEFAULT '';
+ALTER TABLE "event" ADD COLUMN "ad_network" LowCardinality(String) DEFAULT '';
@@ -0,0 +1,3 @@
+ALTER TABLE "session" ADD COLUMN "region" LowCardinality(String) DEFAULT '';
+ALTER TABLE "page_view" ADD COLUMN "region" LowCardinality(String) DEFAULT '';
+ALTER TABLE "event" ADD COLUMN "region" LowCardinality(String) DEFAULT '';
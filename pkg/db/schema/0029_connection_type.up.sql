@@ -0,0 +1,3 @@
+ALTER TABLE "session" ADD COLUMN "connection_type" LowCardinality(String) DEFAULT '';
+ALTER TABLE "page_view" ADD COLUMN "connection_type" LowCardinality(String) DEFAULT '';
+ALTER TABLE "event" ADD COLUMN "connection_type" LowCardinality(String) DEFAULT '';
@@ -23,6 +23,21 @@ type Store interface {
 	// SaveBots saves given bots.
 	SaveBots([]model.Bot) error
 
+	// SaveImportedVisitors saves given imported visitor statistics.
+	SaveImportedVisitors([]model.ImportedVisitors) error
+
+	// SaveImportedPages saves given imported page statistics.
+	SaveImportedPages([]model.ImportedPage) error
+
+	// SaveImportedReferrers saves given imported referrer statistics.
+	SaveImportedReferrers([]model.ImportedReferrer) error
+
+	// SaveImportedCountries saves given imported country statistics.
+	SaveImportedCountries([]model.ImportedCountry) error
+
+	// SaveImportedDevices saves given imported device statistics.
+	SaveImportedDevices([]model.ImportedDevice) error
+
 	// Session returns the last hit for given client, fingerprint, and maximum age.
 	Session(uint64, uint64, time.Time) (*model.Session, error)
 
@@ -77,6 +92,27 @@ type Store interface {
 	// SelectEventListStats selects EventListStats.
 	SelectEventListStats(string, ...any) ([]model.EventListStats, error)
 
+	// SelectBotStats selects BotStats.
+	SelectBotStats(pkg.Period, string, ...any) ([]model.BotStats, error)
+
+	// SelectBotUserAgentStats selects BotUserAgentStats.
+	SelectBotUserAgentStats(string, ...any) ([]model.BotUserAgentStats, error)
+
+	// SelectBotPathStats selects BotPathStats.
+	SelectBotPathStats(string, ...any) ([]model.BotPathStats, error)
+
+	// SelectBotEventStats selects BotEventStats.
+	SelectBotEventStats(string, ...any) ([]model.BotEventStats, error)
+
+	// SelectSearchTermStats selects SearchTermStats.
+	SelectSearchTermStats(string, ...any) ([]model.SearchTermStats, error)
+
+	// SelectSearchExitStats selects SearchExitStats.
+	SelectSearchExitStats(string, ...any) ([]model.SearchExitStats, error)
+
+	// SelectSearchTransitionStats selects SearchTransitionStats.
+	SelectSearchTransitionStats(string, ...any) ([]model.SearchTransitionStats, error)
+
 	// SelectReferrerStats selects ReferrerStats.
 	SelectReferrerStats(string, ...any) ([]model.ReferrerStats, error)
 
@@ -89,6 +125,18 @@ type Store interface {
 	// SelectCountryStats selects CountryStats.
 	SelectCountryStats(string, ...any) ([]model.CountryStats, error)
 
+	// SelectContinentStats selects ContinentStats.
+	SelectContinentStats(string, ...any) ([]model.ContinentStats, error)
+
+	// SelectSubregionStats selects SubregionStats.
+	SelectSubregionStats(string, ...any) ([]model.SubregionStats, error)
+
+	// SelectEconomicRegionStats selects EconomicRegionStats.
+	SelectEconomicRegionStats(string, ...any) ([]model.EconomicRegionStats, error)
+
+	// SelectRegionStats selects RegionStats.
+	SelectRegionStats(string, ...any) ([]model.RegionStats, error)
+
 	// SelectCityStats selects CityStats.
 	SelectCityStats(string, ...any) ([]model.CityStats, error)
 
@@ -101,6 +149,18 @@ type Store interface {
 	// SelectScreenClassStats selects ScreenClassStats.
 	SelectScreenClassStats(string, ...any) ([]model.ScreenClassStats, error)
 
+	// SelectHostnameStats selects HostnameStats.
+	SelectHostnameStats(string, ...any) ([]model.HostnameStats, error)
+
+	// SelectChannelStats selects ChannelStats.
+	SelectChannelStats(string, ...any) ([]model.ChannelStats, error)
+
+	// SelectConnectionTypeStats selects ConnectionTypeStats.
+	SelectConnectionTypeStats(string, ...any) ([]model.ConnectionTypeStats, error)
+
+	// SelectQueryParamStats selects QueryParamStats.
+	SelectQueryParamStats(string, ...any) ([]model.QueryParamStats, error)
+
 	// SelectUTMSourceStats selects UTMSourceStats.
 	SelectUTMSourceStats(string, ...any) ([]model.UTMSourceStats, error)
 
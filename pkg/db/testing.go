@@ -47,6 +47,18 @@ func CleanupDB(t *testing.T, client *Client) {
 	assert.NoError(t, err)
 	_, err = client.Exec(`ALTER TABLE "user_agent" DELETE WHERE 1=1`)
 	assert.NoError(t, err)
+	_, err = client.Exec(`ALTER TABLE "bot" DELETE WHERE 1=1`)
+	assert.NoError(t, err)
+	_, err = client.Exec(`ALTER TABLE "imported_visitors" DELETE WHERE 1=1`)
+	assert.NoError(t, err)
+	_, err = client.Exec(`ALTER TABLE "imported_page" DELETE WHERE 1=1`)
+	assert.NoError(t, err)
+	_, err = client.Exec(`ALTER TABLE "imported_referrer" DELETE WHERE 1=1`)
+	assert.NoError(t, err)
+	_, err = client.Exec(`ALTER TABLE "imported_country" DELETE WHERE 1=1`)
+	assert.NoError(t, err)
+	_, err = client.Exec(`ALTER TABLE "imported_device" DELETE WHERE 1=1`)
+	assert.NoError(t, err)
 	time.Sleep(time.Millisecond * 50)
 }
 
@@ -62,6 +74,16 @@ func DropDB(t *testing.T, client *Client) {
 	assert.NoError(t, err)
 	_, err = client.Exec(`DROP TABLE IF EXISTS "bot"`)
 	assert.NoError(t, err)
+	_, err = client.Exec(`DROP TABLE IF EXISTS "imported_visitors"`)
+	assert.NoError(t, err)
+	_, err = client.Exec(`DROP TABLE IF EXISTS "imported_page"`)
+	assert.NoError(t, err)
+	_, err = client.Exec(`DROP TABLE IF EXISTS "imported_referrer"`)
+	assert.NoError(t, err)
+	_, err = client.Exec(`DROP TABLE IF EXISTS "imported_country"`)
+	assert.NoError(t, err)
+	_, err = client.Exec(`DROP TABLE IF EXISTS "imported_device"`)
+	assert.NoError(t, err)
 	_, err = client.Exec(`DROP TABLE IF EXISTS "schema_migrations"`)
 	assert.NoError(t, err)
 }
@@ -0,0 +1,546 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"errors"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/model"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/referrer"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// TableVisitors is the table for imported visitor statistics.
+	TableVisitors = "imported_visitors"
+
+	// TablePage is the table for imported page statistics.
+	TablePage = "imported_page"
+
+	// TableReferrer is the table for imported referrer statistics.
+	TableReferrer = "imported_referrer"
+
+	// TableCountry is the table for imported country statistics.
+	TableCountry = "imported_country"
+
+	// TableDevice is the table for imported device statistics.
+	TableDevice = "imported_device"
+)
+
+var (
+	// ErrUnknownCSV is returned in case the columns of a CSV file cannot be mapped.
+	ErrUnknownCSV = errors.New("unknown CSV format")
+
+	// csvColumns maps the column names used by Google Analytics (Universal Analytics and GA4) and Plausible exports.
+	csvColumns = map[string][]string{
+		"date":             {"date", "day", "day index", "ga:date"},
+		"visitors":         {"visitors", "users", "total users", "active users", "ga:users"},
+		"views":            {"pageviews", "page views", "views", "ga:pageviews"},
+		"sessions":         {"visits", "sessions", "ga:sessions"},
+		"bounces":          {"bounces", "ga:bounces"},
+		"bounce_rate":      {"bounce rate", "ga:bouncerate"},
+		"visit_duration":   {"visit_duration"},
+		"session_duration": {"avg. session duration", "average session duration", "ga:avgsessionduration"},
+		"path":             {"page", "page path", "page path and screen class", "ga:pagepath"},
+		"referrer":         {"source", "session source", "ga:source"},
+		"country":          {"country", "country iso code", "country id", "ga:countryisocode"},
+		"device":           {"device", "device category", "ga:devicecategory"},
+	}
+
+	// csvMetrics are metric columns which are not imported, but don't prevent importing the daily visitor statistics.
+	// Any other unknown column is considered a dimension (like the browser or entry page).
+	csvMetrics = []string{
+		"new users", "ga:newusers", "% new sessions", "ga:percentnewsessions",
+		"number of sessions per user", "ga:sessionsperuser", "pages / session", "ga:pageviewspersession",
+		"unique pageviews", "ga:uniquepageviews", "avg. time on page", "ga:avgtimeonpage",
+		"engaged sessions", "engagement rate", "average engagement time", "average engagement time per session",
+		"engaged sessions per active user", "views per active user", "views per session", "sessions per active user",
+		"event count", "event count per active user", "key events", "conversions", "total revenue",
+		"entrances", "exits", "events", "time_on_page",
+	}
+
+	directSources = map[string]struct{}{
+		"":                {},
+		"(direct)":        {},
+		"direct":          {},
+		"direct / none":   {},
+		"(none)":          {},
+		"direct / (none)": {},
+	}
+
+	emptyRequest = &http.Request{URL: new(url.URL), Header: make(http.Header)}
+)
+
+// CSVConfig is the configuration for the CSVImporter.
+type CSVConfig struct {
+	// Store is the Store the imported statistics are saved to (required).
+	Store db.Store
+
+	// ClientID is the client the statistics are imported for.
+	ClientID uint64
+
+	// From is the optional (inclusive) start of the date range to import.
+	From time.Time
+
+	// To is the optional (exclusive) end of the date range to import.
+	// This should be set to the cut-over date to prevent importing days that have been tracked already.
+	To time.Time
+}
+
+// CSVResult is the result of importing a CSV file.
+type CSVResult struct {
+	// Table is the table the statistics have been imported into.
+	Table string `json:"table"`
+
+	// Rows is the number of rows saved.
+	Rows int `json:"rows"`
+
+	// Skipped is the number of rows skipped, because they are outside the date range or invalid (like a totals row).
+	Skipped int `json:"skipped"`
+}
+
+// CSVImporter imports aggregated statistics from CSV exports of Google Analytics (Universal Analytics and GA4) and Plausible.
+//
+// The kind of statistics is detected from the columns. Each file must contain a date column and either daily visitor statistics
+// (visitors, page views, sessions, bounces), or visitors by page, source, country (ISO code), or device.
+// Files containing visitors by any other dimension (like the browser or entry page) are rejected with ErrUnknownCSV.
+// Rows for the same day and dimension are summed up.
+type CSVImporter struct {
+	config CSVConfig
+}
+
+// NewCSVImporter creates a new CSVImporter for given config.
+func NewCSVImporter(config CSVConfig) (*CSVImporter, error) {
+	if config.Store == nil {
+		return nil, errors.New("store missing")
+	}
+
+	return &CSVImporter{
+		config: config,
+	}, nil
+}
+
+// ImportFile imports given CSV file.
+// Zip archives (like the Plausible export) are extracted and all contained CSV files are imported.
+// CSV files in a zip archive that cannot be mapped to a table are skipped.
+func (importer *CSVImporter) ImportFile(name string) ([]CSVResult, error) {
+	if strings.ToLower(filepath.Ext(name)) == ".zip" {
+		archive, err := zip.OpenReader(name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		defer archive.Close()
+		results := make([]CSVResult, 0, len(archive.File))
+
+		for _, f := range archive.File {
+			if strings.ToLower(filepath.Ext(f.Name)) != ".csv" {
+				continue
+			}
+
+			r, err := f.Open()
+
+			if err != nil {
+				return results, err
+			}
+
+			result, err := importer.Import(r)
+			r.Close()
+
+			if errors.Is(err, ErrUnknownCSV) {
+				continue
+			} else if err != nil {
+				return results, err
+			}
+
+			results = append(results, *result)
+		}
+
+		return results, nil
+	}
+
+	f, err := os.Open(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+	result, err := importer.Import(f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []CSVResult{*result}, nil
+}
+
+// Import imports a single CSV file from given reader.
+func (importer *CSVImporter) Import(r io.Reader) (*CSVResult, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	columns, table, err := importer.readHeader(reader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows := newCSVRows(importer.config.ClientID)
+	result := &CSVResult{Table: table}
+
+	for {
+		record, err := reader.Read()
+
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if !importer.addRow(rows, table, columns, record) {
+			result.Skipped++
+		}
+	}
+
+	n, err := rows.save(importer.config.Store, table)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result.Rows = n
+	return result, nil
+}
+
+func (importer *CSVImporter) readHeader(reader *csv.Reader) (map[string]int, string, error) {
+	for {
+		header, err := reader.Read()
+
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, "", ErrUnknownCSV
+			}
+
+			return nil, "", err
+		}
+
+		columns := make(map[string]int)
+		unknownDimension := false
+
+		for i, name := range header {
+			name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+			known := name == "" || contains(csvMetrics, name)
+
+			for column, aliases := range csvColumns {
+				if contains(aliases, name) {
+					known = true
+
+					if _, found := columns[column]; !found {
+						columns[column] = i
+					}
+				}
+			}
+
+			if !known {
+				unknownDimension = true
+			}
+		}
+
+		if _, ok := columns["date"]; !ok {
+			// skip leading lines that are not the header
+			continue
+		}
+
+		if _, ok := columns["visitors"]; !ok {
+			return nil, "", ErrUnknownCSV
+		}
+
+		if _, ok := columns["path"]; ok {
+			return columns, TablePage, nil
+		} else if _, ok := columns["referrer"]; ok {
+			return columns, TableReferrer, nil
+		} else if _, ok := columns["country"]; ok {
+			return columns, TableCountry, nil
+		} else if _, ok := columns["device"]; ok {
+			return columns, TableDevice, nil
+		} else if unknownDimension {
+			// the visitors would be counted once per dimension value otherwise
+			return nil, "", ErrUnknownCSV
+		}
+
+		return columns, TableVisitors, nil
+	}
+}
+
+func (importer *CSVImporter) addRow(rows *csvRows, table string, columns map[string]int, record []string) bool {
+	date, ok := parseCSVDate(csvValue(record, columns, "date"))
+
+	if !ok ||
+		(!importer.config.From.IsZero() && date.Before(importer.config.From)) ||
+		(!importer.config.To.IsZero() && !date.Before(importer.config.To)) {
+		return false
+	}
+
+	visitors := parseCSVInt(csvValue(record, columns, "visitors"))
+	views := parseCSVInt(csvValue(record, columns, "views"))
+	sessions := parseCSVInt(csvValue(record, columns, "sessions"))
+	bounces := parseCSVInt(csvValue(record, columns, "bounces"))
+
+	if _, ok := columns["bounces"]; !ok {
+		bounces = int(parseCSVRate(csvValue(record, columns, "bounce_rate"))*float64(sessions) + 0.5)
+	}
+
+	switch table {
+	case TableVisitors:
+		duration := parseCSVInt(csvValue(record, columns, "visit_duration"))
+
+		if _, ok := columns["visit_duration"]; !ok {
+			duration = parseCSVDuration(csvValue(record, columns, "session_duration")) * sessions
+		}
+
+		rows.addVisitors(date, visitors, views, sessions, bounces, duration)
+	case TablePage:
+		path := strings.TrimSpace(csvValue(record, columns, "path"))
+
+		if path == "" {
+			return false
+		}
+
+		if u, err := url.Parse(path); err == nil && u.Path != "" {
+			path = u.Path
+		}
+
+		rows.addPage(date, path, visitors, views, sessions, bounces)
+	case TableReferrer:
+		rows.addReferrer(date, referrerName(csvValue(record, columns, "referrer")), visitors, sessions, bounces)
+	case TableCountry:
+		code := strings.ToLower(strings.TrimSpace(csvValue(record, columns, "country")))
+
+		if utf8.RuneCountInString(code) != 2 {
+			return false
+		}
+
+		rows.addCountry(date, code, visitors)
+	case TableDevice:
+		device := strings.ToLower(strings.TrimSpace(csvValue(record, columns, "device")))
+
+		if device == "laptop" {
+			device = "desktop"
+		}
+
+		rows.addDevice(date, device, visitors)
+	}
+
+	return true
+}
+
+// csvRows aggregates the rows by day and dimension.
+type csvRows struct {
+	clientID  uint64
+	visitors  map[time.Time]*model.ImportedVisitors
+	pages     map[csvKey]*model.ImportedPage
+	referrers map[csvKey]*model.ImportedReferrer
+	countries map[csvKey]*model.ImportedCountry
+	devices   map[csvKey]*model.ImportedDevice
+}
+
+type csvKey struct {
+	date time.Time
+	key  string
+}
+
+func newCSVRows(clientID uint64) *csvRows {
+	return &csvRows{
+		clientID:  clientID,
+		visitors:  make(map[time.Time]*model.ImportedVisitors),
+		pages:     make(map[csvKey]*model.ImportedPage),
+		referrers: make(map[csvKey]*model.ImportedReferrer),
+		countries: make(map[csvKey]*model.ImportedCountry),
+		devices:   make(map[csvKey]*model.ImportedDevice),
+	}
+}
+
+func (rows *csvRows) addVisitors(date time.Time, visitors, views, sessions, bounces, duration int) {
+	row, ok := rows.visitors[date]
+
+	if !ok {
+		row = &model.ImportedVisitors{ClientID: rows.clientID, Date: date}
+		rows.visitors[date] = row
+	}
+
+	row.Visitors += visitors
+	row.Views += views
+	row.Sessions += sessions
+	row.Bounces += bounces
+	row.SessionDuration += duration
+}
+
+func (rows *csvRows) addPage(date time.Time, path string, visitors, views, sessions, bounces int) {
+	key := csvKey{date, path}
+	row, ok := rows.pages[key]
+
+	if !ok {
+		row = &model.ImportedPage{ClientID: rows.clientID, Date: date, Path: path}
+		rows.pages[key] = row
+	}
+
+	row.Visitors += visitors
+	row.Views += views
+	row.Sessions += sessions
+	row.Bounces += bounces
+}
+
+func (rows *csvRows) addReferrer(date time.Time, referrer string, visitors, sessions, bounces int) {
+	key := csvKey{date, referrer}
+	row, ok := rows.referrers[key]
+
+	if !ok {
+		row = &model.ImportedReferrer{ClientID: rows.clientID, Date: date, Referrer: referrer}
+		rows.referrers[key] = row
+	}
+
+	row.Visitors += visitors
+	row.Sessions += sessions
+	row.Bounces += bounces
+}
+
+func (rows *csvRows) addCountry(date time.Time, code string, visitors int) {
+	key := csvKey{date, code}
+	row, ok := rows.countries[key]
+
+	if !ok {
+		row = &model.ImportedCountry{ClientID: rows.clientID, Date: date, CountryCode: code}
+		rows.countries[key] = row
+	}
+
+	row.Visitors += visitors
+}
+
+func (rows *csvRows) addDevice(date time.Time, device string, visitors int) {
+	key := csvKey{date, device}
+	row, ok := rows.devices[key]
+
+	if !ok {
+		row = &model.ImportedDevice{ClientID: rows.clientID, Date: date, Device: device}
+		rows.devices[key] = row
+	}
+
+	row.Visitors += visitors
+}
+
+func (rows *csvRows) save(store db.Store, table string) (int, error) {
+	switch table {
+	case TableVisitors:
+		return len(rows.visitors), store.SaveImportedVisitors(values(rows.visitors))
+	case TablePage:
+		return len(rows.pages), store.SaveImportedPages(values(rows.pages))
+	case TableReferrer:
+		return len(rows.referrers), store.SaveImportedReferrers(values(rows.referrers))
+	case TableCountry:
+		return len(rows.countries), store.SaveImportedCountries(values(rows.countries))
+	default:
+		return len(rows.devices), store.SaveImportedDevices(values(rows.devices))
+	}
+}
+
+func values[K comparable, V any](m map[K]*V) []V {
+	list := make([]V, 0, len(m))
+
+	for _, v := range m {
+		list = append(list, *v)
+	}
+
+	return list
+}
+
+// referrerName returns the referrer name for a source, like "Google" for "google.com".
+// Direct traffic is returned as an empty string.
+func referrerName(source string) string {
+	source = strings.TrimSpace(source)
+
+	if _, direct := directSources[strings.ToLower(source)]; direct {
+		return ""
+	}
+
+	_, name, _ := referrer.GetWithParams(emptyRequest, nil, source, "", nil)
+
+	if name == "" {
+		return source
+	}
+
+	return name
+}
+
+func csvValue(record []string, columns map[string]int, column string) string {
+	i, ok := columns[column]
+
+	if !ok || i >= len(record) {
+		return ""
+	}
+
+	return strings.TrimSpace(record[i])
+}
+
+func parseCSVDate(value string) (time.Time, bool) {
+	for _, layout := range []string{time.DateOnly, "20060102", "1/2/06", "1/2/2006"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func parseCSVInt(value string) int {
+	value = strings.ReplaceAll(strings.ReplaceAll(value, ",", ""), " ", "")
+	f, _ := strconv.ParseFloat(value, 64)
+	return int(f + 0.5)
+}
+
+func parseCSVRate(value string) float64 {
+	percent := strings.HasSuffix(value, "%")
+	f, _ := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, "%")), 64)
+
+	if percent || f > 1 {
+		return f / 100
+	}
+
+	return f
+}
+
+// parseCSVDuration parses a duration in seconds or in the format hh:mm:ss.
+func parseCSVDuration(value string) int {
+	if !strings.Contains(value, ":") {
+		return parseCSVInt(value)
+	}
+
+	seconds := 0
+
+	for _, part := range strings.Split(value, ":") {
+		n, _ := strconv.Atoi(part)
+		seconds = seconds*60 + n
+	}
+
+	return seconds
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
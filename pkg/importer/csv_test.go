@@ -0,0 +1,201 @@
+package importer
+
+import (
+	"archive/zip"
+	"errors"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSVImporter_ImportUniversalAnalytics(t *testing.T) {
+	store := db.NewClientMock()
+	imp := newTestCSVImporter(t, store)
+	result, err := imp.Import(strings.NewReader(`# ----------------------------------------
+# All Web Site Data
+# Audience Overview
+# 20231001-20231010
+# ----------------------------------------
+
+Day Index,Users,Sessions,Pageviews,Bounce Rate,Avg. Session Duration
+9/30/23,1,1,1,0%,00:00:10
+10/1/23,"1,200",1500,"3,000",40.00%,00:01:30
+10/2/23,100,200,300,50%,00:00:10
+10/10/23,1,1,1,0%,00:00:10
+,"1,300",1700,"3,300",41.18%,00:01:21
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, &CSVResult{Table: TableVisitors, Rows: 2, Skipped: 3}, result)
+	store = db.NewClientMock()
+	imp = newTestCSVImporter(t, store)
+	result, err = imp.Import(strings.NewReader(`ga:date,ga:users,ga:sessions,ga:pageviews,ga:bounceRate,ga:avgSessionDuration
+20230930,1,1,1,0%,00:00:10
+20231001,"1,200",1500,"3,000",40.00%,00:01:30
+20231002,100,200,300,50%,00:00:10
+20231010,1,1,1,0%,00:00:10
+,"1,300",1700,"3,300",41.18%,00:01:21
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, &CSVResult{Table: TableVisitors, Rows: 2, Skipped: 3}, result)
+	visitors := store.GetImported().Visitors
+	sort.Slice(visitors, func(i, j int) bool {
+		return visitors[i].Date.Before(visitors[j].Date)
+	})
+	assert.Equal(t, []model.ImportedVisitors{
+		{ClientID: 42, Date: date(2023, 10, 1), Visitors: 1200, Views: 3000, Sessions: 1500, Bounces: 600, SessionDuration: 135000},
+		{ClientID: 42, Date: date(2023, 10, 2), Visitors: 100, Views: 300, Sessions: 200, Bounces: 100, SessionDuration: 2000},
+	}, visitors)
+}
+
+func TestCSVImporter_ImportGA4(t *testing.T) {
+	store := db.NewClientMock()
+	imp := newTestCSVImporter(t, store)
+	result, err := imp.Import(strings.NewReader(`# Pages and screens
+Date,Page path and screen class,Views,Total users,Sessions,Bounces
+20231001,/,100,50,60,20
+20231001,/?utm_source=newsletter,10,5,6,2
+20231001,/about,20,10,10,5
+20231002,/,30,15,15,5
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, &CSVResult{Table: TablePage, Rows: 3}, result)
+	pages := store.GetImported().Pages
+	sort.Slice(pages, func(i, j int) bool {
+		if pages[i].Date.Equal(pages[j].Date) {
+			return pages[i].Path < pages[j].Path
+		}
+
+		return pages[i].Date.Before(pages[j].Date)
+	})
+	assert.Equal(t, []model.ImportedPage{
+		{ClientID: 42, Date: date(2023, 10, 1), Path: "/", Visitors: 55, Views: 110, Sessions: 66, Bounces: 22},
+		{ClientID: 42, Date: date(2023, 10, 1), Path: "/about", Visitors: 10, Views: 20, Sessions: 10, Bounces: 5},
+		{ClientID: 42, Date: date(2023, 10, 2), Path: "/", Visitors: 15, Views: 30, Sessions: 15, Bounces: 5},
+	}, pages)
+	result, err = imp.Import(strings.NewReader(`Date,Country ID,Active users
+20231001,DE,10
+20231001,US,20
+20231001,(not set),3
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, &CSVResult{Table: TableCountry, Rows: 2, Skipped: 1}, result)
+	countries := store.GetImported().Countries
+	sort.Slice(countries, func(i, j int) bool {
+		return countries[i].CountryCode < countries[j].CountryCode
+	})
+	assert.Equal(t, []model.ImportedCountry{
+		{ClientID: 42, Date: date(2023, 10, 1), CountryCode: "de", Visitors: 10},
+		{ClientID: 42, Date: date(2023, 10, 1), CountryCode: "us", Visitors: 20},
+	}, countries)
+}
+
+func TestCSVImporter_ImportFilePlausible(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "plausible.zip")
+	f, err := os.Create(name)
+	assert.NoError(t, err)
+	archive := zip.NewWriter(f)
+	files := map[string]string{
+		"imported_visitors.csv":          "date,visitors,pageviews,bounces,visits,visit_duration\n2023-10-01,10,20,4,12,600\n",
+		"imported_sources.csv":           "date,source,utm_medium,utm_campaign,utm_content,utm_term,visitors,visits,visit_duration,bounces\n2023-10-01,Google,,,,,5,6,100,2\n2023-10-01,,,,,,3,3,0,3\n2023-10-01,Direct / None,,,,,1,1,0,1\n",
+		"imported_devices.csv":           "date,device,visitors,visits,visit_duration,bounces\n2023-10-01,Desktop,7,8,100,2\n2023-10-01,Laptop,1,1,0,0\n",
+		"imported_browsers.csv":          "date,browser,browser_version,visitors,visits,visit_duration,bounces\n2023-10-01,Chrome,120.0,6,7,80,2\n2023-10-01,Firefox,121.0,4,5,20,2\n",
+		"imported_operating_systems.csv": "date,operating_system,operating_system_version,visitors,visits,visit_duration,bounces\n2023-10-01,Windows,11,10,12,100,4\n",
+		"imported_entry_pages.csv":       "date,entry_page,visitors,entrances,visit_duration,bounces,pageviews\n2023-10-01,/,8,10,500,3,15\n2023-10-01,/about,2,2,100,1,5\n",
+		"imported_exit_pages.csv":        "date,exit_page,visitors,visit_duration,exits,pageviews\n2023-10-01,/,10,600,12,20\n",
+		"imported_custom_events.csv":     "date,name,link_url,path,visitors,events\n2023-10-01,Signup,,,3,3\n",
+		"readme.txt":                     "not a CSV file",
+	}
+
+	for _, file := range []string{"imported_visitors.csv", "imported_browsers.csv", "imported_sources.csv", "imported_entry_pages.csv",
+		"imported_devices.csv", "imported_exit_pages.csv", "imported_operating_systems.csv", "imported_custom_events.csv", "readme.txt"} {
+		w, err := archive.Create(file)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(files[file]))
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, archive.Close())
+	assert.NoError(t, f.Close())
+	store := db.NewClientMock()
+	imp := newTestCSVImporter(t, store)
+	results, err := imp.ImportFile(name)
+	assert.NoError(t, err)
+	assert.Equal(t, []CSVResult{
+		{Table: TableVisitors, Rows: 1},
+		{Table: TableReferrer, Rows: 2},
+		{Table: TableDevice, Rows: 1},
+	}, results)
+	imported := store.GetImported()
+	assert.Equal(t, []model.ImportedVisitors{
+		{ClientID: 42, Date: date(2023, 10, 1), Visitors: 10, Views: 20, Sessions: 12, Bounces: 4, SessionDuration: 600},
+	}, imported.Visitors)
+	sort.Slice(imported.Referrers, func(i, j int) bool {
+		return imported.Referrers[i].Referrer < imported.Referrers[j].Referrer
+	})
+	assert.Equal(t, []model.ImportedReferrer{
+		{ClientID: 42, Date: date(2023, 10, 1), Referrer: "", Visitors: 4, Sessions: 4, Bounces: 4},
+		{ClientID: 42, Date: date(2023, 10, 1), Referrer: "Google", Visitors: 5, Sessions: 6, Bounces: 2},
+	}, imported.Referrers)
+	assert.Equal(t, []model.ImportedDevice{
+		{ClientID: 42, Date: date(2023, 10, 1), Device: "desktop", Visitors: 8},
+	}, imported.Devices)
+}
+
+func TestCSVImporter_ImportUnknownDimension(t *testing.T) {
+	store := db.NewClientMock()
+	imp := newTestCSVImporter(t, store)
+	result, err := imp.Import(strings.NewReader("date,browser,visitors,visits\n2023-10-01,Chrome,6,7\n"))
+	assert.ErrorIs(t, err, ErrUnknownCSV)
+	assert.Nil(t, result)
+	result, err = imp.Import(strings.NewReader("Date,Active users,New users,Engaged sessions,Event count\n20231001,6,2,4,20\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, &CSVResult{Table: TableVisitors, Rows: 1}, result)
+	assert.Empty(t, store.GetImported().Pages)
+	assert.Len(t, store.GetImported().Visitors, 1)
+}
+
+func TestCSVImporter_ImportSaveError(t *testing.T) {
+	store := db.NewClientMock()
+	store.SetSaveError(errors.New("error"))
+	imp := newTestCSVImporter(t, store)
+	result, err := imp.Import(strings.NewReader("date,visitors\n2023-10-01,1\n"))
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
+
+func TestReferrerName(t *testing.T) {
+	assert.Empty(t, referrerName(""))
+	assert.Empty(t, referrerName("(direct)"))
+	assert.Empty(t, referrerName("Direct / None"))
+	assert.Equal(t, "Google", referrerName("google.com"))
+	assert.Equal(t, "Newsletter", referrerName("Newsletter"))
+}
+
+func TestParseCSVDuration(t *testing.T) {
+	assert.Equal(t, 0, parseCSVDuration(""))
+	assert.Equal(t, 42, parseCSVDuration("42"))
+	assert.Equal(t, 42, parseCSVDuration("41.6"))
+	assert.Equal(t, 3723, parseCSVDuration("01:02:03"))
+}
+
+func newTestCSVImporter(t *testing.T, store db.Store) *CSVImporter {
+	imp, err := NewCSVImporter(CSVConfig{
+		Store:    store,
+		ClientID: 42,
+		From:     date(2023, 10, 1),
+		To:       date(2023, 10, 10),
+	})
+	assert.NoError(t, err)
+	return imp
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
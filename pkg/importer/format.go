@@ -0,0 +1,259 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Combined is the combined log format used by Nginx and Apache by default.
+	Combined = `$remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent "$http_referer" "$http_user_agent"`
+
+	// Common is the common log format (without referrer and User-Agent).
+	Common = `$remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent`
+
+	timeLocalLayout = "02/Jan/2006:15:04:05 -0700"
+)
+
+var (
+	// ErrInvalidLine is returned in case a line does not match the log format.
+	ErrInvalidLine = errors.New("line does not match log format")
+
+	// apacheDirectives maps Apache LogFormat directives to Nginx variables.
+	apacheDirectives = map[string]string{
+		"h":  "remote_addr",
+		"a":  "remote_addr",
+		"l":  "remote_logname",
+		"u":  "remote_user",
+		"t":  "[time_local]",
+		"r":  "request",
+		"m":  "request_method",
+		"U":  "uri",
+		"q":  "query_string",
+		"s":  "status",
+		">s": "status",
+		"b":  "body_bytes_sent",
+		"B":  "body_bytes_sent",
+		"O":  "bytes_sent",
+		"v":  "server_name",
+		"V":  "server_name",
+	}
+
+	variable = regexp.MustCompile(`\$[a-zA-Z0-9_]+|%[<>]?(\{[^}]*\})?[a-zA-Z]`)
+)
+
+// Entry is a single parsed access log line.
+type Entry struct {
+	Time           time.Time
+	IP             string
+	Method         string
+	URI            string
+	Status         int
+	Referrer       string
+	UserAgent      string
+	AcceptLanguage string
+	Host           string
+}
+
+// Format is a compiled log format.
+type Format struct {
+	expr      *regexp.Regexp
+	variables []string
+}
+
+// ParseFormat compiles given log format.
+// The format can either use Nginx variables (like $remote_addr or $http_user_agent)
+// or Apache LogFormat directives (like %h or %{User-Agent}i). Combined and Common can be used for the default formats.
+// The format must contain the time ($time_local, $time_iso8601, or $msec) and the request ($request or $request_uri).
+func ParseFormat(format string) (*Format, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	variables := make([]string, 0)
+	pos := 0
+
+	for _, match := range variable.FindAllStringIndex(format, -1) {
+		name := variableName(format[match[0]:match[1]])
+		sb.WriteString(regexp.QuoteMeta(format[pos:match[0]]))
+
+		if strings.HasPrefix(name, "[") {
+			sb.WriteString(`\[(.*?)\]`)
+			name = strings.Trim(name, "[]")
+		} else {
+			sb.WriteString("(.*?)")
+		}
+
+		variables = append(variables, name)
+		pos = match[1]
+	}
+
+	sb.WriteString(regexp.QuoteMeta(format[pos:]))
+	sb.WriteString(`\s*$`)
+
+	if !containsAny(variables, "time_local", "time_iso8601", "msec") {
+		return nil, errors.New("the log format must contain the time")
+	}
+
+	if !containsAny(variables, "request", "request_uri", "uri") {
+		return nil, errors.New("the log format must contain the request")
+	}
+
+	expr, err := regexp.Compile(sb.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Format{
+		expr:      expr,
+		variables: variables,
+	}, nil
+}
+
+// Parse parses a single log line.
+func (format *Format) Parse(line string) (*Entry, error) {
+	match := format.expr.FindStringSubmatch(line)
+
+	if match == nil {
+		return nil, ErrInvalidLine
+	}
+
+	entry := new(Entry)
+	var uri, query string
+
+	for i, name := range format.variables {
+		value := match[i+1]
+
+		if value == "-" {
+			continue
+		}
+
+		switch name {
+		case "time_local":
+			t, err := time.Parse(timeLocalLayout, value)
+
+			if err != nil {
+				return nil, err
+			}
+
+			entry.Time = t
+		case "time_iso8601":
+			t, err := time.Parse(time.RFC3339, value)
+
+			if err != nil {
+				return nil, err
+			}
+
+			entry.Time = t
+		case "msec":
+			sec, err := strconv.ParseFloat(value, 64)
+
+			if err != nil {
+				return nil, err
+			}
+
+			entry.Time = time.UnixMilli(int64(sec * 1000))
+		case "remote_addr":
+			entry.IP = value
+		case "request":
+			method, rest, _ := strings.Cut(value, " ")
+			requestURI, _, _ := strings.Cut(rest, " ")
+
+			if method == "" || !strings.HasPrefix(requestURI, "/") {
+				return nil, fmt.Errorf("invalid request: %s", value)
+			}
+
+			entry.Method = method
+			entry.URI = requestURI
+		case "request_method":
+			entry.Method = value
+		case "request_uri":
+			entry.URI = value
+		case "uri":
+			uri = value
+		case "query_string", "args":
+			query = value
+		case "status":
+			status, err := strconv.Atoi(value)
+
+			if err != nil {
+				return nil, err
+			}
+
+			entry.Status = status
+		case "http_referer":
+			entry.Referrer = unescape(value)
+		case "http_user_agent":
+			entry.UserAgent = unescape(value)
+		case "http_accept_language":
+			entry.AcceptLanguage = unescape(value)
+		case "host", "http_host", "server_name":
+			if entry.Host == "" {
+				entry.Host = value
+			}
+		}
+	}
+
+	if entry.URI == "" && uri != "" {
+		entry.URI = uri
+
+		if query != "" {
+			entry.URI += "?" + strings.TrimPrefix(query, "?")
+		}
+	}
+
+	if entry.Time.IsZero() || entry.URI == "" {
+		return nil, ErrInvalidLine
+	}
+
+	if entry.Method == "" {
+		entry.Method = "GET"
+	}
+
+	entry.Time = entry.Time.UTC()
+	return entry, nil
+}
+
+// variableName returns the Nginx variable name for an Nginx variable or Apache directive.
+func variableName(v string) string {
+	if strings.HasPrefix(v, "$") {
+		return v[1:]
+	}
+
+	v = v[1:]
+
+	if strings.HasPrefix(v, "{") {
+		name, directive, _ := strings.Cut(v[1:], "}")
+
+		if directive == "i" {
+			return "http_" + strings.ReplaceAll(strings.ToLower(name), "-", "_")
+		}
+
+		return name
+	}
+
+	if name, ok := apacheDirectives[v]; ok {
+		return name
+	}
+
+	return v
+}
+
+func containsAny(list []string, values ...string) bool {
+	for _, item := range list {
+		for _, value := range values {
+			if item == value {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func unescape(value string) string {
+	return strings.ReplaceAll(value, `\"`, `"`)
+}
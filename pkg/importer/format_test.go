@@ -0,0 +1,59 @@
+package importer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseFormat(t *testing.T) {
+	_, err := ParseFormat(`$remote_addr "$request"`)
+	assert.EqualError(t, err, "the log format must contain the time")
+	_, err = ParseFormat(`$remote_addr [$time_local]`)
+	assert.EqualError(t, err, "the log format must contain the request")
+	format, err := ParseFormat(Combined)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"remote_addr", "remote_user", "time_local", "request", "status", "body_bytes_sent", "http_referer", "http_user_agent"}, format.variables)
+	format, err = ParseFormat(`%h %l %u %t "%r" %>s %b "%{Referer}i" "%{User-Agent}i"`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"remote_addr", "remote_logname", "remote_user", "time_local", "request", "status", "body_bytes_sent", "http_referer", "http_user_agent"}, format.variables)
+}
+
+func TestFormat_Parse(t *testing.T) {
+	for _, f := range []string{Combined, `%h %l %u %t "%r" %>s %b "%{Referer}i" "%{User-Agent}i"`} {
+		format, err := ParseFormat(f)
+		assert.NoError(t, err)
+		entry, err := format.Parse(`81.2.69.142 - - [10/Oct/2023:13:55:36 +0200] "GET /foo?bar=baz HTTP/1.1" 200 2326 "https://google.com/" "Mozilla/5.0 (X11; Linux x86_64; rv:105.0) \"Gecko\" Firefox/105.0"`)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2023, 10, 10, 11, 55, 36, 0, time.UTC), entry.Time)
+		assert.Equal(t, "81.2.69.142", entry.IP)
+		assert.Equal(t, "GET", entry.Method)
+		assert.Equal(t, "/foo?bar=baz", entry.URI)
+		assert.Equal(t, 200, entry.Status)
+		assert.Equal(t, "https://google.com/", entry.Referrer)
+		assert.Equal(t, `Mozilla/5.0 (X11; Linux x86_64; rv:105.0) "Gecko" Firefox/105.0`, entry.UserAgent)
+	}
+
+	format, err := ParseFormat(Common)
+	assert.NoError(t, err)
+	entry, err := format.Parse(`::1 - user [10/Oct/2023:13:55:36 +0000] "POST / HTTP/2.0" 404 -`)
+	assert.NoError(t, err)
+	assert.Equal(t, "::1", entry.IP)
+	assert.Equal(t, "POST", entry.Method)
+	assert.Equal(t, "/", entry.URI)
+	assert.Equal(t, 404, entry.Status)
+	assert.Empty(t, entry.Referrer)
+	assert.Empty(t, entry.UserAgent)
+	_, err = format.Parse(`::1 - - [10/Oct/2023:13:55:36 +0000] "\x16\x03\x01" 400 -`)
+	assert.Error(t, err)
+	_, err = format.Parse(`not a log line`)
+	assert.ErrorIs(t, err, ErrInvalidLine)
+	format, err = ParseFormat(`$host $remote_addr $time_iso8601 $request_method $request_uri $status "$http_accept_language"`)
+	assert.NoError(t, err)
+	entry, err = format.Parse(`example.com 81.2.69.142 2023-10-10T13:55:36+02:00 GET /page 200 "de-DE,de;q=0.9"`)
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com", entry.Host)
+	assert.Equal(t, time.Date(2023, 10, 10, 11, 55, 36, 0, time.UTC), entry.Time)
+	assert.Equal(t, "/page", entry.URI)
+	assert.Equal(t, "de-DE,de;q=0.9", entry.AcceptLanguage)
+}
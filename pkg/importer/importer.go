@@ -0,0 +1,230 @@
+package importer
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	defaultProgressInterval = 10_000
+	maxLineSize             = 1024 * 1024
+)
+
+// Progress is the state of an import.
+type Progress struct {
+	// File is the file being imported (if any).
+	File string `json:"file"`
+
+	// Lines is the number of lines read.
+	Lines int `json:"lines"`
+
+	// Imported is the number of lines passed to the tracker.
+	Imported int `json:"imported"`
+
+	// Skipped is the number of lines skipped, because they are outside the date range, not a page, or not successful.
+	Skipped int `json:"skipped"`
+
+	// Invalid is the number of lines that could not be parsed.
+	Invalid int `json:"invalid"`
+}
+
+// Config is the configuration for the Importer.
+type Config struct {
+	// Tracker is the Tracker the log entries are passed to (required).
+	// The hits are filtered, fingerprinted, and assigned to sessions like live traffic.
+	Tracker *tracker.Tracker
+
+	// ClientID is the client the log entries are imported for.
+	ClientID uint64
+
+	// Format is the log format (required).
+	Format *Format
+
+	// Hostname is used to build the page URL in case the log format does not contain the host.
+	Hostname string
+
+	// From is the optional (inclusive) start of the date range to import.
+	From time.Time
+
+	// To is the optional (exclusive) end of the date range to import.
+	To time.Time
+
+	// StaticExtensions is the list of file extensions for static assets that are skipped.
+	// If nil, tracker.DefaultStaticExtensions will be used.
+	StaticExtensions []string
+
+	// Progress is called regularly while importing and once at the end of each file.
+	Progress func(Progress)
+
+	// ProgressInterval sets after how many lines Progress is called.
+	// If set to <= 0, the default value of 10,000 will be used.
+	ProgressInterval int
+
+	// Logger is the log/slog.Logger used to log invalid lines.
+	Logger *slog.Logger
+}
+
+func (config *Config) validate() {
+	if config.StaticExtensions == nil {
+		config.StaticExtensions = tracker.DefaultStaticExtensions
+	}
+
+	if config.ProgressInterval <= 0 {
+		config.ProgressInterval = defaultProgressInterval
+	}
+
+	if config.Logger == nil {
+		config.Logger = slog.New(slog.NewTextHandler(os.Stdout, nil))
+	}
+}
+
+// Importer imports web server access logs.
+// The log lines are expected to be in chronological order, like they are written by the web server.
+type Importer struct {
+	config Config
+}
+
+// NewImporter creates a new Importer for given config.
+func NewImporter(config Config) (*Importer, error) {
+	if config.Tracker == nil {
+		return nil, errors.New("tracker missing")
+	}
+
+	if config.Format == nil {
+		return nil, errors.New("log format missing")
+	}
+
+	config.validate()
+	return &Importer{
+		config: config,
+	}, nil
+}
+
+// ImportFile imports given log file. Gzip compressed files are decompressed automatically.
+func (importer *Importer) ImportFile(name string) (Progress, error) {
+	f, err := os.Open(name)
+
+	if err != nil {
+		return Progress{File: name}, err
+	}
+
+	defer f.Close()
+	return importer.importReader(name, f)
+}
+
+// Import imports the log from given reader. Gzip compressed input is decompressed automatically.
+func (importer *Importer) Import(r io.Reader) (Progress, error) {
+	return importer.importReader("", r)
+}
+
+func (importer *Importer) importReader(name string, r io.Reader) (Progress, error) {
+	progress := Progress{File: name}
+	reader := bufio.NewReader(r)
+
+	if magic, err := reader.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(reader)
+
+		if err != nil {
+			return progress, err
+		}
+
+		defer gz.Close()
+		reader = bufio.NewReader(gz)
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	for scanner.Scan() {
+		progress.Lines++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line != "" {
+			importer.importLine(line, &progress)
+		} else {
+			progress.Skipped++
+		}
+
+		if importer.config.Progress != nil && progress.Lines%importer.config.ProgressInterval == 0 {
+			importer.config.Progress(progress)
+		}
+	}
+
+	if importer.config.Progress != nil {
+		importer.config.Progress(progress)
+	}
+
+	return progress, scanner.Err()
+}
+
+func (importer *Importer) importLine(line string, progress *Progress) {
+	entry, err := importer.config.Format.Parse(line)
+
+	if err != nil {
+		progress.Invalid++
+		importer.config.Logger.Debug("error parsing log line", "err", err, "file", progress.File, "line", progress.Lines)
+		return
+	}
+
+	if !importer.track(entry) {
+		progress.Skipped++
+		return
+	}
+
+	u := entry.URI
+	host := entry.Host
+
+	if host == "" {
+		host = importer.config.Hostname
+	}
+
+	if host != "" {
+		u = "https://" + host + u
+	}
+
+	importer.config.Tracker.PageViewHit(tracker.Hit{
+		UserAgent:      entry.UserAgent,
+		IP:             entry.IP,
+		AcceptLanguage: entry.AcceptLanguage,
+		Referrer:       entry.Referrer,
+		URL:            u,
+	}, importer.config.ClientID, tracker.Options{
+		StatusCode: uint16(entry.Status),
+		Time:       entry.Time,
+	})
+	progress.Imported++
+}
+
+func (importer *Importer) track(entry *Entry) bool {
+	if (!importer.config.From.IsZero() && entry.Time.Before(importer.config.From)) ||
+		(!importer.config.To.IsZero() && !entry.Time.Before(importer.config.To)) {
+		return false
+	}
+
+	if entry.Method != http.MethodGet ||
+		(entry.Status != 0 && (entry.Status < 200 || entry.Status > 299) && entry.Status != http.StatusNotModified) {
+		return false
+	}
+
+	p, _, _ := strings.Cut(entry.URI, "?")
+	ext := strings.ToLower(path.Ext(p))
+
+	if ext != "" {
+		for _, static := range importer.config.StaticExtensions {
+			if ext == static {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,88 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testLog = `81.2.69.142 - - [09/Oct/2023:23:59:59 +0000] "GET /before HTTP/1.1" 200 100 "-" "Mozilla/5.0 (X11; Linux x86_64; rv:105.0) Gecko/20100101 Firefox/105.0"
+81.2.69.142 - - [10/Oct/2023:10:00:00 +0000] "GET / HTTP/1.1" 200 100 "https://google.com/" "Mozilla/5.0 (X11; Linux x86_64; rv:105.0) Gecko/20100101 Firefox/105.0"
+81.2.69.142 - - [10/Oct/2023:10:00:01 +0000] "GET /style.css HTTP/1.1" 200 100 "https://example.com/" "Mozilla/5.0 (X11; Linux x86_64; rv:105.0) Gecko/20100101 Firefox/105.0"
+81.2.69.142 - - [10/Oct/2023:10:01:00 +0000] "GET /about HTTP/1.1" 200 100 "https://example.com/" "Mozilla/5.0 (X11; Linux x86_64; rv:105.0) Gecko/20100101 Firefox/105.0"
+81.2.69.142 - - [10/Oct/2023:10:02:00 +0000] "POST /form HTTP/1.1" 200 100 "https://example.com/about" "Mozilla/5.0 (X11; Linux x86_64; rv:105.0) Gecko/20100101 Firefox/105.0"
+81.2.69.142 - - [10/Oct/2023:10:03:00 +0000] "GET /error HTTP/1.1" 500 100 "https://example.com/about" "Mozilla/5.0 (X11; Linux x86_64; rv:105.0) Gecko/20100101 Firefox/105.0"
+81.2.69.143 - - [10/Oct/2023:10:04:00 +0000] "GET / HTTP/1.1" 200 100 "-" "Googlebot/2.1 (+http://www.google.com/bot.html)"
+invalid
+
+81.2.69.142 - - [11/Oct/2023:00:00:00 +0000] "GET /after HTTP/1.1" 200 100 "-" "Mozilla/5.0 (X11; Linux x86_64; rv:105.0) Gecko/20100101 Firefox/105.0"
+`
+)
+
+func TestImporter_Import(t *testing.T) {
+	store := db.NewClientMock()
+	imp, progress := newTestImporter(t, store)
+	p, err := imp.Import(strings.NewReader(testLog))
+	assert.NoError(t, err)
+	imp.config.Tracker.Stop()
+	assert.Equal(t, Progress{Lines: 10, Imported: 3, Skipped: 6, Invalid: 1}, p)
+	assert.Equal(t, []Progress{{Lines: 5, Imported: 2, Skipped: 3}, {Lines: 10, Imported: 3, Skipped: 6, Invalid: 1}, p}, *progress)
+	pageViews := store.GetPageViews()
+	assert.Len(t, pageViews, 2)
+	assert.Equal(t, uint64(42), pageViews[0].ClientID)
+	assert.Equal(t, "/", pageViews[0].Path)
+	assert.Equal(t, time.Date(2023, 10, 10, 10, 0, 0, 0, time.UTC), pageViews[0].Time)
+	assert.Equal(t, "https://google.com", pageViews[0].Referrer)
+	assert.Equal(t, "/about", pageViews[1].Path)
+	assert.Equal(t, uint32(60), pageViews[1].DurationSeconds)
+	assert.Equal(t, pageViews[0].SessionID, pageViews[1].SessionID)
+	bots := store.GetBots()
+	assert.Len(t, bots, 1)
+	assert.Equal(t, time.Date(2023, 10, 10, 10, 4, 0, 0, time.UTC), bots[0].Time)
+}
+
+func TestImporter_ImportFileGzip(t *testing.T) {
+	var buffer bytes.Buffer
+	w := gzip.NewWriter(&buffer)
+	_, err := w.Write([]byte(testLog))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	name := filepath.Join(t.TempDir(), "access.log.gz")
+	assert.NoError(t, os.WriteFile(name, buffer.Bytes(), 0644))
+	store := db.NewClientMock()
+	imp, _ := newTestImporter(t, store)
+	p, err := imp.ImportFile(name)
+	assert.NoError(t, err)
+	imp.config.Tracker.Stop()
+	assert.Equal(t, name, p.File)
+	assert.Equal(t, 3, p.Imported)
+	assert.Len(t, store.GetPageViews(), 2)
+}
+
+func newTestImporter(t *testing.T, store *db.ClientMock) (*Importer, *[]Progress) {
+	format, err := ParseFormat(Combined)
+	assert.NoError(t, err)
+	progress := make([]Progress, 0)
+	imp, err := NewImporter(Config{
+		Tracker:          tracker.NewTracker(tracker.Config{Store: store}),
+		ClientID:         42,
+		Format:           format,
+		Hostname:         "example.com",
+		From:             time.Date(2023, 10, 10, 0, 0, 0, 0, time.UTC),
+		To:               time.Date(2023, 10, 11, 0, 0, 0, 0, time.UTC),
+		ProgressInterval: 5,
+		Progress: func(p Progress) {
+			progress = append(progress, p)
+		},
+	})
+	assert.NoError(t, err)
+	return imp, &progress
+}
@@ -6,7 +6,7 @@ import (
 )
 
 // Bot represents a visitor or event that has been ignored.
-// The creation time, User-Agent, path, and event name are stored in the database to find bots.
+// The creation time, User-Agent, path, event name, and the reason it has been ignored are stored in the database to find bots.
 type Bot struct {
 	ClientID  uint64    `db:"client_id" json:"client_id"`
 	VisitorID uint64    `db:"visitor_id" json:"visitor_id"`
@@ -14,6 +14,7 @@ type Bot struct {
 	UserAgent string    `db:"user_agent"`
 	Path      string    `json:"path"`
 	Event     string    `db:"event_name" json:"event"`
+	Reason    string    `json:"reason"`
 }
 
 // String implements the Stringer interface.
@@ -16,14 +16,19 @@ type Event struct {
 	MetaKeys        []string  `db:"event_meta_keys" json:"meta_keys"`
 	MetaValues      []string  `db:"event_meta_values" json:"meta_values"`
 	DurationSeconds uint32    `db:"duration_seconds" json:"duration_seconds"`
+	Hostname        string    `json:"hostname"`
 	Path            string    `json:"path"`
 	Title           string    `json:"title"`
 	Language        string    `json:"language"`
 	CountryCode     string    `db:"country_code" json:"country_code"`
+	Region          string    `json:"region"`
 	City            string    `json:"city"`
 	Referrer        string    `json:"referrer"`
 	ReferrerName    string    `db:"referrer_name" json:"referrer_name"`
 	ReferrerIcon    string    `db:"referrer_icon" json:"referrer_icon"`
+	Channel         string    `json:"channel"`
+	AdNetwork       string    `db:"ad_network" json:"ad_network"`
+	ConnectionType  string    `db:"connection_type" json:"connection_type"`
 	OS              string    `json:"os"`
 	OSVersion       string    `db:"os_version" json:"os_version"`
 	Browser         string    `json:"browser"`
@@ -36,6 +41,9 @@ type Event struct {
 	UTMCampaign     string    `db:"utm_campaign" json:"utm_campaign"`
 	UTMContent      string    `db:"utm_content" json:"utm_content"`
 	UTMTerm         string    `db:"utm_term" json:"utm_term"`
+
+	QueryParamKeys   []string `db:"query_param_keys" json:"query_param_keys"`
+	QueryParamValues []string `db:"query_param_values" json:"query_param_values"`
 }
 
 // String implements the Stringer interface.
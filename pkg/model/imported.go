@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+)
+
+// ImportedVisitors are the daily visitor statistics imported from another analytics tool.
+type ImportedVisitors struct {
+	ClientID        uint64    `db:"client_id" json:"client_id"`
+	Date            time.Time `json:"date"`
+	Visitors        int       `json:"visitors"`
+	Views           int       `json:"views"`
+	Sessions        int       `json:"sessions"`
+	Bounces         int       `json:"bounces"`
+	SessionDuration int       `db:"session_duration" json:"session_duration"`
+}
+
+// ImportedPage are the daily page statistics imported from another analytics tool.
+type ImportedPage struct {
+	ClientID uint64    `db:"client_id" json:"client_id"`
+	Date     time.Time `json:"date"`
+	Path     string    `json:"path"`
+	Visitors int       `json:"visitors"`
+	Views    int       `json:"views"`
+	Sessions int       `json:"sessions"`
+	Bounces  int       `json:"bounces"`
+}
+
+// ImportedReferrer are the daily referrer (source) statistics imported from another analytics tool.
+type ImportedReferrer struct {
+	ClientID uint64    `db:"client_id" json:"client_id"`
+	Date     time.Time `json:"date"`
+	Referrer string    `json:"referrer"`
+	Visitors int       `json:"visitors"`
+	Sessions int       `json:"sessions"`
+	Bounces  int       `json:"bounces"`
+}
+
+// ImportedCountry are the daily country statistics imported from another analytics tool.
+type ImportedCountry struct {
+	ClientID    uint64    `db:"client_id" json:"client_id"`
+	Date        time.Time `json:"date"`
+	CountryCode string    `db:"country_code" json:"country_code"`
+	Visitors    int       `json:"visitors"`
+}
+
+// ImportedDevice are the daily device (desktop, mobile, tablet) statistics imported from another analytics tool.
+type ImportedDevice struct {
+	ClientID uint64    `db:"client_id" json:"client_id"`
+	Date     time.Time `json:"date"`
+	Device   string    `json:"device"`
+	Visitors int       `json:"visitors"`
+}
@@ -12,14 +12,19 @@ type PageView struct {
 	SessionID       uint32    `db:"session_id" json:"session_id"`
 	Time            time.Time `json:"time"`
 	DurationSeconds uint32    `db:"duration_seconds" json:"duration_seconds"`
+	Hostname        string    `json:"hostname"`
 	Path            string    `json:"path"`
 	Title           string    `json:"title"`
 	Language        string    `json:"language"`
 	CountryCode     string    `db:"country_code" json:"country_code"`
+	Region          string    `json:"region"`
 	City            string    `json:"city"`
 	Referrer        string    `json:"referrer"`
 	ReferrerName    string    `db:"referrer_name" json:"referrer_name"`
 	ReferrerIcon    string    `db:"referrer_icon" json:"referrer_icon"`
+	Channel         string    `json:"channel"`
+	AdNetwork       string    `db:"ad_network" json:"ad_network"`
+	ConnectionType  string    `db:"connection_type" json:"connection_type"`
 	OS              string    `json:"os"`
 	OSVersion       string    `db:"os_version" json:"os_version"`
 	Browser         string    `json:"browser"`
@@ -32,6 +37,11 @@ type PageView struct {
 	UTMCampaign     string    `db:"utm_campaign" json:"utm_campaign"`
 	UTMContent      string    `db:"utm_content" json:"utm_content"`
 	UTMTerm         string    `db:"utm_term" json:"utm_term"`
+	SearchTerm      string    `db:"search_term" json:"search_term"`
+	StatusCode      uint16    `db:"status_code" json:"status_code"`
+
+	QueryParamKeys   []string `db:"query_param_keys" json:"query_param_keys"`
+	QueryParamValues []string `db:"query_param_values" json:"query_param_values"`
 }
 
 // String implements the Stringer interface.
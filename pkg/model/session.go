@@ -14,6 +14,7 @@ type Session struct {
 	Time            time.Time `json:"time"`
 	Start           time.Time `json:"start"`
 	DurationSeconds uint32    `db:"duration_seconds" json:"duration_seconds"`
+	Hostname        string    `json:"hostname"`
 	EntryPath       string    `db:"entry_path" json:"entry_path"`
 	ExitPath        string    `db:"exit_path" json:"exit_path"`
 	PageViews       uint16    `db:"page_views" json:"page_views"`
@@ -22,10 +23,14 @@ type Session struct {
 	ExitTitle       string    `db:"exit_title" json:"exit_title"`
 	Language        string    `json:"language"`
 	CountryCode     string    `db:"country_code" json:"country_code"`
+	Region          string    `json:"region"`
 	City            string    `json:"city"`
 	Referrer        string    `json:"referrer"`
 	ReferrerName    string    `db:"referrer_name" json:"referrer_name"`
 	ReferrerIcon    string    `db:"referrer_icon" json:"referrer_icon"`
+	Channel         string    `json:"channel"`
+	AdNetwork       string    `db:"ad_network" json:"ad_network"`
+	ConnectionType  string    `db:"connection_type" json:"connection_type"`
 	OS              string    `json:"os"`
 	OSVersion       string    `db:"os_version" json:"os_version"`
 	Browser         string    `json:"browser"`
@@ -39,6 +44,10 @@ type Session struct {
 	UTMContent      string    `db:"utm_content" json:"utm_content"`
 	UTMTerm         string    `db:"utm_term" json:"utm_term"`
 	Extended        uint16    `json:"extended"`
+
+	// ClickIDHash is the hash of the click ID the session was started with.
+	// It's only kept in the session cache to detect new ad clicks and not stored, so it's 0 for sessions loaded from the database.
+	ClickIDHash uint64 `db:"-" json:"click_id_hash,omitempty"`
 }
 
 // String implements the Stringer interface.
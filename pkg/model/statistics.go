@@ -147,6 +147,61 @@ type EventListStats struct {
 	Count    int               `json:"count"`
 }
 
+// BotStats is the result type for bot hits grouped by day, week, month, or year.
+type BotStats struct {
+	Day      null.Time `json:"day"`
+	Week     null.Time `json:"week"`
+	Month    null.Time `json:"month"`
+	Year     null.Time `json:"year"`
+	Visitors int       `json:"visitors"`
+	Hits     int       `json:"hits"`
+}
+
+// BotUserAgentStats is the result type for User-Agents of bots.
+type BotUserAgentStats struct {
+	UserAgent string `db:"user_agent" json:"user_agent"`
+	Visitors  int    `json:"visitors"`
+	Hits      int    `json:"hits"`
+}
+
+// BotPathStats is the result type for paths hit by bots.
+type BotPathStats struct {
+	Path     string `json:"path"`
+	Visitors int    `json:"visitors"`
+	Hits     int    `json:"hits"`
+}
+
+// BotEventStats is the result type for events sent by bots.
+type BotEventStats struct {
+	Name     string `db:"event_name" json:"name"`
+	Visitors int    `json:"visitors"`
+	Hits     int    `json:"hits"`
+}
+
+// SearchTermStats is the result type for site search terms.
+type SearchTermStats struct {
+	SearchTerm string `db:"search_term" json:"search_term"`
+	Visitors   int    `json:"visitors"`
+	Searches   int    `json:"searches"`
+}
+
+// SearchExitStats is the result type for site searches without a following page view.
+type SearchExitStats struct {
+	SearchTerm string  `db:"search_term" json:"search_term"`
+	Visitors   int     `json:"visitors"`
+	Searches   int     `json:"searches"`
+	Exits      int     `json:"exits"`
+	ExitRate   float64 `db:"exit_rate" json:"exit_rate"`
+}
+
+// SearchTransitionStats is the result type for pages viewed after a site search.
+type SearchTransitionStats struct {
+	SearchTerm string `db:"search_term" json:"search_term"`
+	Path       string `json:"path"`
+	Visitors   int    `json:"visitors"`
+	Views      int    `json:"views"`
+}
+
 // ReferrerStats is the result type for referrer statistics.
 type ReferrerStats struct {
 	Referrer         string  `json:"referrer"`
@@ -198,10 +253,36 @@ type CountryStats struct {
 	CountryCode string `db:"country_code" json:"country_code"`
 }
 
+// ContinentStats is the result type for continent statistics.
+type ContinentStats struct {
+	MetaStats
+	Continent string `json:"continent"`
+}
+
+// SubregionStats is the result type for subregion statistics.
+type SubregionStats struct {
+	MetaStats
+	Subregion string `json:"subregion"`
+}
+
+// EconomicRegionStats is the result type for economic region statistics.
+type EconomicRegionStats struct {
+	MetaStats
+	EconomicRegion string `db:"economic_region" json:"economic_region"`
+}
+
+// RegionStats is the result type for region statistics.
+type RegionStats struct {
+	MetaStats
+	CountryCode string `db:"country_code" json:"country_code"`
+	Region      string `json:"region"`
+}
+
 // CityStats is the result type for city statistics.
 type CityStats struct {
 	MetaStats
 	CountryCode string `db:"country_code" json:"country_code"`
+	Region      string `json:"region"`
 	City        string `json:"city"`
 }
 
@@ -237,6 +318,30 @@ type ScreenClassStats struct {
 	ScreenClass string `db:"screen_class" json:"screen_class"`
 }
 
+// HostnameStats is the result type for hostname statistics.
+type HostnameStats struct {
+	MetaStats
+	Hostname string `json:"hostname"`
+}
+
+// ChannelStats is the result type for marketing channel statistics.
+type ChannelStats struct {
+	MetaStats
+	Channel string `json:"channel"`
+}
+
+// ConnectionTypeStats is the result type for connection type statistics.
+type ConnectionTypeStats struct {
+	MetaStats
+	ConnectionType string `db:"connection_type" json:"connection_type"`
+}
+
+// QueryParamStats is the result type for query parameter statistics.
+type QueryParamStats struct {
+	MetaStats
+	Value string `db:"query_param_value" json:"value"`
+}
+
 // UTMSourceStats is the result type for utm source statistics.
 type UTMSourceStats struct {
 	MetaStats
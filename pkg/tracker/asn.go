@@ -0,0 +1,69 @@
+package tracker
+
+import (
+	"github.com/pirsch-analytics/pirsch/v6/pkg"
+	"regexp"
+	"slices"
+)
+
+var (
+	// DefaultHostingASNs is a list of autonomous system numbers of well-known cloud and hosting providers.
+	DefaultHostingASNs = []uint32{
+		14618,  // Amazon AWS
+		16509,  // Amazon AWS
+		15169,  // Google
+		396982, // Google Cloud
+		8075,   // Microsoft Azure
+		31898,  // Oracle Cloud
+		14061,  // DigitalOcean
+		24940,  // Hetzner
+		213230, // Hetzner Cloud
+		16276,  // OVH
+		63949,  // Akamai Linode
+		20473,  // Vultr
+		12876,  // Scaleway
+		51167,  // Contabo
+		45102,  // Alibaba Cloud
+		132203, // Tencent Cloud
+		36352,  // ColoCrossing
+	}
+
+	// DefaultHostingASOrganizations is a list of patterns matching the organization names of cloud and hosting providers.
+	DefaultHostingASOrganizations = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\b(amazon|aws|google cloud|azure|oracle cloud|digitalocean|hetzner|ovh|linode|vultr|choopa|scaleway|contabo|alibaba|tencent|colocrossing)\b`),
+		regexp.MustCompile(`(?i)\b(hosting|data ?cent(er|re)|dedicated servers?)\b`),
+	}
+)
+
+// matchASN returns whether the autonomous system number is in given list or the organization name matches one of the patterns.
+func matchASN(asns []uint32, organizations []*regexp.Regexp, asn uint32, organization string) bool {
+	if asn != 0 && slices.Contains(asns, asn) {
+		return true
+	}
+
+	if organization != "" {
+		for _, pattern := range organizations {
+			if pattern.MatchString(organization) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// getConnectionType returns the connection type for given request, or an empty string if no autonomous system is found.
+// The autonomous system is only looked up once per request, as the ASNRule might have done so already.
+func (tracker *Tracker) getConnectionType(req *Request) string {
+	asn, organization := req.ASN()
+
+	if asn == 0 && organization == "" {
+		return ""
+	}
+
+	if matchASN(tracker.config.HostingASNs, tracker.config.HostingASOrganizations, asn, organization) {
+		return pkg.ConnectionTypeHosting
+	}
+
+	return pkg.ConnectionTypeResidential
+}
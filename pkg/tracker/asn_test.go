@@ -0,0 +1,198 @@
+package tracker
+
+import (
+	"bytes"
+	"github.com/pirsch-analytics/pirsch/v6/pkg"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/geodb"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync/atomic"
+	"testing"
+)
+
+type asnLookupMock map[string]struct {
+	asn          uint32
+	organization string
+}
+
+func (lookup asnLookupMock) GetASN(ip string) (uint32, string) {
+	as := lookup[ip]
+	return as.asn, as.organization
+}
+
+type asnLookupCounter struct {
+	geodb.ASNLookup
+	lookups atomic.Int32
+}
+
+func (lookup *asnLookupCounter) GetASN(ip string) (uint32, string) {
+	lookup.lookups.Add(1)
+	return lookup.ASNLookup.GetASN(ip)
+}
+
+var testASNDB = asnLookupMock{
+	"3.5.140.2":    {16509, "AMAZON-02"},
+	"5.9.1.1":      {24940, "Hetzner Online GmbH"},
+	"45.33.1.1":    {1234, "Example Hosting Ltd"},
+	"84.128.1.1":   {3320, "Deutsche Telekom AG"},
+	"94.130.1.1":   {0, "Hetzner Online GmbH"},
+	"203.0.113.10": {0, ""},
+}
+
+func TestMatchASN(t *testing.T) {
+	organizations := []*regexp.Regexp{regexp.MustCompile(`(?i)hetzner`)}
+	assert.True(t, matchASN([]uint32{16509}, nil, 16509, "AMAZON-02"))
+	assert.True(t, matchASN(nil, organizations, 24940, "Hetzner Online GmbH"))
+	assert.False(t, matchASN([]uint32{16509}, organizations, 3320, "Deutsche Telekom AG"))
+	assert.False(t, matchASN([]uint32{0}, nil, 0, ""))
+	assert.False(t, matchASN(nil, nil, 16509, "AMAZON-02"))
+}
+
+func TestTracker_ignoreASN(t *testing.T) {
+	client := db.NewClientMock()
+	tracker := NewTracker(Config{
+		Store:                 client,
+		ASNDB:                 testASNDB,
+		IgnoreASNs:            []uint32{16509},
+		IgnoreASOrganizations: []*regexp.Regexp{regexp.MustCompile(`(?i)hetzner`)},
+	})
+
+	for _, remoteAddr := range []string{"84.128.1.1", "3.5.140.2", "5.9.1.1", "94.130.1.1", "45.33.1.1"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("User-Agent", userAgent)
+		req.RemoteAddr = remoteAddr
+		tracker.PageView(req, 0, Options{})
+	}
+
+	tracker.Stop()
+	pageViews := client.GetPageViews()
+	assert.Len(t, pageViews, 2)
+	bots := client.GetBots()
+	assert.Len(t, bots, 3)
+
+	for _, bot := range bots {
+		assert.Equal(t, ReasonASN, bot.Reason)
+	}
+
+	// the rule is not added without an ASN configured
+	tracker = NewTracker(Config{ASNDB: testASNDB})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", userAgent)
+	req.RemoteAddr = "3.5.140.2"
+	_, reason := tracker.ignore(req, 0, nil, nil)
+	assert.Empty(t, reason)
+	tracker.Stop()
+
+	// the rule is ignored without an ASNDB
+	tracker = NewTracker(Config{Rules: []Rule{ASNRule{ASNs: []uint32{16509}}}})
+	_, reason = tracker.ignore(req, 0, nil, nil)
+	assert.Empty(t, reason)
+	tracker.Stop()
+
+	// a warning is logged in case ASNs are ignored without an ASNDB
+	var buffer bytes.Buffer
+	tracker = NewTracker(Config{
+		IgnoreASNs: []uint32{16509},
+		Logger:     slog.New(slog.NewTextHandler(&buffer, nil)),
+	})
+	tracker.Stop()
+	assert.Contains(t, buffer.String(), "without an ASNDB")
+}
+
+func TestTracker_PageViewASNLookup(t *testing.T) {
+	client := db.NewClientMock()
+	lookup := &asnLookupCounter{ASNLookup: testASNDB}
+	tracker := NewTracker(Config{
+		Store:      client,
+		ASNDB:      lookup,
+		IgnoreASNs: []uint32{1},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", userAgent)
+	req.RemoteAddr = "3.5.140.2"
+	tracker.PageView(req, 0, Options{})
+	tracker.Stop()
+	pageViews := client.GetPageViews()
+	assert.Len(t, pageViews, 1)
+	assert.Equal(t, pkg.ConnectionTypeHosting, pageViews[0].ConnectionType)
+	assert.Equal(t, int32(1), lookup.lookups.Load())
+}
+
+func TestTracker_PageViewConnectionType(t *testing.T) {
+	client := db.NewClientMock()
+	tracker := NewTracker(Config{
+		Store: client,
+		ASNDB: testASNDB,
+	})
+
+	for _, remoteAddr := range []string{"3.5.140.2", "45.33.1.1", "84.128.1.1", "203.0.113.10"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("User-Agent", userAgent)
+		req.RemoteAddr = remoteAddr
+		tracker.PageView(req, 0, Options{})
+		tracker.Event(req, 0, EventOptions{Name: "event"}, Options{})
+	}
+
+	tracker.Stop()
+	pageViews := client.GetPageViews()
+	events := client.GetEvents()
+	assert.Len(t, pageViews, 4)
+	assert.Len(t, events, 4)
+	expected := map[uint64]string{}
+
+	for _, s := range client.GetSessions() {
+		expected[s.VisitorID] = s.ConnectionType
+	}
+
+	assert.Len(t, expected, 4)
+	hosting, residential, unknown := 0, 0, 0
+
+	for _, connectionType := range expected {
+		switch connectionType {
+		case pkg.ConnectionTypeHosting:
+			hosting++
+		case pkg.ConnectionTypeResidential:
+			residential++
+		case "":
+			unknown++
+		}
+	}
+
+	assert.Equal(t, 2, hosting)
+	assert.Equal(t, 1, residential)
+	assert.Equal(t, 1, unknown)
+
+	for _, pv := range pageViews {
+		assert.Equal(t, expected[pv.VisitorID], pv.ConnectionType)
+	}
+
+	for _, e := range events {
+		assert.Equal(t, expected[e.VisitorID], e.ConnectionType)
+	}
+
+	// custom hosting providers
+	client = db.NewClientMock()
+	tracker = NewTracker(Config{
+		Store:                  client,
+		ASNDB:                  testASNDB,
+		HostingASNs:            []uint32{3320},
+		HostingASOrganizations: []*regexp.Regexp{},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", userAgent)
+	req.RemoteAddr = "84.128.1.1"
+	tracker.PageView(req, 0, Options{})
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", userAgent)
+	req.RemoteAddr = "45.33.1.1"
+	tracker.PageView(req, 0, Options{})
+	tracker.Stop()
+	pageViews = client.GetPageViews()
+	assert.Len(t, pageViews, 2)
+	connectionTypes := []string{pageViews[0].ConnectionType, pageViews[1].ConnectionType}
+	assert.ElementsMatch(t, []string{pkg.ConnectionTypeHosting, pkg.ConnectionTypeResidential}, connectionTypes)
+}
@@ -0,0 +1,108 @@
+package tracker
+
+import (
+	"github.com/pirsch-analytics/pirsch/v6/pkg/model"
+)
+
+// Batch is a set of sessions, page views, events, user agents, and bots that are saved to the Store together.
+type Batch struct {
+	Sessions   []model.Session   `json:"sessions,omitempty"`
+	PageViews  []model.PageView  `json:"page_views,omitempty"`
+	Events     []model.Event     `json:"events,omitempty"`
+	UserAgents []model.UserAgent `json:"user_agents,omitempty"`
+	Bots       []model.Bot       `json:"bots,omitempty"`
+
+	walRefs []walRef
+}
+
+func newBatch(bufferSize int) *Batch {
+	return &Batch{
+		Sessions:   make([]model.Session, 0, bufferSize*2),
+		PageViews:  make([]model.PageView, 0, bufferSize),
+		Events:     make([]model.Event, 0, bufferSize),
+		UserAgents: make([]model.UserAgent, 0, bufferSize),
+		Bots:       make([]model.Bot, 0, bufferSize),
+	}
+}
+
+func (batch *Batch) add(data data) {
+	if data.cancelSession != nil {
+		batch.Sessions = append(batch.Sessions, *data.cancelSession)
+	}
+
+	if data.session != nil {
+		batch.Sessions = append(batch.Sessions, *data.session)
+	}
+
+	if data.pageView != nil {
+		batch.PageViews = append(batch.PageViews, *data.pageView)
+	}
+
+	if data.event != nil {
+		batch.Events = append(batch.Events, *data.event)
+	}
+
+	if data.ua != nil {
+		batch.UserAgents = append(batch.UserAgents, *data.ua)
+	}
+
+	if data.bot != nil {
+		batch.Bots = append(batch.Bots, *data.bot)
+	}
+
+	if data.walRef.seq != 0 {
+		batch.walRefs = append(batch.walRefs, data.walRef)
+	}
+}
+
+// items returns the batch as a list of data items, one for each session, page view, event, user agent, and bot.
+func (batch *Batch) items() []data {
+	items := make([]data, 0, len(batch.Sessions)+len(batch.PageViews)+len(batch.Events)+len(batch.UserAgents)+len(batch.Bots))
+
+	for i := range batch.Sessions {
+		items = append(items, data{session: &batch.Sessions[i]})
+	}
+
+	for i := range batch.PageViews {
+		items = append(items, data{pageView: &batch.PageViews[i]})
+	}
+
+	for i := range batch.Events {
+		items = append(items, data{event: &batch.Events[i]})
+	}
+
+	for i := range batch.UserAgents {
+		items = append(items, data{ua: &batch.UserAgents[i]})
+	}
+
+	for i := range batch.Bots {
+		items = append(items, data{bot: &batch.Bots[i]})
+	}
+
+	return items
+}
+
+func (batch *Batch) full(bufferSize int) bool {
+	return len(batch.Sessions)+2 >= bufferSize*2 ||
+		len(batch.PageViews)+1 >= bufferSize ||
+		len(batch.Events)+1 >= bufferSize ||
+		len(batch.UserAgents)+1 >= bufferSize ||
+		len(batch.Bots)+1 >= bufferSize
+}
+
+func (batch *Batch) empty() bool {
+	return len(batch.Sessions) == 0 &&
+		len(batch.PageViews) == 0 &&
+		len(batch.Events) == 0 &&
+		len(batch.UserAgents) == 0 &&
+		len(batch.Bots) == 0
+}
+
+func (batch *Batch) reset() {
+	batch.Sessions = batch.Sessions[:0]
+	batch.PageViews = batch.PageViews[:0]
+	batch.Events = batch.Events[:0]
+	batch.UserAgents = batch.UserAgents[:0]
+	batch.Bots = batch.Bots[:0]
+	batch.walRefs = batch.walRefs[:0]
+}
@@ -0,0 +1,102 @@
+package tracker
+
+import (
+	"net/url"
+	"strings"
+)
+
+// CampaignParams maps query parameters to the referrer and UTM parameters of sessions.
+// Each field is a list of query parameter names checked in order, the first non-empty parameter is used.
+// Fields set to nil use the DefaultCampaignParams.
+type CampaignParams struct {
+	// Referrer is a list of query parameters used to set the referrer in case the Referer header is not set.
+	// The Source parameters are checked after the Referrer parameters.
+	Referrer []string
+
+	// Source is a list of query parameters used for the utm_source, like "mtm_source".
+	Source []string
+
+	// Medium is a list of query parameters used for the utm_medium, like "mtm_medium".
+	Medium []string
+
+	// Campaign is a list of query parameters used for the utm_campaign, like "campaign" or "mtm_campaign".
+	Campaign []string
+
+	// Content is a list of query parameters used for the utm_content, like "mtm_content".
+	Content []string
+
+	// Term is a list of query parameters used for the utm_term, like "mtm_keyword".
+	Term []string
+}
+
+// DefaultCampaignParams are the query parameters used for the referrer and UTM parameters by default.
+var DefaultCampaignParams = CampaignParams{
+	Referrer: []string{"ref", "referer", "referrer", "source"},
+	Source:   []string{"utm_source"},
+	Medium:   []string{"utm_medium"},
+	Campaign: []string{"utm_campaign"},
+	Content:  []string{"utm_content"},
+	Term:     []string{"utm_term"},
+}
+
+type campaign struct {
+	source   string
+	medium   string
+	campaign string
+	content  string
+	term     string
+}
+
+func (params *CampaignParams) validate() {
+	if params.Referrer == nil {
+		params.Referrer = DefaultCampaignParams.Referrer
+	}
+
+	if params.Source == nil {
+		params.Source = DefaultCampaignParams.Source
+	}
+
+	if params.Medium == nil {
+		params.Medium = DefaultCampaignParams.Medium
+	}
+
+	if params.Campaign == nil {
+		params.Campaign = DefaultCampaignParams.Campaign
+	}
+
+	if params.Content == nil {
+		params.Content = DefaultCampaignParams.Content
+	}
+
+	if params.Term == nil {
+		params.Term = DefaultCampaignParams.Term
+	}
+}
+
+// referrerParams returns the query parameters passed to referrer.Get and referrer.Ignore.
+func (params *CampaignParams) referrerParams() []string {
+	list := make([]string, 0, len(params.Referrer)+len(params.Source))
+	list = append(list, params.Referrer...)
+	return append(list, params.Source...)
+}
+
+// campaign returns the UTM parameters for given query.
+func (params *CampaignParams) campaign(query url.Values) campaign {
+	return campaign{
+		source:   params.get(query, params.Source),
+		medium:   params.get(query, params.Medium),
+		campaign: params.get(query, params.Campaign),
+		content:  params.get(query, params.Content),
+		term:     params.get(query, params.Term),
+	}
+}
+
+func (params *CampaignParams) get(query url.Values, keys []string) string {
+	for _, key := range keys {
+		if value := strings.TrimSpace(query.Get(key)); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
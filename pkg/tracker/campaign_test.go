@@ -0,0 +1,119 @@
+package tracker
+
+import (
+	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestCampaignParams_validate(t *testing.T) {
+	params := CampaignParams{
+		Source: []string{"mtm_source"},
+		Term:   []string{},
+	}
+	params.validate()
+	assert.Equal(t, DefaultCampaignParams.Referrer, params.Referrer)
+	assert.Equal(t, []string{"mtm_source"}, params.Source)
+	assert.Equal(t, DefaultCampaignParams.Medium, params.Medium)
+	assert.Equal(t, DefaultCampaignParams.Campaign, params.Campaign)
+	assert.Equal(t, DefaultCampaignParams.Content, params.Content)
+	assert.Empty(t, params.Term)
+	assert.Equal(t, []string{"ref", "referer", "referrer", "source", "mtm_source"}, params.referrerParams())
+	params = CampaignParams{}
+	params.validate()
+	assert.Equal(t, []string{"ref", "referer", "referrer", "source", "utm_source"}, params.referrerParams())
+}
+
+func TestCampaignParams_campaign(t *testing.T) {
+	params := CampaignParams{
+		Source:   []string{"utm_source", "mtm_source"},
+		Medium:   []string{"utm_medium", "mtm_medium"},
+		Campaign: []string{"utm_campaign", "mtm_campaign", "campaign"},
+		Content:  []string{"utm_content", "mtm_content"},
+		Term:     []string{"utm_term", "mtm_keyword"},
+	}
+	utm := params.campaign(url.Values{
+		"utm_source":   {" "},
+		"mtm_source":   {"Newsletter"},
+		"mtm_medium":   {" email "},
+		"campaign":     {"Summer"},
+		"mtm_campaign": {"Winter"},
+		"mtm_content":  {"Banner"},
+		"mtm_keyword":  {"shoes"},
+	})
+	assert.Equal(t, "Newsletter", utm.source)
+	assert.Equal(t, "email", utm.medium)
+	assert.Equal(t, "Winter", utm.campaign)
+	assert.Equal(t, "Banner", utm.content)
+	assert.Equal(t, "shoes", utm.term)
+	assert.Equal(t, campaign{}, params.campaign(url.Values{}))
+}
+
+func TestTracker_PageViewCampaignParams(t *testing.T) {
+	client := db.NewClientMock()
+	tracker := NewTracker(Config{
+		Store: client,
+		CampaignParams: CampaignParams{
+			Source:   []string{"utm_source", "mtm_source"},
+			Campaign: []string{"utm_campaign", "mtm_campaign", "campaign"},
+		},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/?mtm_source=Partner&campaign=Summer", nil)
+	req.Header.Set("User-Agent", userAgent)
+	tracker.PageView(req, 0, Options{})
+	req = httptest.NewRequest(http.MethodGet, "/foo?campaign=Summer", nil)
+	req.Header.Set("User-Agent", userAgent)
+	tracker.PageView(req, 0, Options{})
+	req = httptest.NewRequest(http.MethodGet, "/?mtm_campaign=Winter", nil)
+	req.Header.Set("User-Agent", userAgent)
+	tracker.PageView(req, 0, Options{})
+	tracker.Stop()
+	pageViews := client.GetPageViews()
+	assert.Len(t, pageViews, 3)
+	assert.Equal(t, "Partner", pageViews[0].UTMSource)
+	assert.Equal(t, "Partner", pageViews[0].ReferrerName)
+	assert.Equal(t, "Summer", pageViews[0].UTMCampaign)
+	assert.Equal(t, pageViews[0].SessionID, pageViews[1].SessionID)
+	assert.NotEqual(t, pageViews[0].SessionID, pageViews[2].SessionID)
+	assert.Equal(t, "Winter", pageViews[2].UTMCampaign)
+}
+
+func TestTracker_referrerOrCampaignChangedCampaignParams(t *testing.T) {
+	tracker := NewTracker(Config{
+		CampaignParams: CampaignParams{
+			Referrer: []string{},
+			Medium:   []string{"mtm_medium"},
+		},
+	})
+	s := &model.Session{UTMMedium: "email"}
+	req := httptest.NewRequest(http.MethodGet, "/test?ref=https://referrer.com&utm_medium=cpc", nil)
+	assert.False(t, tracker.referrerOrCampaignChanged(req, s, "", "", req.URL.Query()))
+	req = httptest.NewRequest(http.MethodGet, "/test?mtm_medium=email", nil)
+	assert.False(t, tracker.referrerOrCampaignChanged(req, s, "", "", req.URL.Query()))
+	req = httptest.NewRequest(http.MethodGet, "/test?mtm_medium=cpc", nil)
+	assert.True(t, tracker.referrerOrCampaignChanged(req, s, "", "", req.URL.Query()))
+}
+
+func TestTracker_PageViewReferrerFromOptionsURL(t *testing.T) {
+	client := db.NewClientMock()
+	tracker := NewTracker(Config{
+		Store: client,
+	})
+	req := httptest.NewRequest(http.MethodGet, "/pixel?ref=https://ignored.com", nil)
+	req.Header.Set("User-Agent", userAgent)
+	tracker.PageView(req, 0, Options{URL: "https://example.com/?ref=https://www.google.com/&utm_medium=cpc"})
+	req = httptest.NewRequest(http.MethodGet, "/pixel", nil)
+	req.Header.Set("User-Agent", userAgent)
+	tracker.PageView(req, 0, Options{URL: "https://example.com/?ref=https://semalt.com/"})
+	tracker.Stop()
+	sessions := client.GetSessions()
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, "https://www.google.com", sessions[0].Referrer)
+	assert.Equal(t, "Google", sessions[0].ReferrerName)
+	assert.Equal(t, "cpc", sessions[0].UTMMedium)
+	assert.Equal(t, uint64(1), tracker.Stats().Ignored[ReasonReferrerSpam])
+}
@@ -4,20 +4,40 @@ import (
 	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
 	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/geodb"
 	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/ip"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/referrer"
 	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/session"
 	"github.com/pirsch-analytics/pirsch/v6/pkg/util"
 	"log/slog"
 	"net"
 	"os"
+	"regexp"
 	"runtime"
 	"time"
 )
 
 const (
-	defaultWorkerBufferSize = 500
-	defaultWorkerTimeout    = time.Second * 5
-	maxWorkerTimeout        = time.Second * 60
-	defaultMaxPageViews     = uint16(200)
+	defaultWorkerBufferSize    = 500
+	defaultWorkerTimeout       = time.Second * 5
+	maxWorkerTimeout           = time.Second * 60
+	defaultMaxPageViews        = uint16(200)
+	defaultStoreRetries        = 3
+	defaultStoreRetryBackoff   = time.Millisecond * 500
+	maxStoreRetryBackoff       = time.Second * 30
+	defaultBackPressureTimeout = time.Second
+)
+
+// BackPressure is the policy used by the Tracker in case the buffer of the workers is full.
+type BackPressure int
+
+const (
+	// BackPressureBlock blocks until there is space in the buffer (default).
+	BackPressureBlock = BackPressure(iota)
+
+	// BackPressureDrop drops the newest data immediately if the buffer is full.
+	BackPressureDrop
+
+	// BackPressureTimeout blocks until there is space in the buffer or the Config.BackPressureTimeout has been reached, in which case the data is dropped.
+	BackPressureTimeout
 )
 
 // Config is the configuration for the Tracker.
@@ -33,9 +53,92 @@ type Config struct {
 	HeaderParser        []ip.HeaderParser
 	AllowedProxySubnets []net.IPNet
 	MaxPageViews        uint16
-	GeoDB               *geodb.GeoDB
+	GeoDB               geodb.Locator
 	IPFilter            ip.Filter
 	Logger              *slog.Logger
+
+	// Rules decide whether a request is ignored and stored as a bot. They are applied in order.
+	// If nil, the DefaultRules are used, including the IPFilter and an ASNRule for the IgnoreASNs and IgnoreASOrganizations.
+	// Setting Rules replaces all of them, so the IPFilter and ASNRule must be added manually,
+	// like append(DefaultRules(filter), ASNRule{...}, customRule).
+	Rules []Rule
+
+	// StoreRetries sets the number of times saving a batch to the Store is retried before giving up.
+	// If set to 0, the default value of 3 will be used. Set it to a negative value to disable retries.
+	StoreRetries int
+
+	// StoreRetryBackoff sets the time to wait before the first retry. It doubles on each attempt, up to 30 seconds.
+	// If set to <= 0, the default value of 500ms will be used.
+	StoreRetryBackoff time.Duration
+
+	// SpoolDir sets the directory batches are written to if they cannot be saved after all retries.
+	// Spooled batches are replayed once the Store accepts writes again. Spooling is disabled if empty.
+	SpoolDir string
+
+	// OnStoreError is called with the part of a batch that could not be saved after all retries,
+	// in case it cannot be spooled. If nil, the batch is dropped and the error is logged.
+	OnStoreError func(Batch, error)
+
+	// WALDir sets the directory for the write-ahead log. If set, all data is written to disk before it is buffered,
+	// and replayed by NewTracker in case it hasn't been saved before the process was stopped. The WAL is disabled if empty.
+	WALDir string
+
+	// BackPressure sets the policy for PageView, Event, and ExtendSession in case the buffer is full, because the Store is slow.
+	// Dropped data is counted and can be read using Tracker.Dropped.
+	BackPressure BackPressure
+
+	// BackPressureTimeout sets the maximum time to wait for space in the buffer when using BackPressureTimeout.
+	// If set to <= 0, the default value of one second will be used.
+	BackPressureTimeout time.Duration
+
+	// Settings provides the ClientSettings, like the session timeout, for each client.
+	// If nil, the same default settings are used for all clients.
+	Settings SettingsProvider
+
+	// SettingsCacheTTL sets how long the ClientSettings are cached before they are requested from the SettingsProvider again.
+	// If set to <= 0, the default value of five minutes will be used.
+	SettingsCacheTTL time.Duration
+
+	// PathRules normalize the path of page views and events before they are stored, like lowercasing or removing trailing slashes.
+	// They can be overridden for each client using ClientSettings.PathRules.
+	PathRules PathRules
+
+	// QueryParams is a list of query parameter names stored for page views and events, like "q" or "variant".
+	// All other query parameters (except for the UTM parameters) are discarded.
+	// It can be overridden for each client using ClientSettings.QueryParams.
+	QueryParams []string
+
+	// SearchParams is a list of query parameter names used for the site search, like "q" or "query".
+	// The first non-empty parameter is stored as the normalized search term for page views.
+	// It can be overridden for each client using ClientSettings.SearchParams.
+	SearchParams []string
+
+	// ChannelRules are used to classify the channel of sessions, like "Paid Search" or "Social".
+	// They are applied before the referrer.DefaultChannelRules and can be overridden for each client using ClientSettings.ChannelRules.
+	ChannelRules []referrer.ChannelRule
+
+	// CampaignParams maps query parameters to the referrer and UTM parameters, like "mtm_campaign" to the utm_campaign.
+	// Fields set to nil use the DefaultCampaignParams.
+	CampaignParams CampaignParams
+
+	// ASNDB looks up the autonomous system of visitors, like a geodb.MMDB for a GeoLite2-ASN database.
+	// It's required to ignore hits by autonomous system and to store the connection type (hosting or residential) of sessions.
+	ASNDB geodb.ASNLookup
+
+	// IgnoreASNs is a list of autonomous system numbers to ignore, like 16509 for AWS. Ignored hits are stored as bots with ReasonASN.
+	// Unless Rules are set, an ASNRule is added to the DefaultRules if this or IgnoreASOrganizations is set.
+	IgnoreASNs []uint32
+
+	// IgnoreASOrganizations is a list of patterns for autonomous system organization names to ignore, like "(?i)hetzner".
+	IgnoreASOrganizations []*regexp.Regexp
+
+	// HostingASNs is a list of autonomous system numbers of hosting providers, used to store the connection type of sessions.
+	// If nil, the DefaultHostingASNs will be used.
+	HostingASNs []uint32
+
+	// HostingASOrganizations is a list of patterns for organization names of hosting providers, used to store the connection type of sessions.
+	// If nil, the DefaultHostingASOrganizations will be used.
+	HostingASOrganizations []*regexp.Regexp
 }
 
 func (config *Config) validate() {
@@ -73,7 +176,50 @@ func (config *Config) validate() {
 		config.MaxPageViews = defaultMaxPageViews
 	}
 
+	if config.Rules == nil {
+		config.Rules = DefaultRules(config.IPFilter)
+
+		if len(config.IgnoreASNs) > 0 || len(config.IgnoreASOrganizations) > 0 {
+			config.Rules = append(config.Rules, ASNRule{
+				ASNs:          config.IgnoreASNs,
+				Organizations: config.IgnoreASOrganizations,
+			})
+		}
+	}
+
+	if config.HostingASNs == nil {
+		config.HostingASNs = DefaultHostingASNs
+	}
+
+	if config.HostingASOrganizations == nil {
+		config.HostingASOrganizations = DefaultHostingASOrganizations
+	}
+
+	if config.StoreRetries == 0 {
+		config.StoreRetries = defaultStoreRetries
+	} else if config.StoreRetries < 0 {
+		config.StoreRetries = 0
+	}
+
+	if config.StoreRetryBackoff <= 0 {
+		config.StoreRetryBackoff = defaultStoreRetryBackoff
+	}
+
+	if config.BackPressureTimeout <= 0 {
+		config.BackPressureTimeout = defaultBackPressureTimeout
+	}
+
+	config.CampaignParams.validate()
+
+	if config.SettingsCacheTTL <= 0 {
+		config.SettingsCacheTTL = defaultSettingsCacheTTL
+	}
+
 	if config.Logger == nil {
 		config.Logger = slog.New(slog.NewTextHandler(os.Stdout, nil))
 	}
+
+	if config.ASNDB == nil && (len(config.IgnoreASNs) > 0 || len(config.IgnoreASOrganizations) > 0) {
+		config.Logger.Warn("IgnoreASNs and IgnoreASOrganizations have no effect without an ASNDB")
+	}
 }
@@ -17,6 +17,11 @@ func TestConfig_validate(t *testing.T) {
 	assert.Equal(t, defaultWorkerTimeout, cfg.WorkerTimeout)
 	assert.NotNil(t, cfg.SessionCache)
 	assert.NotNil(t, cfg.Logger)
+	assert.Len(t, cfg.Rules, 7)
+	assert.Equal(t, defaultStoreRetries, cfg.StoreRetries)
+	assert.Equal(t, defaultStoreRetryBackoff, cfg.StoreRetryBackoff)
+	assert.Equal(t, BackPressureBlock, cfg.BackPressure)
+	assert.Equal(t, defaultBackPressureTimeout, cfg.BackPressureTimeout)
 	cfg.WorkerTimeout = time.Second * 999
 	cfg.validate()
 	assert.Equal(t, maxWorkerTimeout, cfg.WorkerTimeout)
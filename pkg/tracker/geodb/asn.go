@@ -0,0 +1,65 @@
+package geodb
+
+import (
+	"github.com/oschwald/maxminddb-golang"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ASNLookup looks up the autonomous system (AS) of IP addresses.
+type ASNLookup interface {
+	// GetASN returns the autonomous system number and organization name for given IP, like 16509 and "AMAZON-02".
+	// 0 and an empty string are returned in case the IP is invalid or cannot be found.
+	GetASN(ip string) (uint32, string)
+}
+
+// asnRecord covers the layout of MaxMind GeoLite2-ASN and DB-IP ASN databases, as well as the flat layout used by IPinfo.
+type asnRecord struct {
+	Number       uint32 `maxminddb:"autonomous_system_number"`
+	Organization string `maxminddb:"autonomous_system_organization"`
+	ASN          string `maxminddb:"asn"`
+	Name         string `maxminddb:"name"`
+	ASName       string `maxminddb:"as_name"`
+}
+
+// get returns the autonomous system number and organization name.
+func (record *asnRecord) get() (uint32, string) {
+	if record.Number != 0 || record.Organization != "" {
+		return record.Number, record.Organization
+	}
+
+	var number uint32
+	asn := strings.TrimSpace(record.ASN)
+
+	if len(asn) > 2 && strings.EqualFold(asn[:2], "AS") {
+		asn = asn[2:]
+	}
+
+	if n, err := strconv.ParseUint(asn, 10, 32); err == nil {
+		number = uint32(n)
+	}
+
+	if record.Name != "" {
+		return number, record.Name
+	}
+
+	return number, record.ASName
+}
+
+// lookupASN returns the autonomous system number and organization name for given IP from the database.
+func lookupASN(db *maxminddb.Reader, ip string) (uint32, string) {
+	parsedIP := net.ParseIP(ip)
+
+	if db == nil || parsedIP == nil {
+		return 0, ""
+	}
+
+	var record asnRecord
+
+	if err := db.Lookup(parsedIP, &record); err != nil {
+		return 0, ""
+	}
+
+	return record.get()
+}
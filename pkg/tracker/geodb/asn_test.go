@@ -0,0 +1,48 @@
+package geodb
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestASNRecord(t *testing.T) {
+	input := []asnRecord{
+		{Number: 16509, Organization: "AMAZON-02"},
+		{ASN: "AS24940", Name: "Hetzner Online GmbH"},
+		{ASN: "as15169", ASName: "Google LLC"},
+		{ASN: "396982"},
+		{ASN: "invalid", Name: "Unknown"},
+		{},
+	}
+	expected := []struct {
+		number       uint32
+		organization string
+	}{
+		{16509, "AMAZON-02"},
+		{24940, "Hetzner Online GmbH"},
+		{15169, "Google LLC"},
+		{396982, ""},
+		{0, "Unknown"},
+		{0, ""},
+	}
+
+	for i, in := range input {
+		number, organization := in.get()
+		assert.Equal(t, expected[i].number, number)
+		assert.Equal(t, expected[i].organization, organization)
+	}
+}
+
+func TestMMDB_GetASN(t *testing.T) {
+	db, err := NewMMDB(MMDBConfig{Path: testMMDB})
+	assert.NoError(t, err)
+	var _ ASNLookup = db
+
+	// the city database doesn't contain any autonomous systems
+	asn, organization := db.GetASN("81.2.69.142")
+	assert.Zero(t, asn)
+	assert.Empty(t, organization)
+	asn, organization = db.GetASN("invalid")
+	assert.Zero(t, asn)
+	assert.Empty(t, organization)
+}
@@ -23,6 +23,7 @@ const (
 )
 
 // GeoDB maps IPs to their geological location based on MaxMinds GeoLite2 or GeoIP2 database.
+// It downloads the GeoLite2 City database using a license key. Use MMDB to load any other .mmdb file.
 type GeoDB struct {
 	licenseKey   string
 	downloadPath string
@@ -46,34 +47,21 @@ func NewGeoDB(licenseKey, downloadPath string) (*GeoDB, error) {
 	return geoDB, nil
 }
 
-// GetLocation looks up the country code, subdivision (region), and city for given IP.
-// If the IP is invalid it will return an empty string.
-// The country code is returned in lowercase.
+// GetLocation returns the country code (in lowercase) and city for given IP.
+// For the US, the ISO code of the state is appended to the city, like "Milton (WA)".
+// Use GetLocationWithRegion to look up the region separately.
 func (db *GeoDB) GetLocation(ip string) (string, string) {
 	parsedIP := net.ParseIP(ip)
 
-	if parsedIP == nil {
+	if db == nil || parsedIP == nil {
 		return "", ""
 	}
 
-	record := struct {
-		Country struct {
-			ISOCode string `maxminddb:"iso_code"`
-		} `maxminddb:"country"`
-		Subdivisions []struct {
-			ISOCode string `maxminddb:"iso_code"`
-		} `maxminddb:"subdivisions"`
-		City struct {
-			Names struct {
-				En string `maxminddb:"en"`
-			} `maxminddb:"names"`
-		} `maxminddb:"city"`
-	}{}
-
 	db.m.RLock()
 	defer db.m.RUnlock()
+	var record maxMindRecord
 
-	if err := db.db.Lookup(parsedIP, &record); err != nil {
+	if db.db == nil || db.db.Lookup(parsedIP, &record) != nil {
 		return "", ""
 	}
 
@@ -84,6 +72,18 @@ func (db *GeoDB) GetLocation(ip string) (string, string) {
 	return strings.ToLower(record.Country.ISOCode), record.City.Names.En
 }
 
+// GetLocationWithRegion implements the Locator interface.
+// It's safe to call on a nil GeoDB, so that a nil *GeoDB can be passed as a Locator.
+func (db *GeoDB) GetLocationWithRegion(ip string) (string, string, string) {
+	if db == nil {
+		return "", "", ""
+	}
+
+	db.m.RLock()
+	defer db.m.RUnlock()
+	return lookup(db.db, FormatMaxMind, ip)
+}
+
 // Update downloads and unpacks the MaxMind GeoLite2 database.
 func (db *GeoDB) Update() error {
 	if err := db.download(); err != nil {
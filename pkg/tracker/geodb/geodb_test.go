@@ -24,8 +24,44 @@ func TestGet(t *testing.T) {
 
 func TestGeoDB_GetLocation(t *testing.T) {
 	geoDB, _ := NewGeoDB("", "")
-	assert.NoError(t, geoDB.UpdateFromFile("../../../test/GeoIP2-City-Test.mmdb"))
 	countryCode, city := geoDB.GetLocation("81.2.69.142")
+	assert.Empty(t, countryCode)
+	assert.Empty(t, city)
+	assert.NoError(t, geoDB.UpdateFromFile("../../../test/GeoIP2-City-Test.mmdb"))
+	countryCode, city = geoDB.GetLocation("81.2.69.142")
+	assert.Equal(t, "gb", countryCode)
+	assert.Equal(t, "London", city)
+	countryCode, city = geoDB.GetLocation("216.160.83.56")
+	assert.Equal(t, "us", countryCode)
+	assert.Equal(t, "Milton (WA)", city)
+	countryCode, city = geoDB.GetLocation("invalid")
+	assert.Empty(t, countryCode)
+	assert.Empty(t, city)
+	geoDB = nil
+	countryCode, city = geoDB.GetLocation("81.2.69.142")
+	assert.Empty(t, countryCode)
+	assert.Empty(t, city)
+}
+
+func TestGeoDB_GetLocationWithRegion(t *testing.T) {
+	geoDB, _ := NewGeoDB("", "")
+	assert.NoError(t, geoDB.UpdateFromFile("../../../test/GeoIP2-City-Test.mmdb"))
+	countryCode, region, city := geoDB.GetLocationWithRegion("81.2.69.142")
 	assert.Equal(t, "gb", countryCode)
+	assert.Equal(t, "England", region)
 	assert.Equal(t, "London", city)
+	countryCode, region, city = geoDB.GetLocationWithRegion("216.160.83.56")
+	assert.Equal(t, "us", countryCode)
+	assert.Equal(t, "Washington", region)
+	assert.Equal(t, "Milton", city)
+	countryCode, region, city = geoDB.GetLocationWithRegion("invalid")
+	assert.Empty(t, countryCode)
+	assert.Empty(t, region)
+	assert.Empty(t, city)
+	geoDB = nil
+	var locator Locator = geoDB
+	countryCode, region, city = locator.GetLocationWithRegion("81.2.69.142")
+	assert.Empty(t, countryCode)
+	assert.Empty(t, region)
+	assert.Empty(t, city)
 }
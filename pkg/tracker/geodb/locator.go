@@ -0,0 +1,108 @@
+package geodb
+
+import (
+	"github.com/oschwald/maxminddb-golang"
+	"net"
+	"strings"
+)
+
+// Format is the record layout of a MaxMind DB (.mmdb) file.
+type Format int
+
+const (
+	// FormatAuto detects the Format from the database type in the metadata (default).
+	FormatAuto = Format(iota)
+
+	// FormatMaxMind is the layout used by MaxMind GeoIP2 and GeoLite2, DB-IP, and IP2Location LITE databases.
+	FormatMaxMind
+
+	// FormatIPinfo is the flat layout used by IPinfo databases.
+	FormatIPinfo
+)
+
+// Locator looks up the location of IP addresses.
+type Locator interface {
+	// GetLocationWithRegion returns the country code (in lowercase), region, and city for given IP.
+	// Empty strings are returned in case the IP is invalid or cannot be found.
+	GetLocationWithRegion(ip string) (string, string, string)
+}
+
+type maxMindRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		ISOCode string `maxminddb:"iso_code"`
+		Names   struct {
+			En string `maxminddb:"en"`
+		} `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names struct {
+			En string `maxminddb:"en"`
+		} `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+type ipinfoRecord struct {
+	Country     string `maxminddb:"country"`
+	CountryCode string `maxminddb:"country_code"`
+	Region      string `maxminddb:"region"`
+	City        string `maxminddb:"city"`
+}
+
+// detectFormat returns the Format for given database type.
+func detectFormat(databaseType string) Format {
+	if strings.Contains(strings.ToLower(databaseType), "ipinfo") {
+		return FormatIPinfo
+	}
+
+	return FormatMaxMind
+}
+
+// lookup returns the country code, region, and city for given IP from the database.
+func lookup(db *maxminddb.Reader, format Format, ip string) (string, string, string) {
+	parsedIP := net.ParseIP(ip)
+
+	if db == nil || parsedIP == nil {
+		return "", "", ""
+	}
+
+	if format == FormatAuto {
+		format = detectFormat(db.Metadata.DatabaseType)
+	}
+
+	if format == FormatIPinfo {
+		var record ipinfoRecord
+
+		if err := db.Lookup(parsedIP, &record); err != nil {
+			return "", "", ""
+		}
+
+		countryCode := record.CountryCode
+
+		if countryCode == "" && len(record.Country) == 2 {
+			countryCode = record.Country
+		}
+
+		return strings.ToLower(countryCode), record.Region, record.City
+	}
+
+	var record maxMindRecord
+
+	if err := db.Lookup(parsedIP, &record); err != nil {
+		return "", "", ""
+	}
+
+	region := ""
+
+	if len(record.Subdivisions) > 0 {
+		region = record.Subdivisions[0].Names.En
+
+		if region == "" {
+			region = record.Subdivisions[0].ISOCode
+		}
+	}
+
+	return strings.ToLower(record.Country.ISOCode), region, record.City.Names.En
+}
@@ -0,0 +1,238 @@
+package geodb
+
+import (
+	"errors"
+	"fmt"
+	"github.com/oschwald/maxminddb-golang"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// maxBuildDateSkew is the maximum time a build date may lie in the future.
+	maxBuildDateSkew = time.Hour * 24
+)
+
+// MMDBConfig is the configuration for MMDB.
+type MMDBConfig struct {
+	// Path is the path to the .mmdb file.
+	Path string
+
+	// Format sets the record layout of the database.
+	// If set to FormatAuto, it's detected from the database type in the metadata.
+	Format Format
+
+	// DatabaseTypes is a list of accepted database types from the metadata, like "GeoLite2-City" or "DBIP-City-Lite".
+	// All types are accepted if empty.
+	DatabaseTypes []string
+
+	// MaxAge rejects databases built longer ago than the maximum age. The age is not checked if set to 0.
+	MaxAge time.Duration
+
+	// TestIP is looked up after loading the database. The database is rejected if neither a country nor an autonomous system is found for it.
+	// The test lookup is skipped if empty.
+	TestIP string
+
+	// WatchInterval sets how often the file is checked for changes. The file is not watched if set to 0.
+	WatchInterval time.Duration
+
+	// Logger is the log/slog.Logger used to log errors while reloading the database.
+	// If nil, it will log to stdout.
+	Logger *slog.Logger
+}
+
+// MMDB is a Locator and ASNLookup for any MaxMind DB (.mmdb) file, like from MaxMind, DB-IP, IPinfo, or IP2Location.
+// Which one can be used depends on the database loaded, like GeoLite2-City or GeoLite2-ASN.
+// The database can be replaced while it's in use, and will be reloaded automatically when the file changes if configured.
+type MMDB struct {
+	config  MMDBConfig
+	db      atomic.Pointer[maxminddb.Reader]
+	modTime time.Time
+	size    int64
+	m       sync.Mutex
+	cancel  chan struct{}
+	done    chan struct{}
+}
+
+// NewMMDB loads the database for given configuration.
+// The database must pass the validation configured in MMDBConfig.
+func NewMMDB(config MMDBConfig) (*MMDB, error) {
+	if config.Logger == nil {
+		config.Logger = slog.New(slog.NewTextHandler(os.Stdout, nil))
+	}
+
+	db := &MMDB{
+		config: config,
+	}
+
+	if err := db.Reload(); err != nil {
+		return nil, err
+	}
+
+	if config.WatchInterval > 0 {
+		db.cancel = make(chan struct{})
+		db.done = make(chan struct{})
+		go db.watch()
+	}
+
+	return db, nil
+}
+
+// GetLocationWithRegion implements the Locator interface.
+// It's safe to call on a nil MMDB, so that a nil *MMDB can be passed as a Locator.
+func (db *MMDB) GetLocationWithRegion(ip string) (string, string, string) {
+	if db == nil {
+		return "", "", ""
+	}
+
+	return lookup(db.db.Load(), db.config.Format, ip)
+}
+
+// GetASN implements the ASNLookup interface.
+// It's safe to call on a nil MMDB, so that a nil *MMDB can be passed as an ASNLookup.
+func (db *MMDB) GetASN(ip string) (uint32, string) {
+	if db == nil {
+		return 0, ""
+	}
+
+	return lookupASN(db.db.Load(), ip)
+}
+
+// Metadata returns the metadata of the currently loaded database.
+// The zero value is returned for a nil MMDB.
+func (db *MMDB) Metadata() maxminddb.Metadata {
+	if db == nil {
+		return maxminddb.Metadata{}
+	}
+
+	if reader := db.db.Load(); reader != nil {
+		return reader.Metadata
+	}
+
+	return maxminddb.Metadata{}
+}
+
+// Reload reads and validates the database file, and replaces the current database if it passes the validation.
+// The current database is kept in case of an error.
+func (db *MMDB) Reload() error {
+	db.m.Lock()
+	defer db.m.Unlock()
+	info, err := os.Stat(db.config.Path)
+
+	if err != nil {
+		return err
+	}
+
+	// remember the file even if it's rejected, so that it's not reloaded until it changes again
+	db.modTime = info.ModTime()
+	db.size = info.Size()
+	data, err := os.ReadFile(db.config.Path)
+
+	if err != nil {
+		return err
+	}
+
+	reader, err := maxminddb.FromBytes(data)
+
+	if err != nil {
+		return err
+	}
+
+	if err := db.validate(reader); err != nil {
+		return err
+	}
+
+	db.db.Store(reader)
+	return nil
+}
+
+// Close stops watching the database file.
+func (db *MMDB) Close() {
+	if db != nil && db.cancel != nil {
+		close(db.cancel)
+		<-db.done
+		db.cancel = nil
+	}
+}
+
+func (db *MMDB) validate(reader *maxminddb.Reader) error {
+	if len(db.config.DatabaseTypes) > 0 {
+		found := false
+
+		for _, t := range db.config.DatabaseTypes {
+			if t == reader.Metadata.DatabaseType {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("database type %q not accepted", reader.Metadata.DatabaseType)
+		}
+	}
+
+	if reader.Metadata.BuildEpoch == 0 {
+		return errors.New("database build date missing")
+	}
+
+	buildDate := time.Unix(int64(reader.Metadata.BuildEpoch), 0)
+
+	if buildDate.After(time.Now().Add(maxBuildDateSkew)) {
+		return fmt.Errorf("database build date %s lies in the future", buildDate.Format(time.RFC3339))
+	}
+
+	if db.config.MaxAge > 0 && time.Since(buildDate) > db.config.MaxAge {
+		return fmt.Errorf("database build date %s exceeds the maximum age", buildDate.Format(time.RFC3339))
+	}
+
+	if current := db.db.Load(); current != nil && reader.Metadata.BuildEpoch < current.Metadata.BuildEpoch {
+		return fmt.Errorf("database build date %s is older than the current database", buildDate.Format(time.RFC3339))
+	}
+
+	if db.config.TestIP != "" {
+		countryCode, _, _ := lookup(reader, db.config.Format, db.config.TestIP)
+		asn, _ := lookupASN(reader, db.config.TestIP)
+
+		if countryCode == "" && asn == 0 {
+			return fmt.Errorf("test lookup for %s returned no country or autonomous system", db.config.TestIP)
+		}
+	}
+
+	return nil
+}
+
+func (db *MMDB) watch() {
+	ticker := time.NewTicker(db.config.WatchInterval)
+	defer ticker.Stop()
+	defer close(db.done)
+
+	for {
+		select {
+		case <-db.cancel:
+			return
+		case <-ticker.C:
+			if db.changed() {
+				if err := db.Reload(); err != nil {
+					db.config.Logger.Error("error reloading geo database", "err", err, "path", db.config.Path)
+				} else {
+					db.config.Logger.Info("reloaded geo database", "path", db.config.Path, "type", db.Metadata().DatabaseType)
+				}
+			}
+		}
+	}
+}
+
+func (db *MMDB) changed() bool {
+	info, err := os.Stat(db.config.Path)
+
+	if err != nil {
+		return false
+	}
+
+	db.m.Lock()
+	defer db.m.Unlock()
+	return !info.ModTime().Equal(db.modTime) || info.Size() != db.size
+}
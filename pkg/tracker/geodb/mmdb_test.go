@@ -0,0 +1,92 @@
+package geodb
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testMMDB = "../../../test/GeoIP2-City-Test.mmdb"
+
+func TestNewMMDB(t *testing.T) {
+	db, err := NewMMDB(MMDBConfig{
+		Path:          testMMDB,
+		DatabaseTypes: []string{"GeoLite2-City", "GeoIP2-City"},
+		TestIP:        "81.2.69.142",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "GeoIP2-City", db.Metadata().DatabaseType)
+	countryCode, region, city := db.GetLocationWithRegion("81.2.69.142")
+	assert.Equal(t, "gb", countryCode)
+	assert.Equal(t, "England", region)
+	assert.Equal(t, "London", city)
+	countryCode, region, city = db.GetLocationWithRegion("invalid")
+	assert.Empty(t, countryCode)
+	assert.Empty(t, region)
+	assert.Empty(t, city)
+	var _ Locator = db
+	var _ Locator = &GeoDB{}
+}
+
+func TestNewMMDBValidate(t *testing.T) {
+	_, err := NewMMDB(MMDBConfig{Path: "not-found.mmdb"})
+	assert.Error(t, err)
+	_, err = NewMMDB(MMDBConfig{Path: testMMDB, DatabaseTypes: []string{"DBIP-City-Lite"}})
+	assert.ErrorContains(t, err, "database type")
+	_, err = NewMMDB(MMDBConfig{Path: testMMDB, MaxAge: time.Hour * 24 * 30})
+	assert.ErrorContains(t, err, "maximum age")
+	_, err = NewMMDB(MMDBConfig{Path: testMMDB, TestIP: "127.0.0.1"})
+	assert.ErrorContains(t, err, "test lookup")
+}
+
+func TestMMDB_Watch(t *testing.T) {
+	data, err := os.ReadFile(testMMDB)
+	assert.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "geo.mmdb")
+	assert.NoError(t, os.WriteFile(path, data, 0644))
+	db, err := NewMMDB(MMDBConfig{
+		Path:          path,
+		TestIP:        "81.2.69.142",
+		WatchInterval: time.Millisecond * 10,
+	})
+	assert.NoError(t, err)
+	defer db.Close()
+	current := db.db.Load()
+
+	// an invalid file must be rejected and the current database kept
+	assert.NoError(t, os.WriteFile(path, []byte("invalid"), 0644))
+	time.Sleep(time.Millisecond * 50)
+	assert.Same(t, current, db.db.Load())
+	countryCode, _, _ := db.GetLocationWithRegion("81.2.69.142")
+	assert.Equal(t, "gb", countryCode)
+
+	// a valid file must be swapped in
+	assert.NoError(t, os.WriteFile(path, data, 0644))
+	time.Sleep(time.Millisecond * 50)
+	assert.NotSame(t, current, db.db.Load())
+	countryCode, _, _ = db.GetLocationWithRegion("81.2.69.142")
+	assert.Equal(t, "gb", countryCode)
+}
+
+func TestMMDB_Nil(t *testing.T) {
+	var db *MMDB
+	var locator Locator = db
+	var asnLookup ASNLookup = db
+	countryCode, region, city := locator.GetLocationWithRegion("81.2.69.142")
+	assert.Empty(t, countryCode)
+	assert.Empty(t, region)
+	assert.Empty(t, city)
+	asn, organization := asnLookup.GetASN("81.2.69.142")
+	assert.Zero(t, asn)
+	assert.Empty(t, organization)
+	assert.Empty(t, db.Metadata().DatabaseType)
+	db.Close()
+}
+
+func TestDetectFormat(t *testing.T) {
+	assert.Equal(t, FormatMaxMind, detectFormat("GeoLite2-City"))
+	assert.Equal(t, FormatMaxMind, detectFormat("DBIP-City-Lite"))
+	assert.Equal(t, FormatIPinfo, detectFormat("ipinfo standard_location.mmdb"))
+}
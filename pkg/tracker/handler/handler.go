@@ -0,0 +1,333 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultMaxBodySize = 1024 * 64
+)
+
+var (
+	// pixel is a transparent 1x1 GIF.
+	pixel = []byte{
+		0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+		0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+	}
+
+	getOrPost = []string{http.MethodGet, http.MethodPost}
+
+	// ErrUnknownClient is returned by a ClientIDFunc in case the client cannot be resolved.
+	ErrUnknownClient = errors.New("unknown client")
+)
+
+// ClientIDFunc resolves the client ID for a request.
+// The hostname is taken from the URL of the tracked page and is lowercase.
+// Return an error (like ErrUnknownClient) to reject the request.
+type ClientIDFunc func(r *http.Request, hostname string) (uint64, error)
+
+// ClientIDFromHostname returns a ClientIDFunc looking up the client ID by the hostname of the tracked page.
+func ClientIDFromHostname(clients map[string]uint64) ClientIDFunc {
+	return func(_ *http.Request, hostname string) (uint64, error) {
+		clientID, ok := clients[hostname]
+
+		if !ok {
+			return 0, ErrUnknownClient
+		}
+
+		return clientID, nil
+	}
+}
+
+// ClientIDFromHeader returns a ClientIDFunc reading the client ID from given request header.
+func ClientIDFromHeader(header string) ClientIDFunc {
+	return func(r *http.Request, _ string) (uint64, error) {
+		clientID, err := strconv.ParseUint(strings.TrimSpace(r.Header.Get(header)), 10, 64)
+
+		if err != nil {
+			return 0, ErrUnknownClient
+		}
+
+		return clientID, nil
+	}
+}
+
+// Config is the configuration for the Handler.
+type Config struct {
+	// Tracker is the Tracker used to track page views, events, and sessions (required).
+	Tracker *tracker.Tracker
+
+	// ClientID resolves the client ID for a request.
+	// If nil, all requests are tracked for client ID 0.
+	ClientID ClientIDFunc
+
+	// AllowedOrigins is the list of origins allowed to send cross-origin requests.
+	// If empty, all origins are allowed.
+	AllowedOrigins []string
+
+	// MaxBodySize sets the maximum size of a request body in bytes.
+	// If set to <= 0, the default value of 64 KB will be used.
+	MaxBodySize int64
+
+	// Logger is the log/slog.Logger used to log errors.
+	Logger *slog.Logger
+}
+
+func (config *Config) validate() {
+	if config.ClientID == nil {
+		config.ClientID = func(*http.Request, string) (uint64, error) {
+			return 0, nil
+		}
+	}
+
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = defaultMaxBodySize
+	}
+
+	if config.Logger == nil {
+		config.Logger = slog.New(slog.NewTextHandler(os.Stdout, nil))
+	}
+}
+
+// pageRequest is the optional JSON body for page views and session extensions sent as POST requests.
+// The fields are named like the query parameters read by tracker.OptionsFromRequest and override them if set.
+type pageRequest struct {
+	URL          string `json:"url"`
+	Title        string `json:"t"`
+	Referrer     string `json:"ref"`
+	ScreenWidth  uint16 `json:"w"`
+	ScreenHeight uint16 `json:"h"`
+}
+
+// eventRequest is the body for events.
+// The name is required, the duration and metadata are optional.
+type eventRequest struct {
+	Name     string            `json:"name"`
+	Duration uint32            `json:"duration"`
+	Meta     map[string]string `json:"meta"`
+}
+
+// Handler provides http.Handlers to collect page views, events, and session extensions.
+//
+// Page options (URL, title, referrer, screen size) are read from the query parameters like tracker.OptionsFromRequest does.
+// Page views and session extensions sent as POST requests can pass them as a JSON body instead, see pageRequest.
+// If no URL is passed, the Referer header is used instead.
+// Events are sent as JSON in the request body. As navigator.sendBeacon cannot set the Content-Type,
+// text/plain bodies are accepted too.
+type Handler struct {
+	config Config
+}
+
+// NewHandler creates a new Handler for given config.
+func NewHandler(config Config) *Handler {
+	config.validate()
+	return &Handler{
+		config: config,
+	}
+}
+
+// PageView returns an http.Handler tracking page views.
+// It accepts GET and POST requests (with an optional JSON body) and responds with 200 on success.
+func (handler *Handler) PageView() http.Handler {
+	return handler.handle(getOrPost, true, func(w http.ResponseWriter, r *http.Request, clientID uint64, options tracker.Options) {
+		handler.config.Tracker.PageView(r, clientID, options)
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// Event returns an http.Handler tracking events.
+// It accepts POST requests with a JSON body and responds with 200 on success.
+func (handler *Handler) Event() http.Handler {
+	return handler.handle([]string{http.MethodPost}, false, func(w http.ResponseWriter, r *http.Request, clientID uint64, options tracker.Options) {
+		r.Body = http.MaxBytesReader(w, r.Body, handler.config.MaxBodySize)
+		var req eventRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			var maxBytesErr *http.MaxBytesError
+
+			if errors.As(err, &maxBytesErr) {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+			} else {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+
+			return
+		}
+
+		req.Name = strings.TrimSpace(req.Name)
+
+		if req.Name == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		handler.config.Tracker.Event(r, clientID, tracker.EventOptions{
+			Name:     req.Name,
+			Duration: req.Duration,
+			Meta:     req.Meta,
+		}, options)
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// ExtendSession returns an http.Handler extending sessions.
+// It accepts GET and POST requests (with an optional JSON body) and responds with 200 on success.
+func (handler *Handler) ExtendSession() http.Handler {
+	return handler.handle(getOrPost, true, func(w http.ResponseWriter, r *http.Request, clientID uint64, options tracker.Options) {
+		handler.config.Tracker.ExtendSession(r, clientID, options)
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// Pixel returns an http.Handler tracking page views and responding with a transparent 1x1 GIF.
+// This can be used for visitors who have JavaScript disabled, AMP pages, or emails.
+// It accepts GET requests only.
+func (handler *Handler) Pixel() http.Handler {
+	return handler.handle([]string{http.MethodGet}, false, func(w http.ResponseWriter, r *http.Request, clientID uint64, options tracker.Options) {
+		handler.config.Tracker.PageView(r, clientID, options)
+		w.Header().Set("Content-Type", "image/gif")
+		w.Header().Set("Content-Length", strconv.Itoa(len(pixel)))
+		w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
+		w.WriteHeader(http.StatusOK)
+
+		if _, err := w.Write(pixel); err != nil {
+			handler.config.Logger.Debug("error writing pixel", "err", err)
+		}
+	})
+}
+
+// handle checks the CORS headers and method, reads the page options, and resolves the client ID before calling next.
+// If pageBody is set, the page options can be passed as a JSON body for POST requests.
+func (handler *Handler) handle(methods []string, pageBody bool, next func(http.ResponseWriter, *http.Request, uint64, tracker.Options)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !handler.cors(w, r) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if !slices.Contains(methods, r.Method) {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		options := tracker.OptionsFromRequest(r)
+
+		if pageBody && r.Method == http.MethodPost {
+			if status := handler.readPageRequest(w, r, &options); status != 0 {
+				w.WriteHeader(status)
+				return
+			}
+		}
+
+		if options.URL == "" {
+			if _, err := url.ParseRequestURI(r.Referer()); err == nil {
+				options.URL = r.Referer()
+			}
+		}
+
+		var hostname string
+
+		if u, err := url.ParseRequestURI(options.URL); err == nil {
+			hostname = strings.ToLower(u.Hostname())
+		}
+
+		clientID, err := handler.config.ClientID(r, hostname)
+
+		if err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		next(w, r, clientID, options)
+	})
+}
+
+// readPageRequest reads the page options from the JSON body of given request, if any.
+// It returns the status code to respond with in case the body is invalid, or 0 otherwise.
+func (handler *Handler) readPageRequest(w http.ResponseWriter, r *http.Request, options *tracker.Options) int {
+	r.Body = http.MaxBytesReader(w, r.Body, handler.config.MaxBodySize)
+	var req pageRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+
+		if errors.Is(err, io.EOF) {
+			return 0
+		} else if errors.As(err, &maxBytesErr) {
+			return http.StatusRequestEntityTooLarge
+		}
+
+		return http.StatusBadRequest
+	}
+
+	if pageURL := strings.TrimSpace(req.URL); pageURL != "" {
+		if _, err := url.ParseRequestURI(pageURL); err == nil {
+			options.URL = pageURL
+		}
+	}
+
+	if title := strings.TrimSpace(req.Title); title != "" {
+		options.Title = title
+	}
+
+	if ref := strings.TrimSpace(req.Referrer); ref != "" {
+		options.Referrer = ref
+	}
+
+	if req.ScreenWidth > 0 {
+		options.ScreenWidth = req.ScreenWidth
+	}
+
+	if req.ScreenHeight > 0 {
+		options.ScreenHeight = req.ScreenHeight
+	}
+
+	return 0
+}
+
+// cors sets the CORS headers and returns false if the origin is not allowed.
+func (handler *Handler) cors(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+
+	if origin == "" {
+		return true
+	}
+
+	if len(handler.config.AllowedOrigins) > 0 {
+		if !slices.Contains(handler.config.AllowedOrigins, origin) {
+			return false
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+	} else {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	}
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Max-Age", "86400")
+
+		if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+			w.Header().Set("Access-Control-Allow-Headers", headers)
+		}
+	}
+
+	return true
+}
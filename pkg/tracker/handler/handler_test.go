@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const (
+	userAgent = "Mozilla/5.0 (X11; Linux x86_64; rv:105.0) Gecko/20100101 Firefox/105.0"
+)
+
+func TestHandler_PageView(t *testing.T) {
+	store := db.NewClientMock()
+	handler := NewHandler(Config{
+		Tracker:  tracker.NewTracker(tracker.Config{Store: store}),
+		ClientID: ClientIDFromHostname(map[string]uint64{"example.com": 42}),
+	})
+	w := serve(handler.PageView(), http.MethodGet, "/p?url=https://example.com/foo&t=Title", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serve(handler.PageView(), http.MethodGet, "/p?url=https://unknown.com/foo", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serve(handler.PageView(), http.MethodPut, "/p?url=https://example.com/foo", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	handler.config.Tracker.Stop()
+	pageViews := store.GetPageViews()
+	assert.Len(t, pageViews, 1)
+	assert.Equal(t, uint64(42), pageViews[0].ClientID)
+	assert.Equal(t, "/foo", pageViews[0].Path)
+	assert.Equal(t, "Title", pageViews[0].Title)
+}
+
+func TestHandler_PageViewPost(t *testing.T) {
+	store := db.NewClientMock()
+	handler := NewHandler(Config{
+		Tracker:     tracker.NewTracker(tracker.Config{Store: store}),
+		ClientID:    ClientIDFromHostname(map[string]uint64{"example.com": 42}),
+		MaxBodySize: 200,
+	})
+	w := serve(handler.PageView(), http.MethodPost, "/p", `{"url": "https://example.com/foo", "t": "Title", "w": 1920}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serve(handler.PageView(), http.MethodPost, "/p?url=https://example.com/bar", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serve(handler.PageView(), http.MethodPost, "/p", `{"url": "https://unknown.com/foo"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serve(handler.PageView(), http.MethodPost, "/p", `{"url":`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(handler.PageView(), http.MethodPost, "/p", `{"url": "https://example.com/`+strings.Repeat("a", 200)+`"}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	handler.config.Tracker.Stop()
+	pageViews := store.GetPageViews()
+	assert.Len(t, pageViews, 2)
+	assert.Equal(t, "/foo", pageViews[0].Path)
+	assert.Equal(t, "Title", pageViews[0].Title)
+	assert.Equal(t, "/bar", pageViews[1].Path)
+	sessions := store.GetSessions()
+	assert.NotEmpty(t, sessions)
+	assert.Equal(t, "Full HD", sessions[0].ScreenClass)
+}
+
+func TestHandler_PageViewQuery(t *testing.T) {
+	store := db.NewClientMock()
+	handler := NewHandler(Config{
+		Tracker: tracker.NewTracker(tracker.Config{
+			Store:          store,
+			QueryParams:    []string{"variant"},
+			SearchParams:   []string{"q"},
+			CampaignParams: tracker.CampaignParams{Campaign: []string{"utm_campaign", "mtm_campaign"}},
+		}),
+	})
+	pageURL := url.QueryEscape("https://example.com/search?q=Shoes&variant=b&gclid=abc&mtm_campaign=Summer")
+	w := serve(handler.PageView(), http.MethodGet, "/p?url="+pageURL, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serve(handler.Event(), http.MethodPost, "/e?url="+pageURL, `{"name": "event"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	handler.config.Tracker.Stop()
+	pageViews := store.GetPageViews()
+	assert.Len(t, pageViews, 1)
+	assert.Equal(t, "/search", pageViews[0].Path)
+	assert.Equal(t, "shoes", pageViews[0].SearchTerm)
+	assert.Equal(t, []string{"variant"}, pageViews[0].QueryParamKeys)
+	assert.Equal(t, []string{"b"}, pageViews[0].QueryParamValues)
+	assert.Equal(t, "google", pageViews[0].UTMSource)
+	assert.Equal(t, "cpc", pageViews[0].UTMMedium)
+	assert.Equal(t, "Summer", pageViews[0].UTMCampaign)
+	assert.Equal(t, "Google Ads", pageViews[0].AdNetwork)
+	events := store.GetEvents()
+	assert.Len(t, events, 1)
+	assert.Equal(t, pageViews[0].SessionID, events[0].SessionID)
+	assert.Equal(t, []string{"variant"}, events[0].QueryParamKeys)
+	assert.Equal(t, []string{"b"}, events[0].QueryParamValues)
+}
+
+func TestHandler_Event(t *testing.T) {
+	store := db.NewClientMock()
+	handler := NewHandler(Config{
+		Tracker:     tracker.NewTracker(tracker.Config{Store: store}),
+		ClientID:    ClientIDFromHeader("X-Client-ID"),
+		MaxBodySize: 100,
+	})
+	w := serve(handler.Event(), http.MethodPost, "/e?url=https://example.com/foo", `{"name": "event", "duration": 42, "meta": {"key": "value"}}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serve(handler.Event(), http.MethodPost, "/e?url=https://example.com/foo", `{"name": " "}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(handler.Event(), http.MethodPost, "/e?url=https://example.com/foo", `{"name":`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(handler.Event(), http.MethodPost, "/e?url=https://example.com/foo", `{"name": "`+strings.Repeat("a", 100)+`"}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	w = serve(handler.Event(), http.MethodGet, "/e?url=https://example.com/foo", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	req := httptest.NewRequest(http.MethodPost, "/e?url=https://example.com/foo", strings.NewReader(`{"name": "event"}`))
+	req.Header.Set("User-Agent", userAgent)
+	w = httptest.NewRecorder()
+	handler.Event().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	handler.config.Tracker.Stop()
+	events := store.GetEvents()
+	assert.Len(t, events, 1)
+	assert.Equal(t, uint64(7), events[0].ClientID)
+	assert.Equal(t, "event", events[0].Name)
+	assert.Equal(t, uint32(42), events[0].DurationSeconds)
+	assert.Equal(t, []string{"key"}, events[0].MetaKeys)
+	assert.Equal(t, []string{"value"}, events[0].MetaValues)
+}
+
+func TestHandler_ExtendSession(t *testing.T) {
+	store := db.NewClientMock()
+	handler := NewHandler(Config{
+		Tracker: tracker.NewTracker(tracker.Config{Store: store}),
+	})
+	w := serve(handler.PageView(), http.MethodGet, "/p?url=https://example.com/", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serve(handler.ExtendSession(), http.MethodPost, "/s?url=https://example.com/", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	handler.config.Tracker.Stop()
+	sessions := store.GetSessions()
+	assert.Len(t, sessions, 3)
+	assert.Equal(t, uint16(1), sessions[2].Extended)
+}
+
+func TestHandler_Pixel(t *testing.T) {
+	store := db.NewClientMock()
+	handler := NewHandler(Config{
+		Tracker: tracker.NewTracker(tracker.Config{Store: store}),
+	})
+	req := httptest.NewRequest(http.MethodGet, "/pixel.gif", nil)
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Referer", "https://example.com/page")
+	w := httptest.NewRecorder()
+	handler.Pixel().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/gif", w.Header().Get("Content-Type"))
+	assert.Equal(t, pixel, w.Body.Bytes())
+	w = serve(handler.Pixel(), http.MethodPost, "/pixel.gif", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	handler.config.Tracker.Stop()
+	pageViews := store.GetPageViews()
+	assert.Len(t, pageViews, 1)
+	assert.Equal(t, "/page", pageViews[0].Path)
+}
+
+func TestHandler_CORS(t *testing.T) {
+	handler := NewHandler(Config{
+		Tracker:        tracker.NewTracker(tracker.Config{Store: db.NewClientMock()}),
+		AllowedOrigins: []string{"https://example.com"},
+	})
+	req := httptest.NewRequest(http.MethodOptions, "/e", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Headers", "Content-Type")
+	w := httptest.NewRecorder()
+	handler.Event().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+	req = httptest.NewRequest(http.MethodPost, "/e?url=https://example.com/", strings.NewReader(`{"name": "event"}`))
+	req.Header.Set("Origin", "https://other.com")
+	w = httptest.NewRecorder()
+	handler.Event().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	handler.config.Tracker.Stop()
+}
+
+func serve(handler http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("X-Client-ID", "7")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
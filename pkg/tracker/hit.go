@@ -0,0 +1,148 @@
+package tracker
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ClientHints are the User-Agent client hints sent by the browser.
+// The values are the raw header values, like `"Google Chrome";v="115"` for UA or `?1` for Mobile.
+type ClientHints struct {
+	UA              string
+	Mobile          string
+	Platform        string
+	PlatformVersion string
+	Width           uint16
+	ViewportWidth   uint16
+}
+
+// Hit is a request-independent input for page views, events, and session extensions.
+// It can be used to track data received from a message queue or sent by native apps.
+// Hits are filtered, fingerprinted, and assigned to sessions the same way as HTTP requests.
+type Hit struct {
+	// UserAgent is the raw User-Agent string.
+	UserAgent string
+
+	// ClientHints are the optional User-Agent client hints.
+	ClientHints ClientHints
+
+	// IP is the visitor IP address (IPv4 or IPv6).
+	IP string
+
+	// AcceptLanguage is the raw Accept-Language header value.
+	AcceptLanguage string
+
+	// Referrer is the referrer URL or name.
+	Referrer string
+
+	// URL is the full URL of the page, including the hostname.
+	URL string
+
+	// DoNotTrack sets whether the visitor has sent the DNT header.
+	DoNotTrack bool
+
+	UTMSource   string
+	UTMMedium   string
+	UTMCampaign string
+	UTMContent  string
+	UTMTerm     string
+}
+
+// PageViewHit tracks a page view for given Hit.
+func (tracker *Tracker) PageViewHit(hit Hit, clientID uint64, options Options) {
+	tracker.PageView(hit.request(&options), clientID, options)
+}
+
+// EventHit tracks an event for given Hit.
+func (tracker *Tracker) EventHit(hit Hit, clientID uint64, eventOptions EventOptions, options Options) {
+	tracker.Event(hit.request(&options), clientID, eventOptions, options)
+}
+
+// ExtendSessionHit extends an existing session for given Hit.
+func (tracker *Tracker) ExtendSessionHit(hit Hit, clientID uint64, options Options) {
+	tracker.ExtendSession(hit.request(&options), clientID, options)
+}
+
+// request builds an http.Request for the hit, so that the same rules and parsers can be used for both.
+// The UTM parameters are added to the URL query and the URL is set for the options if empty.
+// If the options already contain a URL, the UTM parameters are added to it instead.
+func (hit *Hit) request(options *Options) *http.Request {
+	u, err := url.ParseRequestURI(strings.TrimSpace(hit.URL))
+
+	if err != nil {
+		u = &url.URL{Path: "/"}
+	}
+
+	hit.setUTMParams(u)
+
+	if options.URL == "" {
+		if err == nil {
+			options.URL = u.String()
+		}
+	} else if pageURL, err := url.ParseRequestURI(options.URL); err == nil {
+		hit.setUTMParams(pageURL)
+		options.URL = pageURL.String()
+	}
+
+	header := make(http.Header)
+	setHeader(header, "User-Agent", hit.UserAgent)
+	setHeader(header, "Accept-Language", hit.AcceptLanguage)
+	setHeader(header, "Referer", hit.Referrer)
+	setHeader(header, "Sec-CH-UA", hit.ClientHints.UA)
+	setHeader(header, "Sec-CH-UA-Mobile", hit.ClientHints.Mobile)
+	setHeader(header, "Sec-CH-UA-Platform", hit.ClientHints.Platform)
+	setHeader(header, "Sec-CH-UA-Platform-Version", hit.ClientHints.PlatformVersion)
+
+	if hit.ClientHints.Width > 0 {
+		header.Set("Sec-CH-Width", strconv.Itoa(int(hit.ClientHints.Width)))
+	}
+
+	if hit.ClientHints.ViewportWidth > 0 {
+		header.Set("Sec-CH-Viewport-Width", strconv.Itoa(int(hit.ClientHints.ViewportWidth)))
+	}
+
+	if hit.DoNotTrack {
+		header.Set("DNT", "1")
+	}
+
+	return &http.Request{
+		Method:     http.MethodGet,
+		URL:        u,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     header,
+		Host:       u.Host,
+		RemoteAddr: net.JoinHostPort(strings.TrimSpace(hit.IP), "0"),
+	}
+}
+
+// setUTMParams adds the UTM parameters of the hit to the query of given URL.
+func (hit *Hit) setUTMParams(u *url.URL) {
+	query := u.Query()
+	setQueryParam(query, "utm_source", hit.UTMSource)
+	setQueryParam(query, "utm_medium", hit.UTMMedium)
+	setQueryParam(query, "utm_campaign", hit.UTMCampaign)
+	setQueryParam(query, "utm_content", hit.UTMContent)
+	setQueryParam(query, "utm_term", hit.UTMTerm)
+	u.RawQuery = query.Encode()
+}
+
+func setQueryParam(query url.Values, key, value string) {
+	value = strings.TrimSpace(value)
+
+	if value != "" {
+		query.Set(key, value)
+	}
+}
+
+func setHeader(header http.Header, key, value string) {
+	value = strings.TrimSpace(value)
+
+	if value != "" {
+		header.Set(key, value)
+	}
+}
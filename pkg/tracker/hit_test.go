@@ -0,0 +1,130 @@
+package tracker
+
+import (
+	"github.com/pirsch-analytics/pirsch/v6/pkg"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/geodb"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTracker_PageViewHit(t *testing.T) {
+	geoDB, _ := geodb.NewGeoDB("", "")
+	assert.NoError(t, geoDB.UpdateFromFile("../../test/GeoIP2-City-Test.mmdb"))
+	client := db.NewClientMock()
+	tracker := NewTracker(Config{
+		Store: client,
+		GeoDB: geoDB,
+	})
+	hit := Hit{
+		UserAgent:      userAgent,
+		IP:             "81.2.69.142",
+		AcceptLanguage: "fr-CH, fr;q=0.9, en;q=0.8",
+		Referrer:       "https://google.com",
+		URL:            "https://example.com/foo/bar",
+		UTMSource:      "Source",
+		UTMMedium:      "Medium",
+		UTMCampaign:    "Campaign",
+		UTMContent:     "Content",
+		UTMTerm:        "Term",
+	}
+	tracker.PageViewHit(hit, 123, Options{Title: "Foo", ScreenWidth: 1920})
+	tracker.EventHit(hit, 123, EventOptions{Name: "event"}, Options{})
+	tracker.ExtendSessionHit(hit, 123, Options{})
+	hit.DoNotTrack = true
+	tracker.PageViewHit(hit, 123, Options{})
+	tracker.Flush()
+	sessions := client.GetSessions()
+	pageViews := client.GetPageViews()
+	events := client.GetEvents()
+	assert.Len(t, sessions, 5)
+	assert.Len(t, pageViews, 1)
+	assert.Len(t, events, 1)
+	assert.Equal(t, uint64(123), sessions[0].ClientID)
+	assert.Equal(t, "/foo/bar", sessions[0].EntryPath)
+	assert.Equal(t, "Foo", sessions[0].EntryTitle)
+	assert.Equal(t, "fr", sessions[0].Language)
+	assert.Equal(t, "gb", sessions[0].CountryCode)
+	assert.Equal(t, "London", sessions[0].City)
+	assert.Equal(t, "https://google.com", sessions[0].Referrer)
+	assert.Equal(t, "Google", sessions[0].ReferrerName)
+	assert.Equal(t, pkg.BrowserFirefox, sessions[0].Browser)
+	assert.Equal(t, "Full HD", sessions[0].ScreenClass)
+	assert.Equal(t, "Source", sessions[0].UTMSource)
+	assert.Equal(t, "Medium", sessions[0].UTMMedium)
+	assert.Equal(t, "Campaign", sessions[0].UTMCampaign)
+	assert.Equal(t, "Content", sessions[0].UTMContent)
+	assert.Equal(t, "Term", sessions[0].UTMTerm)
+	assert.Equal(t, sessions[0].SessionID, events[0].SessionID)
+	assert.Equal(t, uint16(1), sessions[4].Extended)
+	assert.Equal(t, uint64(1), tracker.Stats().Ignored[ReasonDoNotTrack])
+
+	// the fingerprint must match the one of an equal HTTP request
+	req := httptest.NewRequest(http.MethodGet, "/foo/bar", nil)
+	req.Header.Set("User-Agent", userAgent)
+	req.RemoteAddr = "81.2.69.142"
+	tracker.PageView(req, 123, Options{URL: "https://example.com/foo/bar", Title: "Foo"})
+	tracker.Stop()
+	sessions = client.GetSessions()
+	assert.Equal(t, sessions[0].VisitorID, sessions[len(sessions)-1].VisitorID)
+	assert.Equal(t, sessions[0].SessionID, sessions[len(sessions)-1].SessionID)
+}
+
+func TestHit_request(t *testing.T) {
+	hit := Hit{
+		UserAgent: userAgent,
+		ClientHints: ClientHints{
+			UA:              `"Chromium";v="115"`,
+			Mobile:          "?1",
+			Platform:        `"Android"`,
+			PlatformVersion: `"13.0.0"`,
+			ViewportWidth:   400,
+		},
+		IP:        "2001:db8::1",
+		URL:       "https://example.com/path?query=value&utm_source=url",
+		UTMSource: "Source",
+	}
+	options := Options{}
+	r := hit.request(&options)
+	assert.Equal(t, "https://example.com/path?query=value&utm_source=Source", options.URL)
+	assert.Equal(t, "Source", r.URL.Query().Get("utm_source"))
+	assert.Equal(t, userAgent, r.UserAgent())
+	assert.Equal(t, "?1", r.Header.Get("Sec-CH-UA-Mobile"))
+	assert.Equal(t, "400", r.Header.Get("Sec-CH-Viewport-Width"))
+	assert.Empty(t, r.Header.Get("Sec-CH-Width"))
+	assert.Empty(t, r.Header.Get("DNT"))
+	assert.Equal(t, "[2001:db8::1]:0", r.RemoteAddr)
+	options = Options{URL: "https://example.com/other"}
+	hit.URL = "invalid"
+	r = hit.request(&options)
+	assert.Equal(t, "https://example.com/other?utm_source=Source", options.URL)
+	assert.Equal(t, "/", r.URL.Path)
+}
+
+func TestTracker_PageViewHitOptionsURL(t *testing.T) {
+	client := db.NewClientMock()
+	tracker := NewTracker(Config{
+		Store: client,
+	})
+	hit := Hit{
+		UserAgent:   userAgent,
+		IP:          "81.2.69.142",
+		URL:         "https://example.com/hit",
+		UTMSource:   "Source",
+		UTMCampaign: "Campaign",
+	}
+	tracker.PageViewHit(hit, 0, Options{URL: "https://example.com/foo?utm_medium=Medium"})
+	tracker.Stop()
+	sessions := client.GetSessions()
+	pageViews := client.GetPageViews()
+	assert.Len(t, sessions, 1)
+	assert.Len(t, pageViews, 1)
+	assert.Equal(t, "/foo", pageViews[0].Path)
+	assert.Equal(t, "Source", sessions[0].UTMSource)
+	assert.Equal(t, "Medium", sessions[0].UTMMedium)
+	assert.Equal(t, "Campaign", sessions[0].UTMCampaign)
+	assert.Equal(t, "Source", pageViews[0].UTMSource)
+	assert.Equal(t, "Campaign", pageViews[0].UTMCampaign)
+}
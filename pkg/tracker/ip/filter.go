@@ -14,3 +14,64 @@ type Filter interface {
 	// Ignore reports whether an IP address should be ignored.
 	Ignore(string) bool
 }
+
+// Multi implements the Filter interface by combining multiple filters.
+// An IP address is ignored if any of the filters ignores it.
+type Multi struct {
+	filters []Filter
+}
+
+// NewMulti creates a new Filter for given filters.
+func NewMulti(filters ...Filter) *Multi {
+	return &Multi{
+		filters: filters,
+	}
+}
+
+// Update implements the Filter interface.
+// It does nothing, as the lists belong to the combined filters. Update them directly instead.
+func (multi *Multi) Update([]string, []string, []Range, []Range) {}
+
+// Ignore implements the Filter interface.
+func (multi *Multi) Ignore(ip string) bool {
+	for _, filter := range multi.filters {
+		if filter != nil && filter.Ignore(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Allow implements the Filter interface by overriding a filter with an allow-list.
+// IP addresses on the allow-list are never ignored, like partner monitoring services on a blocked network.
+type Allow struct {
+	filter Filter
+	allow  *List
+}
+
+// NewAllow creates a new Filter that ignores all IP addresses ignored by the filter, except for the ones on the allow-list.
+// If the filter is nil, no IP address is ignored.
+func NewAllow(filter Filter, allow *List) *Allow {
+	return &Allow{
+		filter: filter,
+		allow:  allow,
+	}
+}
+
+// Update implements the Filter interface.
+// It updates the filter, not the allow-list.
+func (allow *Allow) Update(ipsV4, ipsV6 []string, rangesV4, rangesV6 []Range) {
+	if allow.filter != nil {
+		allow.filter.Update(ipsV4, ipsV6, rangesV4, rangesV6)
+	}
+}
+
+// Ignore implements the Filter interface.
+func (allow *Allow) Ignore(ip string) bool {
+	if allow.filter == nil || (allow.allow != nil && allow.allow.Contains(ip)) {
+		return false
+	}
+
+	return allow.filter.Ignore(ip)
+}
@@ -0,0 +1,41 @@
+package ip
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMulti(t *testing.T) {
+	udger := NewUdger("", "")
+	udger.Update([]string{"90.154.29.38"}, nil, nil, nil)
+	list, err := NewList(ListConfig{})
+	assert.NoError(t, err)
+	list.Update([]string{"203.0.113.5"}, nil, nil, nil)
+	filter := NewMulti(udger, list, nil)
+	assert.True(t, filter.Ignore("90.154.29.38"))
+	assert.True(t, filter.Ignore("203.0.113.5"))
+	assert.False(t, filter.Ignore("203.0.113.6"))
+	filter.Update([]string{"203.0.113.6"}, nil, nil, nil)
+	assert.False(t, filter.Ignore("203.0.113.6"))
+	assert.False(t, NewMulti().Ignore("203.0.113.6"))
+}
+
+func TestAllow(t *testing.T) {
+	blocked, err := NewList(ListConfig{})
+	assert.NoError(t, err)
+	blocked.Update(nil, nil, []Range{{"203.0.113.0", "203.0.113.255"}}, nil)
+	allowed, err := NewList(ListConfig{})
+	assert.NoError(t, err)
+	allowed.Update([]string{"203.0.113.5"}, nil, nil, nil)
+	filter := NewAllow(blocked, allowed)
+	assert.True(t, filter.Ignore("203.0.113.4"))
+	assert.False(t, filter.Ignore("203.0.113.5"))
+	assert.False(t, filter.Ignore("198.51.100.1"))
+	assert.True(t, filter.Ignore("invalid"))
+	filter = NewAllow(NewMulti(blocked), nil)
+	assert.True(t, filter.Ignore("203.0.113.5"))
+	filter = NewAllow(nil, allowed)
+	filter.Update([]string{"203.0.113.4"}, nil, nil, nil)
+	assert.False(t, filter.Ignore("203.0.113.4"))
+	assert.False(t, filter.Ignore("203.0.113.5"))
+}
@@ -0,0 +1,235 @@
+package ip
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ListConfig is the configuration for List.
+type ListConfig struct {
+	// Files is a list of files containing one IP address (like "203.0.113.5") or CIDR range (like "2001:db8::/32") per line.
+	// Empty lines and everything after a "#" or ";" are ignored.
+	Files []string
+
+	// ReloadInterval sets how often the files are read again. The files are only read once if set to 0.
+	ReloadInterval time.Duration
+
+	// Logger is the log/slog.Logger used to log errors while reloading the files.
+	// If nil, it will log to stdout.
+	Logger *slog.Logger
+}
+
+// List implements the Filter interface for plain lists of IP addresses and CIDR ranges, like office IPs or Tor exit nodes.
+type List struct {
+	config   ListConfig
+	ips      map[netip.Addr]struct{}
+	prefixes []netip.Prefix
+	ranges   []addrRange
+	m        sync.RWMutex
+	cancel   chan struct{}
+	done     chan struct{}
+}
+
+type addrRange struct {
+	from netip.Addr
+	to   netip.Addr
+}
+
+// NewList creates a new Filter and loads the IP addresses from the configured files.
+func NewList(config ListConfig) (*List, error) {
+	if config.Logger == nil {
+		config.Logger = slog.New(slog.NewTextHandler(os.Stdout, nil))
+	}
+
+	list := &List{
+		config: config,
+	}
+
+	if err := list.Reload(); err != nil {
+		return nil, err
+	}
+
+	if config.ReloadInterval > 0 && len(config.Files) > 0 {
+		list.cancel = make(chan struct{})
+		list.done = make(chan struct{})
+		go list.reload()
+	}
+
+	return list, nil
+}
+
+// Update implements the Filter interface.
+// The list is replaced by the configured files on the next reload.
+func (list *List) Update(ipsV4, ipsV6 []string, rangesV4, rangesV6 []Range) {
+	ips := make(map[netip.Addr]struct{}, len(ipsV4)+len(ipsV6))
+
+	for _, addresses := range [][]string{ipsV4, ipsV6} {
+		for _, ip := range addresses {
+			if addr, err := netip.ParseAddr(ip); err == nil {
+				ips[addr.Unmap()] = struct{}{}
+			}
+		}
+	}
+
+	ranges := make([]addrRange, 0, len(rangesV4)+len(rangesV6))
+
+	for _, addressRanges := range [][]Range{rangesV4, rangesV6} {
+		for _, r := range addressRanges {
+			from, fromErr := netip.ParseAddr(r.From)
+			to, toErr := netip.ParseAddr(r.To)
+
+			if fromErr == nil && toErr == nil {
+				ranges = append(ranges, addrRange{
+					from: from.Unmap(),
+					to:   to.Unmap(),
+				})
+			}
+		}
+	}
+
+	list.m.Lock()
+	defer list.m.Unlock()
+	list.ips = ips
+	list.prefixes = nil
+	list.ranges = ranges
+}
+
+// Ignore implements the Filter interface.
+// Invalid IP addresses are ignored.
+func (list *List) Ignore(ip string) bool {
+	if _, err := netip.ParseAddr(ip); err != nil {
+		return true
+	}
+
+	return list.Contains(ip)
+}
+
+// Contains returns whether the IP address is on the list.
+func (list *List) Contains(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+	list.m.RLock()
+	defer list.m.RUnlock()
+
+	if _, ok := list.ips[addr]; ok {
+		return true
+	}
+
+	for _, prefix := range list.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	for _, r := range list.ranges {
+		if addr.BitLen() == r.from.BitLen() && addr.Compare(r.from) >= 0 && addr.Compare(r.to) <= 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reload reads the configured files and replaces the list.
+// The current list is kept in case of an error.
+func (list *List) Reload() error {
+	ips := make(map[netip.Addr]struct{})
+	var prefixes []netip.Prefix
+
+	for _, path := range list.config.Files {
+		if err := readList(path, ips, &prefixes); err != nil {
+			return err
+		}
+	}
+
+	list.m.Lock()
+	defer list.m.Unlock()
+	list.ips = ips
+	list.prefixes = prefixes
+	list.ranges = nil
+	return nil
+}
+
+// Close stops reloading the files.
+func (list *List) Close() {
+	if list.cancel != nil {
+		close(list.cancel)
+		<-list.done
+		list.cancel = nil
+	}
+}
+
+func (list *List) reload() {
+	ticker := time.NewTicker(list.config.ReloadInterval)
+	defer ticker.Stop()
+	defer close(list.done)
+
+	for {
+		select {
+		case <-list.cancel:
+			return
+		case <-ticker.C:
+			if err := list.Reload(); err != nil {
+				list.config.Logger.Error("error reloading IP list", "err", err)
+			}
+		}
+	}
+}
+
+func readList(path string, ips map[netip.Addr]struct{}, prefixes *[]netip.Prefix) error {
+	f, err := os.Open(path)
+
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		entry := scanner.Text()
+
+		if i := strings.IndexAny(entry, "#;"); i >= 0 {
+			entry = entry[:i]
+		}
+
+		entry = strings.TrimSpace(entry)
+
+		if entry == "" {
+			continue
+		}
+
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+
+			if err != nil {
+				return fmt.Errorf("invalid CIDR range %q in %s:%d", entry, path, line)
+			}
+
+			*prefixes = append(*prefixes, prefix.Masked())
+		} else {
+			addr, err := netip.ParseAddr(entry)
+
+			if err != nil {
+				return fmt.Errorf("invalid IP address %q in %s:%d", entry, path, line)
+			}
+
+			ips[addr.Unmap()] = struct{}{}
+		}
+	}
+
+	return scanner.Err()
+}
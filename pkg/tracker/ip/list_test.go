@@ -0,0 +1,95 @@
+package ip
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewList(t *testing.T) {
+	dir := t.TempDir()
+	office := filepath.Join(dir, "office.txt")
+	tor := filepath.Join(dir, "tor.txt")
+	assert.NoError(t, os.WriteFile(office, []byte(`# office
+203.0.113.5
+198.51.100.0/24 ; VPN
+
+2001:db8:1::/48 # IPv6
+`), 0644))
+	assert.NoError(t, os.WriteFile(tor, []byte("185.220.101.1\n2a0b:f4c2::1\n"), 0644))
+	list, err := NewList(ListConfig{Files: []string{office, tor}})
+	assert.NoError(t, err)
+	assert.True(t, list.Ignore("203.0.113.5"))
+	assert.False(t, list.Ignore("203.0.113.6"))
+	assert.True(t, list.Ignore("198.51.100.0"))
+	assert.True(t, list.Ignore("198.51.100.255"))
+	assert.False(t, list.Ignore("198.51.101.1"))
+	assert.True(t, list.Ignore("::ffff:198.51.100.42"))
+	assert.True(t, list.Ignore("2001:db8:1:ffff::1"))
+	assert.False(t, list.Ignore("2001:db8:2::1"))
+	assert.True(t, list.Ignore("185.220.101.1"))
+	assert.True(t, list.Ignore("2a0b:f4c2::1"))
+	assert.False(t, list.Ignore("2a0b:f4c2::2"))
+	assert.True(t, list.Ignore("invalid"))
+	assert.False(t, list.Contains("invalid"))
+}
+
+func TestNewListInvalid(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "list.txt")
+	_, err := NewList(ListConfig{Files: []string{path}})
+	assert.Error(t, err)
+	assert.NoError(t, os.WriteFile(path, []byte("203.0.113.5\n203.0.113.256\n"), 0644))
+	_, err = NewList(ListConfig{Files: []string{path}})
+	assert.ErrorContains(t, err, "list.txt:2")
+	assert.NoError(t, os.WriteFile(path, []byte("203.0.113.0/33\n"), 0644))
+	_, err = NewList(ListConfig{Files: []string{path}})
+	assert.ErrorContains(t, err, "invalid CIDR range")
+}
+
+func TestList_Update(t *testing.T) {
+	list, err := NewList(ListConfig{})
+	assert.NoError(t, err)
+	assert.False(t, list.Ignore("90.154.29.38"))
+	list.Update([]string{
+		"90.154.29.38",
+	}, []string{
+		"2003:e1:7f03:a7b7:6328:b96a:4061:9999",
+	}, []Range{
+		{"123.0.0.0", "123.10.0.5"},
+	}, []Range{
+		{"2001:1ab0:f001::", "2001:1ab0:f001:ffff:ffff:ffff:ffff:ffff"},
+	})
+	assert.True(t, list.Ignore("90.154.29.38"))
+	assert.False(t, list.Ignore("91.154.29.38"))
+	assert.True(t, list.Ignore("123.5.123.69"))
+	assert.False(t, list.Ignore("123.10.0.6"))
+	assert.True(t, list.Ignore("2003:e1:7f03:a7b7:6328:b96a:4061:9999"))
+	assert.True(t, list.Ignore("2001:1ab0:f001:1000::ff"))
+	assert.False(t, list.Ignore("2001:1ab0:f002::"))
+}
+
+func TestList_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("203.0.113.5\n"), 0644))
+	list, err := NewList(ListConfig{
+		Files:          []string{path},
+		ReloadInterval: time.Millisecond * 10,
+	})
+	assert.NoError(t, err)
+	defer list.Close()
+	assert.True(t, list.Ignore("203.0.113.5"))
+
+	// an invalid file must be rejected and the current list kept
+	assert.NoError(t, os.WriteFile(path, []byte("invalid\n"), 0644))
+	time.Sleep(time.Millisecond * 50)
+	assert.True(t, list.Ignore("203.0.113.5"))
+
+	// a valid file must replace the list
+	assert.NoError(t, os.WriteFile(path, []byte("203.0.113.6\n"), 0644))
+	time.Sleep(time.Millisecond * 50)
+	assert.False(t, list.Ignore("203.0.113.5"))
+	assert.True(t, list.Ignore("203.0.113.6"))
+}
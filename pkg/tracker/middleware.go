@@ -0,0 +1,171 @@
+package tracker
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// DefaultStaticExtensions is the default list of file extensions for static assets, which are not tracked.
+var DefaultStaticExtensions = []string{
+	".css", ".js", ".mjs", ".map", ".json", ".xml", ".txt",
+	".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp", ".avif", ".ico", ".bmp",
+	".woff", ".woff2", ".ttf", ".otf", ".eot",
+	".mp4", ".webm", ".mp3", ".ogg", ".wav",
+	".pdf", ".zip", ".gz", ".wasm",
+}
+
+// MiddlewareOptions are the options for the Middleware.
+type MiddlewareOptions struct {
+	// ClientID is the client page views are tracked for.
+	ClientID uint64
+
+	// Include is an optional list of path patterns (see path.Match). If set, only matching paths are tracked.
+	Include []string
+
+	// Exclude is a list of path patterns (see path.Match) that are not tracked.
+	Exclude []string
+
+	// StaticExtensions is the list of file extensions for static assets that are not tracked.
+	// If nil, a default list of common extensions (stylesheets, scripts, images, fonts, ...) will be used.
+	StaticExtensions []string
+
+	// TitleHeader is the name of a response header the handler can set to pass the page title.
+	// The header is removed from the response.
+	TitleHeader string
+
+	// Title is an optional function to get the page title for a request.
+	// It is called after the handler has returned and overrides the TitleHeader.
+	Title func(r *http.Request, header http.Header) string
+
+	// TrackNotFound sets whether responses with status 404 are tracked.
+	// They can be told apart from successful page views by the status code.
+	TrackNotFound bool
+}
+
+// Middleware returns a middleware that tracks page views for successful HTML responses to GET requests automatically.
+// This can be used for server-side rendered websites.
+func Middleware(tracker *Tracker, options MiddlewareOptions) func(http.Handler) http.Handler {
+	if options.StaticExtensions == nil {
+		options.StaticExtensions = DefaultStaticExtensions
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || !options.track(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rw := &middlewareResponseWriter{
+				ResponseWriter: w,
+				titleHeader:    options.TitleHeader,
+			}
+			next.ServeHTTP(rw, r)
+
+			if rw.status == 0 {
+				rw.status = http.StatusOK
+			}
+
+			if (rw.status < 200 || rw.status > 299) && (!options.TrackNotFound || rw.status != http.StatusNotFound) {
+				return
+			}
+
+			if !strings.HasPrefix(strings.ToLower(rw.Header().Get("Content-Type")), "text/html") {
+				return
+			}
+
+			title := rw.title
+
+			if options.Title != nil {
+				title = options.Title(r, rw.Header())
+			}
+
+			tracker.PageView(r, options.ClientID, Options{
+				Title:      title,
+				StatusCode: uint16(rw.status),
+			})
+		})
+	}
+}
+
+func (options *MiddlewareOptions) track(p string) bool {
+	ext := strings.ToLower(path.Ext(p))
+
+	if ext != "" {
+		for _, static := range options.StaticExtensions {
+			if ext == static {
+				return false
+			}
+		}
+	}
+
+	for _, pattern := range options.Exclude {
+		if match, _ := path.Match(pattern, p); match {
+			return false
+		}
+	}
+
+	if len(options.Include) == 0 {
+		return true
+	}
+
+	for _, pattern := range options.Include {
+		if match, _ := path.Match(pattern, p); match {
+			return true
+		}
+	}
+
+	return false
+}
+
+// middlewareResponseWriter captures the status code and the title header.
+type middlewareResponseWriter struct {
+	http.ResponseWriter
+	titleHeader string
+	title       string
+	status      int
+}
+
+func (w *middlewareResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+
+		if w.titleHeader != "" {
+			w.title = w.Header().Get(w.titleHeader)
+			w.Header().Del(w.titleHeader)
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *middlewareResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *middlewareResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *middlewareResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+
+	return nil, nil, errors.New("hijacking not supported")
+}
+
+// Unwrap returns the original http.ResponseWriter for http.ResponseController.
+func (w *middlewareResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
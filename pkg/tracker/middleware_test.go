@@ -0,0 +1,94 @@
+package tracker
+
+import (
+	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	store := db.NewClientMock()
+	tracker := NewTracker(Config{Store: store})
+	handler := Middleware(tracker, MiddlewareOptions{
+		ClientID:      42,
+		Exclude:       []string{"/admin/*"},
+		TitleHeader:   "X-Title",
+		TrackNotFound: true,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+		case "/missing":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		case "/error":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("X-Title", "Title")
+		}
+
+		_, _ = w.Write([]byte("<html></html>"))
+	}))
+
+	for _, path := range []string{"/", "/json", "/missing", "/error", "/admin/page", "/style.css", "/image.PNG"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("User-Agent", userAgent)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if path == "/" {
+			assert.Empty(t, w.Header().Get("X-Title"))
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("User-Agent", userAgent)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	tracker.Stop()
+	pageViews := store.GetPageViews()
+	assert.Len(t, pageViews, 2)
+	assert.Equal(t, uint64(42), pageViews[0].ClientID)
+	assert.Equal(t, "/", pageViews[0].Path)
+	assert.Equal(t, "Title", pageViews[0].Title)
+	assert.Equal(t, uint16(http.StatusOK), pageViews[0].StatusCode)
+	assert.Equal(t, "/missing", pageViews[1].Path)
+	assert.Equal(t, uint16(http.StatusNotFound), pageViews[1].StatusCode)
+}
+
+func TestMiddlewareOptions_track(t *testing.T) {
+	options := MiddlewareOptions{
+		Include:          []string{"/blog/*", "/"},
+		Exclude:          []string{"/blog/draft-*"},
+		StaticExtensions: DefaultStaticExtensions,
+	}
+	assert.True(t, options.track("/"))
+	assert.True(t, options.track("/blog/post"))
+	assert.False(t, options.track("/blog/draft-post"))
+	assert.False(t, options.track("/about"))
+	assert.False(t, options.track("/blog/script.js"))
+}
+
+func TestMiddlewareTitle(t *testing.T) {
+	store := db.NewClientMock()
+	tracker := NewTracker(Config{Store: store})
+	handler := Middleware(tracker, MiddlewareOptions{
+		Title: func(r *http.Request, header http.Header) string {
+			return "Title " + r.URL.Path
+		},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/page", nil)
+	req.Header.Set("User-Agent", userAgent)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	tracker.Stop()
+	pageViews := store.GetPageViews()
+	assert.Len(t, pageViews, 1)
+	assert.Equal(t, "Title /page", pageViews[0].Title)
+}
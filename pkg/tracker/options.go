@@ -2,6 +2,7 @@ package tracker
 
 import (
 	"github.com/pirsch-analytics/pirsch/v6/pkg/util"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -10,6 +11,7 @@ import (
 )
 
 // Options are optional parameters for page views and events.
+// The StatusCode is the HTTP status of the page (if known) and is stored for page views.
 type Options struct {
 	URL          string
 	Hostname     string
@@ -18,7 +20,11 @@ type Options struct {
 	Referrer     string
 	ScreenWidth  uint16
 	ScreenHeight uint16
+	StatusCode   uint16
 	Time         time.Time
+
+	// query is the query of the page URL, set by validate.
+	query url.Values
 }
 
 func (options *Options) validate(r *http.Request) {
@@ -30,6 +36,7 @@ func (options *Options) validate(r *http.Request) {
 
 	if err == nil {
 		options.Hostname = strings.ToLower(u.Hostname())
+		options.query = u.Query()
 
 		if options.Path != "" {
 			// change path and re-assemble URL
@@ -40,6 +47,19 @@ func (options *Options) validate(r *http.Request) {
 		}
 	}
 
+	if options.Hostname == "" {
+		// use the host of the request for server-side tracking
+		options.Hostname = strings.ToLower(r.Host)
+
+		if host, _, err := net.SplitHostPort(options.Hostname); err == nil {
+			options.Hostname = host
+		}
+	}
+
+	if options.query == nil {
+		options.query = r.URL.Query()
+	}
+
 	options.Title = util.ShortenString(options.Title, 512)
 	options.Path = util.ShortenString(options.Path, 2000)
 
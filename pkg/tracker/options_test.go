@@ -28,6 +28,14 @@ func TestOptions_validate(t *testing.T) {
 	options.validate(req)
 	assert.Equal(t, "https://example.com/new/path?query=parameter#anchor", options.URL)
 	assert.Equal(t, "example.com", options.Hostname)
+
+	req = httptest.NewRequest(http.MethodGet, "/foo", nil)
+	req.Host = "Example.com:8080"
+	options = Options{}
+	options.validate(req)
+	assert.Equal(t, "/foo", options.URL)
+	assert.Equal(t, "example.com", options.Hostname)
+	assert.Equal(t, "/foo", options.Path)
 }
 
 func TestOptionsFromRequest(t *testing.T) {
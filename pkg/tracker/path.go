@@ -0,0 +1,76 @@
+package tracker
+
+import (
+	"regexp"
+	"strings"
+)
+
+// TrailingSlash sets how trailing slashes are handled by the PathRules.
+type TrailingSlash int
+
+const (
+	// TrailingSlashKeep keeps the path as it is (default).
+	TrailingSlashKeep = TrailingSlash(iota)
+
+	// TrailingSlashRemove removes trailing slashes, like "/blog/" to "/blog".
+	TrailingSlashRemove
+
+	// TrailingSlashAdd adds a trailing slash to paths not ending with one, like "/blog" to "/blog/".
+	TrailingSlashAdd
+)
+
+// PathRewrite replaces all matches of the Pattern in a path with the Replacement.
+// The Replacement can reference capture groups, like "$1".
+type PathRewrite struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// PathRules normalize the path of page views and events before they are stored.
+// The zero value leaves the path unchanged.
+type PathRules struct {
+	// Lowercase converts the path to lowercase.
+	Lowercase bool
+
+	// TrailingSlash sets how trailing slashes are handled. The root path "/" is never changed.
+	TrailingSlash TrailingSlash
+
+	// IndexFiles is a list of file names removed from the end of the path, like "index.html" for "/blog/index.html" to "/blog/".
+	IndexFiles []string
+
+	// Rewrites are applied in order, like `^/user/\d+` to "/user/:id".
+	Rewrites []PathRewrite
+}
+
+// NormalizePath applies the PathRules to given path.
+// The rules are applied in this order: lowercasing, index file stripping, rewrites, and trailing slash handling.
+func NormalizePath(path string, rules PathRules) string {
+	if rules.Lowercase {
+		path = strings.ToLower(path)
+	}
+
+	for _, file := range rules.IndexFiles {
+		if strings.HasSuffix(path, "/"+file) {
+			path = path[:len(path)-len(file)]
+			break
+		}
+	}
+
+	for _, rewrite := range rules.Rewrites {
+		if rewrite.Pattern != nil {
+			path = rewrite.Pattern.ReplaceAllString(path, rewrite.Replacement)
+		}
+	}
+
+	if rules.TrailingSlash == TrailingSlashRemove {
+		path = strings.TrimRight(path, "/")
+	} else if rules.TrailingSlash == TrailingSlashAdd && !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+
+	if path == "" {
+		path = "/"
+	}
+
+	return path
+}
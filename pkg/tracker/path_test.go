@@ -0,0 +1,152 @@
+package tracker
+
+import (
+	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestNormalizePath(t *testing.T) {
+	rules := PathRules{
+		Lowercase:     true,
+		TrailingSlash: TrailingSlashRemove,
+		IndexFiles:    []string{"index.html", "index.php"},
+		Rewrites: []PathRewrite{
+			{Pattern: regexp.MustCompile(`^/user/\d+`), Replacement: "/user/:id"},
+			{Pattern: regexp.MustCompile(`^/post/(\w+)-\d+$`), Replacement: "/post/$1"},
+		},
+	}
+	input := []string{
+		"/",
+		"/Blog/",
+		"/blog/index.html",
+		"/index.php",
+		"/myindex.html",
+		"/user/12345/settings",
+		"/User/42",
+		"/user/me",
+		"/post/hello-123",
+		"//",
+	}
+	expected := []string{
+		"/",
+		"/blog",
+		"/blog",
+		"/",
+		"/myindex.html",
+		"/user/:id/settings",
+		"/user/:id",
+		"/user/me",
+		"/post/hello",
+		"/",
+	}
+
+	for i, path := range input {
+		assert.Equal(t, expected[i], NormalizePath(path, rules), path)
+	}
+
+	assert.Equal(t, "/Blog/Index.html", NormalizePath("/Blog/Index.html", PathRules{}))
+	assert.Equal(t, "/blog/", NormalizePath("/blog", PathRules{TrailingSlash: TrailingSlashAdd}))
+	assert.Equal(t, "/blog/", NormalizePath("/blog/index.html", PathRules{IndexFiles: []string{"index.html"}}))
+	assert.Equal(t, "/", NormalizePath("/", PathRules{TrailingSlash: TrailingSlashAdd}))
+}
+
+func TestTracker_PageViewPathRules(t *testing.T) {
+	client := db.NewClientMock()
+	tracker := NewTracker(Config{
+		Store: client,
+		Settings: SettingsProviderFunc(func(clientID uint64) (*ClientSettings, error) {
+			return &ClientSettings{
+				IgnorePaths: []string{"/admin"},
+				PathRules: &PathRules{
+					Lowercase:     true,
+					TrailingSlash: TrailingSlashRemove,
+					Rewrites: []PathRewrite{
+						{Pattern: regexp.MustCompile(`^/user/\d+`), Replacement: "/user/:id"},
+					},
+				},
+			}, nil
+		}),
+	})
+
+	for _, path := range []string{"/User/123/Settings/", "/user/456", "/Admin/"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("User-Agent", userAgent)
+		tracker.PageView(req, 0, Options{})
+	}
+
+	tracker.Stop()
+	pageViews := client.GetPageViews()
+	assert.Len(t, pageViews, 2)
+	assert.Equal(t, "/user/:id/settings", pageViews[0].Path)
+	assert.Equal(t, "/user/:id", pageViews[1].Path)
+	sessions := client.GetSessions()
+	assert.NotEmpty(t, sessions)
+	assert.Equal(t, "/user/:id/settings", sessions[0].EntryPath)
+	assert.Equal(t, "/user/:id", sessions[len(sessions)-1].ExitPath)
+	assert.Equal(t, uint64(1), tracker.Stats().Ignored[ReasonIgnoredPath])
+}
+
+func TestTracker_PageViewPathRulesMaxLength(t *testing.T) {
+	client := db.NewClientMock()
+	tracker := NewTracker(Config{
+		Store: client,
+		Settings: SettingsProviderFunc(func(clientID uint64) (*ClientSettings, error) {
+			return &ClientSettings{
+				PathRules: &PathRules{
+					Rewrites: []PathRewrite{
+						{Pattern: regexp.MustCompile(`^/long$`), Replacement: "/" + strings.Repeat("a", 2500)},
+					},
+				},
+			}, nil
+		}),
+	})
+	req := httptest.NewRequest(http.MethodGet, "/long", nil)
+	req.Header.Set("User-Agent", userAgent)
+	tracker.PageView(req, 0, Options{})
+	tracker.Event(req, 0, EventOptions{Name: "event"}, Options{})
+	tracker.Stop()
+	pageViews := client.GetPageViews()
+	events := client.GetEvents()
+	assert.Len(t, pageViews, 1)
+	assert.Len(t, events, 1)
+	assert.Len(t, pageViews[0].Path, 2000)
+	assert.Len(t, events[0].Path, 2000)
+}
+
+func TestTracker_PageViewConfigPathRules(t *testing.T) {
+	client := db.NewClientMock()
+	tracker := NewTracker(Config{
+		Store:     client,
+		PathRules: PathRules{Lowercase: true},
+		Settings: SettingsProviderFunc(func(clientID uint64) (*ClientSettings, error) {
+			if clientID == 1 {
+				return &ClientSettings{PathRules: &PathRules{}}, nil
+			}
+
+			return nil, nil
+		}),
+	})
+
+	for clientID := uint64(0); clientID < 2; clientID++ {
+		req := httptest.NewRequest(http.MethodGet, "/Blog", nil)
+		req.Header.Set("User-Agent", userAgent)
+		tracker.PageView(req, clientID, Options{})
+	}
+
+	tracker.Stop()
+	pageViews := client.GetPageViews()
+	assert.Len(t, pageViews, 2)
+
+	for _, pageView := range pageViews {
+		if pageView.ClientID == 1 {
+			assert.Equal(t, "/Blog", pageView.Path)
+		} else {
+			assert.Equal(t, "/blog", pageView.Path)
+		}
+	}
+}
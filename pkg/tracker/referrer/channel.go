@@ -0,0 +1,294 @@
+package referrer
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	// ChannelDirect is the channel for visitors without a referrer or campaign.
+	ChannelDirect = "Direct"
+
+	// ChannelOrganicSearch is the channel for visitors coming from a search engine.
+	ChannelOrganicSearch = "Organic Search"
+
+	// ChannelPaidSearch is the channel for visitors coming from search ads.
+	ChannelPaidSearch = "Paid Search"
+
+	// ChannelSocial is the channel for visitors coming from social networks.
+	ChannelSocial = "Social"
+
+	// ChannelEmail is the channel for visitors coming from emails and newsletters.
+	ChannelEmail = "Email"
+
+	// ChannelReferral is the channel for visitors coming from any other website.
+	ChannelReferral = "Referral"
+
+	// ChannelDisplay is the channel for visitors coming from display ads.
+	ChannelDisplay = "Display"
+)
+
+const (
+	categorySearch  = "search"
+	categorySocial  = "social"
+	categoryMail    = "mail"
+	categoryDisplay = "display"
+)
+
+// ClickID is a query parameter added to links by ad networks.
+type ClickID struct {
+	// Param is the name of the query parameter.
+	Param string
+
+	// Channel is the channel the click ID indicates.
+	Channel string
+
+	// Network is the name of the ad network using the click ID.
+	Network string
+
+	// Source is used as the utm_source in case it's not set.
+	Source string
+
+	// Medium is used as the utm_medium in case it's not set.
+	Medium string
+}
+
+// ClickIDs is the list of known click IDs.
+var ClickIDs = []ClickID{
+	{Param: "gclid", Channel: ChannelPaidSearch, Network: "Google Ads", Source: "google", Medium: "cpc"},
+	{Param: "gbraid", Channel: ChannelPaidSearch, Network: "Google Ads", Source: "google", Medium: "cpc"},
+	{Param: "wbraid", Channel: ChannelPaidSearch, Network: "Google Ads", Source: "google", Medium: "cpc"},
+	{Param: "msclkid", Channel: ChannelPaidSearch, Network: "Microsoft Ads", Source: "bing", Medium: "cpc"},
+	{Param: "dclid", Channel: ChannelDisplay, Network: "Google Display & Video 360", Source: "google", Medium: "display"},
+	{Param: "fbclid", Channel: ChannelSocial, Network: "Meta Ads", Source: "facebook", Medium: "paid_social"},
+	{Param: "ttclid", Channel: ChannelSocial, Network: "TikTok Ads", Source: "tiktok", Medium: "paid_social"},
+	{Param: "twclid", Channel: ChannelSocial, Network: "X Ads", Source: "twitter", Medium: "paid_social"},
+	{Param: "li_fat_id", Channel: ChannelSocial, Network: "LinkedIn Ads", Source: "linkedin", Medium: "paid_social"},
+}
+
+var (
+	//go:embed list.json
+	listJSON []byte
+
+	paidMedium    = regexp.MustCompile(`^(.*cpc|ppc|paid.*|retargeting)$`)
+	displayMedium = regexp.MustCompile(`^(display|banner|cpm|expandable|interstitial|programmatic)$`)
+	emailMedium   = regexp.MustCompile(`^(e[-_ ]?mail|newsletter)$`)
+	socialMedium  = regexp.MustCompile(`^(social|social[-_ ]?network|social[-_ ]?media|sm)$`)
+	searchMedium  = regexp.MustCompile(`^organic$`)
+
+	// categories maps lowercase referrer names (as returned by Get) and common UTM sources to the category of the referrer.
+	// It's extended by the search, social, and mail groups in list.json.
+	categories = map[string]string{
+		"google":                categorySearch,
+		"google images":         categorySearch,
+		"google news":           categorySearch,
+		"google product search": categorySearch,
+		"bing":                  categorySearch,
+		"yahoo!":                categorySearch,
+		"yahoo":                 categorySearch,
+		"yahoo! images":         categorySearch,
+		"duckduckgo":            categorySearch,
+		"yandex":                categorySearch,
+		"yandex images":         categorySearch,
+		"baidu":                 categorySearch,
+		"ecosia":                categorySearch,
+		"qwant":                 categorySearch,
+		"naver":                 categorySearch,
+		"seznam":                categorySearch,
+		"ask":                   categorySearch,
+		"aol":                   categorySearch,
+		"startpage":             categorySearch,
+		"brave search":          categorySearch,
+		"facebook":              categorySocial,
+		"fb":                    categorySocial,
+		"twitter":               categorySocial,
+		"x":                     categorySocial,
+		"t.co":                  categorySocial,
+		"instagram":             categorySocial,
+		"ig":                    categorySocial,
+		"linkedin":              categorySocial,
+		"pinterest":             categorySocial,
+		"reddit":                categorySocial,
+		"youtube":               categorySocial,
+		"tiktok":                categorySocial,
+		"vkontakte":             categorySocial,
+		"vk":                    categorySocial,
+		"tumblr":                categorySocial,
+		"mastodon":              categorySocial,
+		"hacker news":           categorySocial,
+		"quora":                 categorySocial,
+		"snapchat":              categorySocial,
+		"threads":               categorySocial,
+		"bluesky":               categorySocial,
+		"whatsapp":              categorySocial,
+		"telegram":              categorySocial,
+		"discord":               categorySocial,
+		"weibo":                 categorySocial,
+		"twitch":                categorySocial,
+		"xing":                  categorySocial,
+		"gmail":                 categoryMail,
+		"outlook":               categoryMail,
+		"yahoo! mail":           categoryMail,
+		"email":                 categoryMail,
+		"newsletter":            categoryMail,
+		"doubleclick":           categoryDisplay,
+		"appnexus":              categoryDisplay,
+		"taboola":               categoryDisplay,
+		"outbrain":              categoryDisplay,
+		"adform":                categoryDisplay,
+		"adroll":                categoryDisplay,
+		"criteo":                categoryDisplay,
+		"openx":                 categoryDisplay,
+		"zedo":                  categoryDisplay,
+	}
+)
+
+func init() {
+	var list map[string]map[string]struct {
+		Domains []string `json:"domains"`
+	}
+
+	if err := json.Unmarshal(listJSON, &list); err != nil {
+		panic(err)
+	}
+
+	for _, category := range []string{categorySearch, categorySocial, categoryMail} {
+		for name := range list[category] {
+			categories[strings.ToLower(name)] = category
+		}
+	}
+}
+
+// ChannelData is the data a channel is classified from.
+type ChannelData struct {
+	// Referrer is the full referrer URL.
+	Referrer string
+
+	// ReferrerName is the name of the referrer (like "Google") or the hostname in case it's unknown.
+	ReferrerName string
+
+	// UTMSource is the utm_source query parameter.
+	UTMSource string
+
+	// UTMMedium is the utm_medium query parameter.
+	UTMMedium string
+
+	// ClickID is the name of the click ID query parameter (like "gclid"), see ClickIDs.
+	ClickID string
+}
+
+// ChannelRule returns the channel for given data, or an empty string in case the rule does not apply.
+type ChannelRule func(ChannelData) string
+
+// DefaultChannelRules are the rules used by Channel in this order.
+// The first rule returning a channel wins. Visitors not matching any rule are classified as ChannelDirect.
+var DefaultChannelRules = []ChannelRule{
+	ClickIDChannelRule,
+	MediumChannelRule,
+	SourceChannelRule,
+}
+
+// ClickIDChannelRule classifies visitors by the click ID added by ad networks.
+func ClickIDChannelRule(data ChannelData) string {
+	for _, id := range ClickIDs {
+		if id.Param == data.ClickID {
+			return id.Channel
+		}
+	}
+
+	return ""
+}
+
+// MediumChannelRule classifies visitors by the common utm_medium conventions, like "cpc", "email", or "social".
+// Paid traffic from social networks is classified as ChannelSocial.
+func MediumChannelRule(data ChannelData) string {
+	medium := strings.ToLower(strings.TrimSpace(data.UTMMedium))
+
+	if medium == "" {
+		return ""
+	}
+
+	switch {
+	case paidMedium.MatchString(medium):
+		if category(data) == categorySocial {
+			return ChannelSocial
+		}
+
+		return ChannelPaidSearch
+	case displayMedium.MatchString(medium):
+		return ChannelDisplay
+	case emailMedium.MatchString(medium):
+		return ChannelEmail
+	case socialMedium.MatchString(medium):
+		return ChannelSocial
+	case searchMedium.MatchString(medium):
+		return ChannelOrganicSearch
+	}
+
+	return ""
+}
+
+// SourceChannelRule classifies visitors by the referrer name or utm_source.
+// Known search engines, social networks, email providers, and ad networks are classified as such, everything else as ChannelReferral.
+func SourceChannelRule(data ChannelData) string {
+	switch category(data) {
+	case categorySearch:
+		return ChannelOrganicSearch
+	case categorySocial:
+		return ChannelSocial
+	case categoryMail:
+		return ChannelEmail
+	case categoryDisplay:
+		return ChannelDisplay
+	}
+
+	if data.Referrer != "" || data.ReferrerName != "" || data.UTMSource != "" {
+		return ChannelReferral
+	}
+
+	return ""
+}
+
+// Channel returns the channel for given data using the rules. The DefaultChannelRules are applied after the rules.
+func Channel(data ChannelData, rules ...ChannelRule) string {
+	for _, rule := range rules {
+		if channel := rule(data); channel != "" {
+			return channel
+		}
+	}
+
+	for _, rule := range DefaultChannelRules {
+		if channel := rule(data); channel != "" {
+			return channel
+		}
+	}
+
+	return ChannelDirect
+}
+
+// GetClickID returns the first click ID found in given query and its value.
+// The ClickID is empty in case the query does not contain a click ID.
+func GetClickID(query url.Values) (ClickID, string) {
+	for _, id := range ClickIDs {
+		if value := strings.TrimSpace(query.Get(id.Param)); value != "" {
+			return id, value
+		}
+	}
+
+	return ClickID{}, ""
+}
+
+func category(data ChannelData) string {
+	if c := categories[strings.ToLower(data.ReferrerName)]; c != "" {
+		return c
+	}
+
+	if c := categories[strings.ToLower(strings.TrimSpace(data.UTMSource))]; c != "" {
+		return c
+	}
+
+	return ""
+}
@@ -0,0 +1,88 @@
+package referrer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+)
+
+func TestChannel(t *testing.T) {
+	input := []ChannelData{
+		{},
+		{Referrer: "https://www.google.com", ReferrerName: "Google"},
+		{ReferrerName: "Google", ClickID: "gclid"},
+		{ReferrerName: "Google", UTMSource: "google", UTMMedium: "cpc"},
+		{UTMSource: "facebook", UTMMedium: "paid_social"},
+		{UTMSource: "newsletter", UTMMedium: "email"},
+		{UTMSource: "Newsletter"},
+		{Referrer: "https://www.facebook.com", ReferrerName: "Facebook"},
+		{ReferrerName: "Facebook", ClickID: "fbclid"},
+		{Referrer: "https://www.linkedin.com", ReferrerName: "LinkedIn"},
+		{Referrer: "https://search.brave.com", ReferrerName: "Brave Search"},
+		{ReferrerName: "GMX"},
+		{UTMMedium: "banner"},
+		{ClickID: "dclid"},
+		{ReferrerName: "Doubleclick"},
+		{Referrer: "https://example.com", ReferrerName: "example.com"},
+		{UTMSource: "partner"},
+		{UTMMedium: "organic", UTMSource: "partner"},
+	}
+	expected := []string{
+		ChannelDirect,
+		ChannelOrganicSearch,
+		ChannelPaidSearch,
+		ChannelPaidSearch,
+		ChannelSocial,
+		ChannelEmail,
+		ChannelEmail,
+		ChannelSocial,
+		ChannelSocial,
+		ChannelSocial,
+		ChannelOrganicSearch,
+		ChannelEmail,
+		ChannelDisplay,
+		ChannelDisplay,
+		ChannelDisplay,
+		ChannelReferral,
+		ChannelReferral,
+		ChannelOrganicSearch,
+	}
+
+	for i, data := range input {
+		assert.Equal(t, expected[i], Channel(data), i)
+	}
+}
+
+func TestChannelCustomRules(t *testing.T) {
+	partner := func(data ChannelData) string {
+		if data.UTMSource == "partner" {
+			return "Affiliate"
+		}
+
+		return ""
+	}
+	assert.Equal(t, "Affiliate", Channel(ChannelData{UTMSource: "partner"}, partner))
+	assert.Equal(t, ChannelOrganicSearch, Channel(ChannelData{ReferrerName: "Google"}, partner))
+	assert.Equal(t, ChannelDirect, Channel(ChannelData{}, partner))
+}
+
+func TestGetClickID(t *testing.T) {
+	id, value := GetClickID(url.Values{})
+	assert.Empty(t, id.Param)
+	assert.Empty(t, value)
+	id, value = GetClickID(url.Values{"gclid": {" "}})
+	assert.Empty(t, id.Param)
+	assert.Empty(t, value)
+	id, value = GetClickID(url.Values{"gclid": {"abc"}})
+	assert.Equal(t, "gclid", id.Param)
+	assert.Equal(t, "Google Ads", id.Network)
+	assert.Equal(t, "abc", value)
+	id, value = GetClickID(url.Values{"fbclid": {"def"}, "gclid": {"abc"}})
+	assert.Equal(t, "gclid", id.Param)
+	assert.Equal(t, "abc", value)
+	id, value = GetClickID(url.Values{"msclkid": {"abc"}})
+	assert.Equal(t, "msclkid", id.Param)
+	assert.Equal(t, "Microsoft Ads", id.Network)
+	assert.Equal(t, "bing", id.Source)
+	assert.Equal(t, "cpc", id.Medium)
+}
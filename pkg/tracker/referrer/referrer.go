@@ -12,6 +12,8 @@ import (
 )
 
 // QueryParams is a list of query parameters to set the referrer.
+//
+// Deprecated: QueryParams is only used by Get and Ignore. Use GetWithParams and IgnoreWithParams instead.
 var QueryParams = []string{
 	"ref",
 	"referer",
@@ -23,8 +25,16 @@ var QueryParams = []string{
 var isDomain = regexp.MustCompile("^.*\\.[a-zA-Z]+$")
 
 // Ignore returns whether a referrer should be ignored or not.
+// The QueryParams of the request URL are checked in case the Referer header is not set.
 func Ignore(r *http.Request) bool {
-	referrer := getFromHeaderOrQuery(r)
+	return IgnoreWithParams(r, r.URL.Query(), QueryParams)
+}
+
+// IgnoreWithParams returns whether a referrer should be ignored or not.
+// The queryParams are looked up in order in given query in case the Referer header is not set.
+// If queryParams is nil, only the Referer header is checked.
+func IgnoreWithParams(r *http.Request, query url.Values, queryParams []string) bool {
+	referrer := getFromHeaderOrQuery(r, query, queryParams)
 
 	if referrer == "" {
 		return false
@@ -42,13 +52,22 @@ func Ignore(r *http.Request) bool {
 }
 
 // Get returns the referrer for given request.
+// The QueryParams of the request URL are checked in case ref is empty and the Referer header is not set.
 func Get(r *http.Request, ref, requestHostname string) (string, string, string) {
+	return GetWithParams(r, r.URL.Query(), ref, requestHostname, QueryParams)
+}
+
+// GetWithParams returns the referrer for given request.
+// The queryParams are looked up in order in given query in case ref is empty and the Referer header is not set.
+// If queryParams is nil, only the Referer header is checked.
+// The tracker passes the query of the tracked page and the Referrer and Source parameters of tracker.CampaignParams.
+func GetWithParams(r *http.Request, query url.Values, ref, requestHostname string, queryParams []string) (string, string, string) {
 	referrer := ""
 
 	if ref != "" {
 		referrer = ref
 	} else {
-		referrer = getFromHeaderOrQuery(r)
+		referrer = getFromHeaderOrQuery(r, query, queryParams)
 	}
 
 	if referrer == "" {
@@ -116,12 +135,12 @@ func Get(r *http.Request, ref, requestHostname string) (string, string, string)
 	return u.String(), name, ""
 }
 
-func getFromHeaderOrQuery(r *http.Request) string {
+func getFromHeaderOrQuery(r *http.Request, query url.Values, queryParams []string) string {
 	referrer := r.Header.Get("Referer")
 
 	if referrer == "" {
-		for _, param := range QueryParams {
-			referrer = r.URL.Query().Get(param)
+		for _, param := range queryParams {
+			referrer = query.Get(param)
 
 			if referrer != "" {
 				return referrer
@@ -5,6 +5,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 )
 
@@ -180,8 +181,26 @@ func TestGetFromHeaderOrQuery(t *testing.T) {
 
 	for i, in := range input {
 		r := httptest.NewRequest(http.MethodGet, "/?"+in[0]+"="+in[1], nil)
-		assert.Equal(t, expected[i], getFromHeaderOrQuery(r))
+		assert.Equal(t, expected[i], getFromHeaderOrQuery(r, r.URL.Query(), QueryParams))
 	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?mtm_source=domain&ref=other", nil)
+	assert.Equal(t, "other", getFromHeaderOrQuery(r, r.URL.Query(), QueryParams))
+	assert.Equal(t, "domain", getFromHeaderOrQuery(r, r.URL.Query(), []string{"mtm_source", "ref"}))
+	assert.Empty(t, getFromHeaderOrQuery(r, r.URL.Query(), nil))
+}
+
+func TestGetWithParams(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/?ref=ignored.com", nil)
+	query := url.Values{"mtm_source": []string{"https://www.google.com/"}}
+	referrer, referrerName, _ := GetWithParams(r, query, "", "", []string{"mtm_source", "ref"})
+	assert.Equal(t, "https://www.google.com", referrer)
+	assert.Equal(t, "Google", referrerName)
+	referrer, referrerName, _ = GetWithParams(r, query, "", "", nil)
+	assert.Empty(t, referrer)
+	assert.Empty(t, referrerName)
+	assert.False(t, IgnoreWithParams(r, query, []string{"mtm_source"}))
+	assert.True(t, IgnoreWithParams(r, url.Values{"source": []string{"https://semalt.com/"}}, []string{"source"}))
 }
 
 func TestStripSubdomain(t *testing.T) {
@@ -0,0 +1,373 @@
+package tracker
+
+import (
+	"github.com/pirsch-analytics/pirsch/v6/pkg"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/model"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/geodb"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/ip"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/referrer"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/ua"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/util"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ReasonDoNotTrack is returned for requests with the DNT header set.
+	ReasonDoNotTrack = "dnt"
+
+	// ReasonUserAgentLength is returned for User-Agents that are empty, too short, or too long.
+	ReasonUserAgentLength = "user_agent_length"
+
+	// ReasonUserAgentNonASCII is returned for User-Agents containing non-ASCII characters.
+	ReasonUserAgentNonASCII = "user_agent_non_ascii"
+
+	// ReasonUserAgentIP is returned for User-Agents that are an IP address.
+	ReasonUserAgentIP = "user_agent_ip"
+
+	// ReasonPrefetch is returned for requests made by browsers pre-fetching data.
+	ReasonPrefetch = "prefetch"
+
+	// ReasonReferrerSpam is returned for requests from referrer spammers.
+	ReasonReferrerSpam = "referrer_spam"
+
+	// ReasonBrowserVersion is returned for browsers older than the configured minimum version.
+	ReasonBrowserVersion = "browser_version"
+
+	// ReasonUserAgentBlacklist is returned for User-Agents containing a bot keyword.
+	ReasonUserAgentBlacklist = "user_agent_blacklist"
+
+	// ReasonIPFilter is returned for IPs ignored by the ip.Filter.
+	ReasonIPFilter = "ip_filter"
+
+	// ReasonASN is returned for IPs belonging to an ignored autonomous system, like a cloud provider.
+	ReasonASN = "asn"
+
+	minUserAgentLength = 10
+	maxUserAgentLength = 300
+
+	minChromeVersion  = 70 // late 2019
+	minFirefoxVersion = 68 // mid 2019
+	minSafariVersion  = 12 // late 2018
+	minOperaVersion   = 65 // late 2019
+	minEdgeVersion    = 88 // late 2020
+	minIEVersion      = 11 // late 2013
+)
+
+// Rule decides whether a request is ignored.
+// Ignored requests are stored as a model.Bot together with the reason.
+type Rule interface {
+	// Ignore returns a non-empty reason code if the request should be ignored.
+	Ignore(*Request) string
+}
+
+// RuleFunc is a function implementing the Rule interface.
+type RuleFunc func(*Request) string
+
+// Ignore implements the Rule interface.
+func (f RuleFunc) Ignore(r *Request) string {
+	return f(r)
+}
+
+// Request is passed to the Rules to decide whether the request should be ignored.
+// The User-Agent and IP are parsed when they are accessed for the first time.
+type Request struct {
+	// Request is the original HTTP request.
+	Request *http.Request
+
+	// ClientID is the client the request is tracked for.
+	ClientID uint64
+
+	// Settings are the ClientSettings for the client.
+	Settings *ClientSettings
+
+	headerParser        []ip.HeaderParser
+	allowedProxySubnets []net.IPNet
+	referrerParams      []string
+	query               url.Values
+	asnDB               geodb.ASNLookup
+	userAgent           *model.UserAgent
+	ip                  *string
+	asn                 *asn
+}
+
+type asn struct {
+	number       uint32
+	organization string
+}
+
+// UserAgent returns the parsed User-Agent.
+func (r *Request) UserAgent() model.UserAgent {
+	if r.userAgent == nil {
+		userAgent := ua.Parse(r.Request)
+		r.userAgent = &userAgent
+	}
+
+	return *r.userAgent
+}
+
+// IP returns the IP address of the visitor.
+func (r *Request) IP() string {
+	if r.ip == nil {
+		ipAddress := ip.Get(r.Request, r.headerParser, r.allowedProxySubnets)
+		r.ip = &ipAddress
+	}
+
+	return *r.ip
+}
+
+// ASN returns the autonomous system number and organization name of the visitor's IP address.
+// 0 and an empty string are returned if no Config.ASNDB is configured or the IP cannot be found.
+func (r *Request) ASN() (uint32, string) {
+	if r.asn == nil {
+		r.asn = new(asn)
+
+		if r.asnDB != nil {
+			r.asn.number, r.asn.organization = r.asnDB.GetASN(r.IP())
+		}
+	}
+
+	return r.asn.number, r.asn.organization
+}
+
+// DefaultRules returns the rules used if no rules are configured, in the order they are applied.
+// The ip.Filter is optional.
+func DefaultRules(filter ip.Filter) []Rule {
+	rules := []Rule{
+		DoNotTrackRule{},
+		UserAgentRule{},
+		UserAgentIPRule{},
+		PrefetchRule{},
+		ReferrerSpamRule{},
+		DefaultBrowserVersionRule(),
+		UserAgentBlacklistRule{},
+	}
+
+	if filter != nil {
+		rules = append(rules, IPFilterRule{Filter: filter})
+	}
+
+	return rules
+}
+
+// DoNotTrackRule respects the do not track header.
+type DoNotTrackRule struct{}
+
+// Ignore implements the Rule interface.
+func (rule DoNotTrackRule) Ignore(r *Request) string {
+	if r.Request.Header.Get("DNT") == "1" {
+		return ReasonDoNotTrack
+	}
+
+	return ""
+}
+
+// UserAgentRule ignores empty User-Agents, User-Agents of unusual length, and User-Agents containing non-ASCII characters, as they are usually bots.
+// If MinLength or MaxLength is set to <= 0, the default values of 10 and 300 will be used.
+type UserAgentRule struct {
+	MinLength int
+	MaxLength int
+}
+
+// Ignore implements the Rule interface.
+func (rule UserAgentRule) Ignore(r *Request) string {
+	minLength, maxLength := rule.MinLength, rule.MaxLength
+
+	if minLength <= 0 {
+		minLength = minUserAgentLength
+	}
+
+	if maxLength <= 0 {
+		maxLength = maxUserAgentLength
+	}
+
+	userAgent := strings.TrimSpace(strings.ToLower(r.Request.UserAgent()))
+
+	if userAgent == "" || len(userAgent) < minLength || len(userAgent) > maxLength {
+		return ReasonUserAgentLength
+	}
+
+	if util.ContainsNonASCIICharacters(userAgent) {
+		return ReasonUserAgentNonASCII
+	}
+
+	return ""
+}
+
+// UserAgentIPRule ignores User-Agents that are an IP address.
+type UserAgentIPRule struct{}
+
+// Ignore implements the Rule interface.
+func (rule UserAgentIPRule) Ignore(r *Request) string {
+	host := r.Request.UserAgent()
+
+	if net.ParseIP(host) != nil {
+		return ReasonUserAgentIP
+	}
+
+	if strings.Contains(host, ":") {
+		host, _, _ = net.SplitHostPort(host)
+	}
+
+	if net.ParseIP(host) != nil {
+		return ReasonUserAgentIP
+	}
+
+	return ""
+}
+
+// PrefetchRule ignores browsers pre-fetching data.
+type PrefetchRule struct{}
+
+// Ignore implements the Rule interface.
+func (rule PrefetchRule) Ignore(r *Request) string {
+	xPurpose := r.Request.Header.Get("X-Purpose")
+	purpose := r.Request.Header.Get("Purpose")
+
+	if r.Request.Header.Get("X-Moz") == "prefetch" ||
+		xPurpose == "prefetch" ||
+		xPurpose == "preview" ||
+		purpose == "prefetch" ||
+		purpose == "preview" {
+		return ReasonPrefetch
+	}
+
+	return ""
+}
+
+// ReferrerSpamRule filters referrer spammers.
+type ReferrerSpamRule struct{}
+
+// Ignore implements the Rule interface.
+func (rule ReferrerSpamRule) Ignore(r *Request) string {
+	if referrer.IgnoreWithParams(r.Request, r.query, r.referrerParams) {
+		return ReasonReferrerSpam
+	}
+
+	return ""
+}
+
+// BrowserVersionRule ignores browsers older than the configured major version.
+// Browsers with a minimum version of 0 are not checked.
+// The versions are overridden by ClientSettings.MinBrowserVersions if set.
+type BrowserVersionRule struct {
+	Chrome  int
+	Firefox int
+	Safari  int
+	Opera   int
+	Edge    int
+	IE      int
+}
+
+// DefaultBrowserVersionRule returns a BrowserVersionRule for the default minimum browser versions.
+func DefaultBrowserVersionRule() BrowserVersionRule {
+	return BrowserVersionRule{
+		Chrome:  minChromeVersion,
+		Firefox: minFirefoxVersion,
+		Safari:  minSafariVersion,
+		Opera:   minOperaVersion,
+		Edge:    minEdgeVersion,
+		IE:      minIEVersion,
+	}
+}
+
+// Ignore implements the Rule interface.
+func (rule BrowserVersionRule) Ignore(r *Request) string {
+	if r.Settings != nil && r.Settings.MinBrowserVersions != nil {
+		rule = *r.Settings.MinBrowserVersions
+	}
+
+	userAgent := r.UserAgent()
+
+	if rule.versionBefore(userAgent.Browser, userAgent.BrowserVersion) {
+		return ReasonBrowserVersion
+	}
+
+	return ""
+}
+
+func (rule BrowserVersionRule) versionBefore(browser, version string) bool {
+	return version != "" &&
+		browser == pkg.BrowserChrome && browserVersionBefore(version, rule.Chrome) ||
+		browser == pkg.BrowserFirefox && browserVersionBefore(version, rule.Firefox) ||
+		browser == pkg.BrowserSafari && browserVersionBefore(version, rule.Safari) ||
+		browser == pkg.BrowserOpera && browserVersionBefore(version, rule.Opera) ||
+		browser == pkg.BrowserEdge && browserVersionBefore(version, rule.Edge) ||
+		browser == pkg.BrowserIE && browserVersionBefore(version, rule.IE)
+}
+
+// UserAgentBlacklistRule filters User-Agents containing bot keywords from ua.Blacklist.
+type UserAgentBlacklistRule struct{}
+
+// Ignore implements the Rule interface.
+func (rule UserAgentBlacklistRule) Ignore(r *Request) string {
+	userAgent := strings.TrimSpace(strings.ToLower(r.Request.UserAgent()))
+
+	for _, botUserAgent := range ua.Blacklist {
+		if strings.Contains(userAgent, botUserAgent) {
+			return ReasonUserAgentBlacklist
+		}
+	}
+
+	return ""
+}
+
+// IPFilterRule ignores IPs using an ip.Filter.
+type IPFilterRule struct {
+	Filter ip.Filter
+}
+
+// Ignore implements the Rule interface.
+func (rule IPFilterRule) Ignore(r *Request) string {
+	if rule.Filter != nil && rule.Filter.Ignore(r.IP()) {
+		return ReasonIPFilter
+	}
+
+	return ""
+}
+
+// ASNRule ignores IPs from autonomous systems, like cloud providers, by number or organization name.
+// It requires the Config.ASNDB to be set.
+type ASNRule struct {
+	ASNs          []uint32
+	Organizations []*regexp.Regexp
+}
+
+// Ignore implements the Rule interface.
+func (rule ASNRule) Ignore(r *Request) string {
+	if len(rule.ASNs) == 0 && len(rule.Organizations) == 0 {
+		return ""
+	}
+
+	number, organization := r.ASN()
+
+	if matchASN(rule.ASNs, rule.Organizations, number, organization) {
+		return ReasonASN
+	}
+
+	return ""
+}
+
+func browserVersionBefore(version string, min int) bool {
+	if min <= 0 {
+		return false
+	}
+
+	i := strings.Index(version, ".")
+
+	if i >= 0 {
+		version = version[:i]
+	}
+
+	v, err := strconv.Atoi(version)
+
+	if err != nil {
+		return false
+	}
+
+	return v < min
+}
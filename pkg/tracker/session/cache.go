@@ -22,6 +22,24 @@ type Cache interface {
 	NewMutex(uint64, uint64) sync.Locker
 }
 
+// Stats are the statistics of a Cache.
+type Stats struct {
+	// Hits is the number of sessions found in the cache.
+	Hits uint64 `json:"hits"`
+
+	// Misses is the number of sessions not found in the cache.
+	Misses uint64 `json:"misses"`
+
+	// StoreFallbacks is the number of times the Store has been queried on a cache miss.
+	StoreFallbacks uint64 `json:"store_fallbacks"`
+}
+
+// StatsProvider is implemented by caches that keep Stats.
+type StatsProvider interface {
+	// Stats returns the Stats for the cache.
+	Stats() Stats
+}
+
 func getSessionKey(clientID, fingerprint uint64) string {
 	return fmt.Sprintf("%d_%d", clientID, fingerprint)
 }
@@ -4,6 +4,7 @@ import (
 	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
 	"github.com/pirsch-analytics/pirsch/v6/pkg/model"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -18,6 +19,8 @@ type MemCache struct {
 	maxSessions int
 	client      db.Store
 	m           sync.RWMutex
+	hits        atomic.Uint64
+	misses      atomic.Uint64
 }
 
 // NewMemCache creates a new cache for given client and maximum size.
@@ -41,9 +44,11 @@ func (cache *MemCache) Get(clientID, fingerprint uint64, maxAge time.Time) *mode
 	cache.m.RUnlock()
 
 	if found && session.Time.After(maxAge) {
+		cache.hits.Add(1)
 		return &session
 	}
 
+	cache.misses.Add(1)
 	s, _ := cache.client.Session(clientID, fingerprint, maxAge)
 	return s
 }
@@ -72,6 +77,17 @@ func (cache *MemCache) Clear() {
 	cache.sessions = make(map[string]model.Session)
 }
 
+// Stats implements the StatsProvider interface.
+// Each miss falls back to the Store.
+func (cache *MemCache) Stats() Stats {
+	misses := cache.misses.Load()
+	return Stats{
+		Hits:           cache.hits.Load(),
+		Misses:         misses,
+		StoreFallbacks: misses,
+	}
+}
+
 // NewMutex implements the Cache interface.
 func (cache *MemCache) NewMutex(uint64, uint64) sync.Locker {
 	return new(sync.Mutex)
@@ -97,3 +97,15 @@ func TestMemCache_Put(t *testing.T) {
 	session := cache.Get(1, 1, now.Add(-time.Second*10))
 	assert.Equal(t, "/", session.EntryPath)
 }
+
+func TestMemCacheStats(t *testing.T) {
+	cache := NewMemCache(db.NewClientMock(), 10)
+	assert.Nil(t, cache.Get(1, 1, time.Now().Add(-time.Minute)))
+	cache.Put(1, 1, &model.Session{Time: time.Now()})
+	assert.NotNil(t, cache.Get(1, 1, time.Now().Add(-time.Minute)))
+	assert.NotNil(t, cache.Get(1, 1, time.Now().Add(-time.Minute)))
+	stats := cache.Stats()
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, uint64(1), stats.StoreFallbacks)
+}
@@ -10,6 +10,7 @@ import (
 	"log/slog"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -19,6 +20,8 @@ type RedisCache struct {
 	rds    *redis.Client
 	rs     *redsync.Redsync
 	logger *slog.Logger
+	hits   atomic.Uint64
+	misses atomic.Uint64
 }
 
 // RedisMutex wraps a redis mutex.
@@ -62,6 +65,7 @@ func (cache *RedisCache) Get(clientID, fingerprint uint64, _ time.Time) *model.S
 			cache.logger.Error("error reading session from cache", "err", err)
 		}
 
+		cache.misses.Add(1)
 		return nil
 	}
 
@@ -69,12 +73,23 @@ func (cache *RedisCache) Get(clientID, fingerprint uint64, _ time.Time) *model.S
 
 	if err := json.Unmarshal([]byte(r), &session); err != nil {
 		cache.logger.Error("error unmarshalling session from cache", "err", err)
+		cache.misses.Add(1)
 		return nil
 	}
 
+	cache.hits.Add(1)
 	return &session
 }
 
+// Stats implements the StatsProvider interface.
+// The RedisCache does not fall back to the Store.
+func (cache *RedisCache) Stats() Stats {
+	return Stats{
+		Hits:   cache.hits.Load(),
+		Misses: cache.misses.Load(),
+	}
+}
+
 // Put implements the Cache interface.
 func (cache *RedisCache) Put(clientID, fingerprint uint64, session *model.Session) {
 	v, err := json.Marshal(session)
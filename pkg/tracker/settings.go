@@ -0,0 +1,309 @@
+package tracker
+
+import (
+	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/referrer"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/util"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ReasonIgnoredPath is counted for requests to a path ignored by the ClientSettings.
+	// These requests are dropped and not stored as bots.
+	ReasonIgnoredPath = "ignored_path"
+
+	// ReasonHostname is counted for requests to a hostname not allowed by the ClientSettings.
+	// These requests are dropped and not stored as bots.
+	ReasonHostname = "hostname"
+
+	defaultSettingsCacheTTL = time.Minute * 5
+
+	// settingsErrorCacheTTL is how long the fallback settings are cached in case the SettingsProvider returns an error,
+	// so that a failing provider is not requested for every hit.
+	settingsErrorCacheTTL = time.Second * 10
+)
+
+// ClientSettings are the tracker settings for a single client.
+// The zero value uses the defaults of the Tracker.
+type ClientSettings struct {
+	// SessionTimeout sets the time after which a session without activity ends.
+	// Make sure the session.Cache keeps sessions at least as long as the longest timeout.
+	// If set to <= 0, the default value of 30 minutes will be used.
+	SessionTimeout time.Duration
+
+	// MaxPageViews sets the maximum number of page views per session. Page views exceeding the limit are dropped.
+	// If set to 0, Config.MaxPageViews will be used.
+	MaxPageViews uint16
+
+	// DisableSessionSplit disables starting a new session in case the referrer or UTM parameters change within a session.
+	DisableSessionSplit bool
+
+	// MinBrowserVersions overrides the minimum browser versions of the BrowserVersionRule.
+	// If nil, the versions configured for the rule will be used.
+	MinBrowserVersions *BrowserVersionRule
+
+	// IgnorePaths is a list of paths that are not tracked.
+	// Paths ending with an asterisk ignore all paths starting with the prefix, like "/admin/*".
+	IgnorePaths []string
+
+	// AllowedHostnames is a list of hostnames hits are accepted for. Hits for other hostnames are dropped.
+	// Hostnames starting with "*." allow all subdomains, like "*.example.com" for "www.example.com" (but not "example.com" itself).
+	// All hostnames are allowed if empty.
+	AllowedHostnames []string
+
+	// AllowLocalhost allows hits for localhost and IP addresses in addition to the AllowedHostnames, for development.
+	AllowLocalhost bool
+
+	// PathRules normalize the path before the IgnorePaths are checked and the page view or event is stored.
+	// If nil, Config.PathRules will be used.
+	PathRules *PathRules
+
+	// QueryParams is a list of query parameter names stored for page views and events.
+	// If nil, Config.QueryParams will be used.
+	QueryParams []string
+
+	// SearchParams is a list of query parameter names used for the site search.
+	// If nil, Config.SearchParams will be used.
+	SearchParams []string
+
+	// ChannelRules are applied before the referrer.DefaultChannelRules to classify the channel of sessions.
+	// If nil, Config.ChannelRules will be used.
+	ChannelRules []referrer.ChannelRule
+}
+
+// drop returns the reason in case the hit must be dropped because of the settings.
+func (settings *ClientSettings) drop(options *Options) string {
+	if !settings.allowHostname(options.Hostname) {
+		return ReasonHostname
+	}
+
+	if settings.ignorePath(options.Path) {
+		return ReasonIgnoredPath
+	}
+
+	return ""
+}
+
+func (settings *ClientSettings) allowHostname(hostname string) bool {
+	if len(settings.AllowedHostnames) == 0 {
+		return true
+	}
+
+	hostname = strings.TrimSuffix(strings.Trim(strings.ToLower(hostname), "[]"), ".")
+
+	if hostname == "" {
+		return false
+	}
+
+	if settings.AllowLocalhost && (hostname == "localhost" || strings.HasSuffix(hostname, ".localhost") || net.ParseIP(hostname) != nil) {
+		return true
+	}
+
+	for _, allowed := range settings.AllowedHostnames {
+		allowed = strings.ToLower(allowed)
+
+		if strings.HasPrefix(allowed, "*.") {
+			if strings.HasSuffix(hostname, allowed[1:]) {
+				return true
+			}
+		} else if hostname == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (settings *ClientSettings) ignorePath(path string) bool {
+	for _, p := range settings.IgnorePaths {
+		if strings.HasSuffix(p, "*") {
+			if strings.HasPrefix(path, p[:len(p)-1]) {
+				return true
+			}
+		} else if path == p {
+			return true
+		}
+	}
+
+	return false
+}
+
+// queryParams returns the keys and values of the allowed query parameters found in given query.
+// Only the first value is used for parameters set multiple times. Empty values are skipped.
+func (settings *ClientSettings) queryParams(query url.Values) ([]string, []string) {
+	if len(settings.QueryParams) == 0 || len(query) == 0 {
+		return nil, nil
+	}
+
+	keys, values := make([]string, 0, len(settings.QueryParams)), make([]string, 0, len(settings.QueryParams))
+
+	for _, key := range settings.QueryParams {
+		value := util.ShortenString(strings.TrimSpace(query.Get(key)), 200)
+
+		if value != "" {
+			keys = append(keys, key)
+			values = append(values, value)
+		}
+	}
+
+	return keys, values
+}
+
+// searchTerm returns the normalized search term for the first search parameter found in given query.
+// The term is converted to lowercase and whitespace is collapsed.
+func (settings *ClientSettings) searchTerm(query url.Values) string {
+	for _, key := range settings.SearchParams {
+		term := util.ShortenString(strings.Join(strings.Fields(strings.ToLower(query.Get(key))), " "), 200)
+
+		if term != "" {
+			return term
+		}
+	}
+
+	return ""
+}
+
+// SettingsProvider provides the ClientSettings for a client, like from a database.
+// The settings are cached by the Tracker for Config.SettingsCacheTTL.
+type SettingsProvider interface {
+	// Settings returns the settings for given client.
+	// Return nil to use the default settings.
+	Settings(clientID uint64) (*ClientSettings, error)
+}
+
+// SettingsProviderFunc is a function implementing the SettingsProvider interface.
+type SettingsProviderFunc func(uint64) (*ClientSettings, error)
+
+// Settings implements the SettingsProvider interface.
+func (f SettingsProviderFunc) Settings(clientID uint64) (*ClientSettings, error) {
+	return f(clientID)
+}
+
+type cachedSettings struct {
+	settings ClientSettings
+	expires  time.Time
+}
+
+// settingsCache caches the ClientSettings by client ID.
+// Expired entries are kept as a fallback in case the SettingsProvider returns an error,
+// until they are removed by the next cleanup, which runs at most once per cleanup interval.
+type settingsCache struct {
+	cache           map[uint64]cachedSettings
+	cleanupInterval time.Duration
+	nextCleanup     time.Time
+	m               sync.RWMutex
+}
+
+func newSettingsCache(cleanupInterval time.Duration) *settingsCache {
+	return &settingsCache{
+		cache:           make(map[uint64]cachedSettings),
+		cleanupInterval: cleanupInterval,
+		nextCleanup:     time.Now().Add(cleanupInterval),
+	}
+}
+
+func (cache *settingsCache) get(clientID uint64, now time.Time) (ClientSettings, bool, bool) {
+	cache.m.RLock()
+	defer cache.m.RUnlock()
+	entry, found := cache.cache[clientID]
+	return entry.settings, found, found && now.Before(entry.expires)
+}
+
+func (cache *settingsCache) put(clientID uint64, settings ClientSettings, expires time.Time) {
+	cache.m.Lock()
+	defer cache.m.Unlock()
+	now := time.Now()
+
+	if now.After(cache.nextCleanup) {
+		for id, entry := range cache.cache {
+			if !now.Before(entry.expires) {
+				delete(cache.cache, id)
+			}
+		}
+
+		cache.nextCleanup = now.Add(cache.cleanupInterval)
+	}
+
+	cache.cache[clientID] = cachedSettings{settings, expires}
+}
+
+func (cache *settingsCache) remove(clientID uint64) {
+	cache.m.Lock()
+	defer cache.m.Unlock()
+	delete(cache.cache, clientID)
+}
+
+// InvalidateSettings removes the cached ClientSettings for given client, so that they are looked up again on the next request.
+func (tracker *Tracker) InvalidateSettings(clientID uint64) {
+	tracker.settings.remove(clientID)
+}
+
+// clientSettings returns the settings for given client with the defaults applied.
+// In case the SettingsProvider returns an error, the previously cached settings (if any) or the defaults are used.
+// These are cached for a short time only, so that the settings are requested again soon.
+func (tracker *Tracker) clientSettings(clientID uint64) ClientSettings {
+	var settings ClientSettings
+
+	if tracker.config.Settings != nil {
+		now := time.Now()
+		cached, found, valid := tracker.settings.get(clientID, now)
+
+		if valid {
+			return cached
+		}
+
+		s, err := tracker.config.Settings.Settings(clientID)
+
+		if err != nil {
+			tracker.config.Logger.Error("error reading client settings", "err", err, "client_id", clientID)
+
+			if !found {
+				cached = tracker.applySettingsDefaults(settings)
+			}
+
+			tracker.settings.put(clientID, cached, now.Add(min(settingsErrorCacheTTL, tracker.config.SettingsCacheTTL)))
+			return cached
+		}
+
+		if s != nil {
+			settings = *s
+		}
+
+		settings = tracker.applySettingsDefaults(settings)
+		tracker.settings.put(clientID, settings, now.Add(tracker.config.SettingsCacheTTL))
+		return settings
+	}
+
+	return tracker.applySettingsDefaults(settings)
+}
+
+func (tracker *Tracker) applySettingsDefaults(settings ClientSettings) ClientSettings {
+	if settings.SessionTimeout <= 0 {
+		settings.SessionTimeout = sessionMaxAge
+	}
+
+	if settings.MaxPageViews == 0 {
+		settings.MaxPageViews = tracker.config.MaxPageViews
+	}
+
+	if settings.PathRules == nil {
+		settings.PathRules = &tracker.config.PathRules
+	}
+
+	if settings.QueryParams == nil {
+		settings.QueryParams = tracker.config.QueryParams
+	}
+
+	if settings.SearchParams == nil {
+		settings.SearchParams = tracker.config.SearchParams
+	}
+
+	if settings.ChannelRules == nil {
+		settings.ChannelRules = tracker.config.ChannelRules
+	}
+
+	return settings
+}
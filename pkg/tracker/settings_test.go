@@ -0,0 +1,470 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/referrer"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/session"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTracker_clientSettings(t *testing.T) {
+	var calls atomic.Int32
+	fail := false
+	tracker := NewTracker(Config{
+		MaxPageViews: 10,
+		Settings: SettingsProviderFunc(func(clientID uint64) (*ClientSettings, error) {
+			calls.Add(1)
+
+			if fail {
+				return nil, errors.New("error")
+			}
+
+			if clientID == 1 {
+				return &ClientSettings{SessionTimeout: time.Hour, MaxPageViews: 3}, nil
+			}
+
+			return nil, nil
+		}),
+	})
+	settings := tracker.clientSettings(1)
+	assert.Equal(t, time.Hour, settings.SessionTimeout)
+	assert.Equal(t, uint16(3), settings.MaxPageViews)
+	settings = tracker.clientSettings(1)
+	assert.Equal(t, time.Hour, settings.SessionTimeout)
+	assert.Equal(t, int32(1), calls.Load())
+	settings = tracker.clientSettings(2)
+	assert.Equal(t, sessionMaxAge, settings.SessionTimeout)
+	assert.Equal(t, uint16(10), settings.MaxPageViews)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// the cached settings are used in case the provider returns an error
+	fail = true
+	tracker.settings.put(1, settings, time.Now().Add(-time.Second))
+	settings = tracker.clientSettings(1)
+	assert.Equal(t, sessionMaxAge, settings.SessionTimeout)
+	assert.Equal(t, int32(3), calls.Load())
+	settings = tracker.clientSettings(1)
+	assert.Equal(t, sessionMaxAge, settings.SessionTimeout)
+	assert.Equal(t, int32(3), calls.Load())
+	fail = false
+	tracker.InvalidateSettings(1)
+	settings = tracker.clientSettings(1)
+	assert.Equal(t, time.Hour, settings.SessionTimeout)
+	assert.Equal(t, int32(4), calls.Load())
+
+	// the defaults are cached for a short time in case the provider returns an error
+	fail = true
+	settings = tracker.clientSettings(3)
+	assert.Equal(t, sessionMaxAge, settings.SessionTimeout)
+	assert.Equal(t, int32(5), calls.Load())
+	_, found, valid := tracker.settings.get(3, time.Now())
+	assert.True(t, found)
+	assert.True(t, valid)
+	_, _, valid = tracker.settings.get(3, time.Now().Add(settingsErrorCacheTTL))
+	assert.False(t, valid)
+	settings = tracker.clientSettings(3)
+	assert.Equal(t, sessionMaxAge, settings.SessionTimeout)
+	assert.Equal(t, int32(5), calls.Load())
+	tracker.Stop()
+	tracker = NewTracker(Config{})
+	settings = tracker.clientSettings(1)
+	assert.Equal(t, sessionMaxAge, settings.SessionTimeout)
+	assert.Equal(t, defaultMaxPageViews, settings.MaxPageViews)
+}
+
+func TestSettingsCache(t *testing.T) {
+	cache := newSettingsCache(time.Millisecond)
+	cache.put(1, ClientSettings{MaxPageViews: 1}, time.Now().Add(-time.Second))
+	cache.put(2, ClientSettings{MaxPageViews: 2}, time.Now().Add(time.Minute))
+	settings, found, valid := cache.get(1, time.Now())
+	assert.Equal(t, uint16(1), settings.MaxPageViews)
+	assert.True(t, found)
+	assert.False(t, valid)
+	time.Sleep(time.Millisecond * 2)
+	cache.put(3, ClientSettings{MaxPageViews: 3}, time.Now().Add(time.Minute))
+	_, found, _ = cache.get(1, time.Now())
+	assert.False(t, found)
+	settings, found, valid = cache.get(2, time.Now())
+	assert.Equal(t, uint16(2), settings.MaxPageViews)
+	assert.True(t, found)
+	assert.True(t, valid)
+	assert.Len(t, cache.cache, 2)
+}
+
+func TestTracker_PageViewClientSettings(t *testing.T) {
+	client := db.NewClientMock()
+	tracker := NewTracker(Config{
+		Store:        client,
+		SessionCache: session.NewMemCache(client, 10),
+		Settings: SettingsProviderFunc(func(clientID uint64) (*ClientSettings, error) {
+			if clientID == 1 {
+				return &ClientSettings{
+					MaxPageViews:        3,
+					DisableSessionSplit: true,
+					IgnorePaths:         []string{"/admin/*", "/login"},
+				}, nil
+			}
+
+			return nil, nil
+		}),
+	})
+
+	for _, clientID := range []uint64{1, 2} {
+		for i, path := range []string{"/", "/login", "/admin/settings", "/foo?utm_source=Source", "/bar", "/baz"} {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("User-Agent", userAgent)
+
+			if i == 4 {
+				req.Header.Set("Referer", "https://google.com")
+			}
+
+			tracker.PageView(req, clientID, Options{})
+			time.Sleep(time.Millisecond * 5)
+		}
+	}
+
+	tracker.Stop()
+	pageViews := client.GetPageViews()
+	sessions := make(map[uint64]map[uint32]struct{})
+	paths := make(map[uint64][]string)
+
+	for _, pv := range pageViews {
+		if sessions[pv.ClientID] == nil {
+			sessions[pv.ClientID] = make(map[uint32]struct{})
+		}
+
+		sessions[pv.ClientID][pv.SessionID] = struct{}{}
+		paths[pv.ClientID] = append(paths[pv.ClientID], pv.Path)
+	}
+
+	assert.ElementsMatch(t, []string{"/", "/foo", "/bar"}, paths[1])
+	assert.Len(t, sessions[1], 1)
+	assert.Len(t, paths[2], 6)
+	assert.Len(t, sessions[2], 3)
+	assert.Equal(t, uint64(2), tracker.Stats().Ignored[ReasonIgnoredPath])
+}
+
+func TestTracker_PageViewSessionTimeout(t *testing.T) {
+	client := db.NewClientMock()
+	tracker := NewTracker(Config{
+		Store:        client,
+		SessionCache: session.NewMemCache(client, 10),
+		Settings: SettingsProviderFunc(func(clientID uint64) (*ClientSettings, error) {
+			return &ClientSettings{SessionTimeout: time.Hour}, nil
+		}),
+	})
+	now := time.Now().UTC().Truncate(time.Hour * 24).Add(time.Hour * 12)
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/%d", i), nil)
+		req.Header.Set("User-Agent", userAgent)
+		tracker.PageView(req, 0, Options{Time: now.Add(time.Minute * 45 * time.Duration(i))})
+	}
+
+	tracker.Stop()
+	pageViews := client.GetPageViews()
+	assert.Len(t, pageViews, 3)
+	assert.Equal(t, pageViews[0].SessionID, pageViews[1].SessionID)
+	assert.Equal(t, pageViews[0].SessionID, pageViews[2].SessionID)
+}
+
+func TestBrowserVersionRule_clientSettings(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/61.0.4147.135 Safari/537.36")
+	tracker := NewTracker(Config{})
+	_, reason := tracker.ignore(req, 0, &ClientSettings{}, nil)
+	assert.Equal(t, ReasonBrowserVersion, reason)
+	_, reason = tracker.ignore(req, 0, &ClientSettings{MinBrowserVersions: &BrowserVersionRule{Chrome: 60}}, nil)
+	assert.Empty(t, reason)
+}
+
+func TestClientSettings_ignorePath(t *testing.T) {
+	settings := ClientSettings{IgnorePaths: []string{"/admin/*", "/login"}}
+	assert.True(t, settings.ignorePath("/admin/"))
+	assert.True(t, settings.ignorePath("/admin/users"))
+	assert.True(t, settings.ignorePath("/login"))
+	assert.False(t, settings.ignorePath("/admin"))
+	assert.False(t, settings.ignorePath("/login/reset"))
+	assert.False(t, settings.ignorePath("/"))
+}
+
+func TestTracker_PageViewAllowedHostnames(t *testing.T) {
+	client := db.NewClientMock()
+	tracker := NewTracker(Config{
+		Store: client,
+		Settings: SettingsProviderFunc(func(clientID uint64) (*ClientSettings, error) {
+			return &ClientSettings{AllowedHostnames: []string{"example.com", "*.example.com"}}, nil
+		}),
+	})
+
+	for _, u := range []string{"https://example.com/", "https://www.example.com/foo", "https://spoofed.com/bar", "https://example.com.spoofed.com/baz", "http://localhost:8080/qux"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("User-Agent", userAgent)
+		tracker.PageView(req, 0, Options{URL: u})
+	}
+
+	tracker.Stop()
+	pageViews := client.GetPageViews()
+	assert.Len(t, pageViews, 2)
+	assert.ElementsMatch(t, []string{"example.com", "www.example.com"}, []string{pageViews[0].Hostname, pageViews[1].Hostname})
+	assert.Equal(t, "example.com", client.GetSessions()[0].Hostname)
+	assert.Empty(t, client.GetBots())
+	assert.Equal(t, uint64(3), tracker.Stats().Ignored[ReasonHostname])
+}
+
+func TestClientSettings_allowHostname(t *testing.T) {
+	settings := ClientSettings{}
+	assert.True(t, settings.allowHostname("example.com"))
+	assert.True(t, settings.allowHostname(""))
+	settings.AllowedHostnames = []string{"example.com", "*.Example.org"}
+	assert.True(t, settings.allowHostname("example.com"))
+	assert.True(t, settings.allowHostname("EXAMPLE.com."))
+	assert.False(t, settings.allowHostname("www.example.com"))
+	assert.True(t, settings.allowHostname("www.example.org"))
+	assert.True(t, settings.allowHostname("a.b.example.org"))
+	assert.False(t, settings.allowHostname("example.org"))
+	assert.False(t, settings.allowHostname("fakeexample.org"))
+	assert.False(t, settings.allowHostname(""))
+	assert.False(t, settings.allowHostname("localhost"))
+	assert.False(t, settings.allowHostname("127.0.0.1"))
+	settings.AllowLocalhost = true
+	assert.True(t, settings.allowHostname("localhost"))
+	assert.True(t, settings.allowHostname("app.localhost"))
+	assert.True(t, settings.allowHostname("127.0.0.1"))
+	assert.True(t, settings.allowHostname("[::1]"))
+	assert.False(t, settings.allowHostname("spoofed.com"))
+}
+
+func TestClientSettings_drop(t *testing.T) {
+	settings := ClientSettings{AllowedHostnames: []string{"example.com"}, IgnorePaths: []string{"/admin"}}
+	assert.Empty(t, settings.drop(&Options{Hostname: "example.com", Path: "/"}))
+	assert.Equal(t, ReasonHostname, settings.drop(&Options{Hostname: "spoofed.com", Path: "/"}))
+	assert.Equal(t, ReasonIgnoredPath, settings.drop(&Options{Hostname: "example.com", Path: "/admin"}))
+}
+
+func TestTracker_PageViewQueryParams(t *testing.T) {
+	client := db.NewClientMock()
+	tracker := NewTracker(Config{
+		Store:       client,
+		QueryParams: []string{"q"},
+		Settings: SettingsProviderFunc(func(clientID uint64) (*ClientSettings, error) {
+			if clientID == 1 {
+				return &ClientSettings{QueryParams: []string{"variant", "q"}}, nil
+			}
+
+			return nil, nil
+		}),
+	})
+
+	for _, clientID := range []uint64{1, 2} {
+		req := httptest.NewRequest(http.MethodGet, "/search?q=shoes&variant=b&session=secret", nil)
+		req.Header.Set("User-Agent", userAgent)
+		tracker.PageView(req, clientID, Options{})
+		tracker.Event(req, clientID, EventOptions{Name: "event"}, Options{})
+	}
+
+	tracker.Stop()
+	pageViews := client.GetPageViews()
+	events := client.GetEvents()
+	assert.Len(t, pageViews, 2)
+	assert.Len(t, events, 2)
+
+	for _, pv := range pageViews {
+		if pv.ClientID == 1 {
+			assert.Equal(t, []string{"variant", "q"}, pv.QueryParamKeys)
+			assert.Equal(t, []string{"b", "shoes"}, pv.QueryParamValues)
+		} else {
+			assert.Equal(t, []string{"q"}, pv.QueryParamKeys)
+			assert.Equal(t, []string{"shoes"}, pv.QueryParamValues)
+		}
+	}
+
+	for _, e := range events {
+		if e.ClientID == 1 {
+			assert.Equal(t, []string{"variant", "q"}, e.QueryParamKeys)
+		} else {
+			assert.Equal(t, []string{"q"}, e.QueryParamKeys)
+		}
+	}
+}
+
+func TestClientSettings_queryParams(t *testing.T) {
+	query := url.Values{"q": {" shoes ", "boots"}, "empty": {""}, "other": {"value"}}
+	settings := ClientSettings{}
+	keys, values := settings.queryParams(query)
+	assert.Empty(t, keys)
+	assert.Empty(t, values)
+	settings.QueryParams = []string{"q", "empty", "missing"}
+	keys, values = settings.queryParams(query)
+	assert.Equal(t, []string{"q"}, keys)
+	assert.Equal(t, []string{"shoes"}, values)
+}
+
+func TestTracker_PageViewSearchTerm(t *testing.T) {
+	client := db.NewClientMock()
+	tracker := NewTracker(Config{
+		Store:        client,
+		SearchParams: []string{"q"},
+		Settings: SettingsProviderFunc(func(clientID uint64) (*ClientSettings, error) {
+			if clientID == 1 {
+				return &ClientSettings{SearchParams: []string{"s", "query"}}, nil
+			}
+
+			return nil, nil
+		}),
+	})
+
+	for _, clientID := range []uint64{1, 2} {
+		req := httptest.NewRequest(http.MethodGet, "/search?q=Red+Shoes&query=%20Blue%20%20Shoes", nil)
+		req.Header.Set("User-Agent", userAgent)
+		tracker.PageView(req, clientID, Options{})
+	}
+
+	tracker.Stop()
+	pageViews := client.GetPageViews()
+	assert.Len(t, pageViews, 2)
+
+	for _, pv := range pageViews {
+		if pv.ClientID == 1 {
+			assert.Equal(t, "blue shoes", pv.SearchTerm)
+		} else {
+			assert.Equal(t, "red shoes", pv.SearchTerm)
+		}
+	}
+}
+
+func TestClientSettings_searchTerm(t *testing.T) {
+	query := url.Values{"q": {"  "}, "s": {"Hello\tWorld "}}
+	settings := ClientSettings{}
+	assert.Empty(t, settings.searchTerm(query))
+	settings.SearchParams = []string{"q", "s"}
+	assert.Equal(t, "hello world", settings.searchTerm(query))
+	settings.SearchParams = []string{"query"}
+	assert.Empty(t, settings.searchTerm(query))
+}
+
+func TestTracker_PageViewChannel(t *testing.T) {
+	client := db.NewClientMock()
+	tracker := NewTracker(Config{
+		Store: client,
+		Settings: SettingsProviderFunc(func(clientID uint64) (*ClientSettings, error) {
+			if clientID == 1 {
+				return &ClientSettings{ChannelRules: []referrer.ChannelRule{
+					func(data referrer.ChannelData) string {
+						if data.UTMSource == "partner" {
+							return "Affiliate"
+						}
+
+						return ""
+					},
+				}}, nil
+			}
+
+			return nil, nil
+		}),
+	})
+
+	for _, clientID := range []uint64{1, 2} {
+		req := httptest.NewRequest(http.MethodGet, "/?utm_source=partner", nil)
+		req.Header.Set("User-Agent", userAgent)
+		tracker.PageView(req, clientID, Options{})
+		req = httptest.NewRequest(http.MethodGet, "/foo", nil)
+		req.Header.Set("User-Agent", userAgent)
+		tracker.Event(req, clientID, EventOptions{Name: "event"}, Options{})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/?gclid=abc", nil)
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Referer", "https://www.google.com/")
+	tracker.PageView(req, 3, Options{})
+	tracker.Stop()
+	channels := make(map[uint64]string)
+
+	for _, pv := range client.GetPageViews() {
+		channels[pv.ClientID] = pv.Channel
+	}
+
+	assert.Equal(t, "Affiliate", channels[1])
+	assert.Equal(t, referrer.ChannelReferral, channels[2])
+	assert.Equal(t, referrer.ChannelPaidSearch, channels[3])
+
+	for _, e := range client.GetEvents() {
+		assert.Equal(t, channels[e.ClientID], e.Channel)
+	}
+
+	for _, s := range client.GetSessions() {
+		assert.Equal(t, channels[s.ClientID], s.Channel)
+	}
+}
+
+func TestTracker_PageViewAdNetwork(t *testing.T) {
+	client := db.NewClientMock()
+	tracker := NewTracker(Config{
+		Store: client,
+	})
+	req := httptest.NewRequest(http.MethodGet, "/?gclid=abc", nil)
+	req.Header.Set("User-Agent", userAgent)
+	tracker.PageView(req, 0, Options{})
+	req = httptest.NewRequest(http.MethodGet, "/foo?gclid=abc", nil)
+	req.Header.Set("User-Agent", userAgent)
+	tracker.PageView(req, 0, Options{})
+	req = httptest.NewRequest(http.MethodGet, "/?fbclid=abc&utm_source=newsletter", nil)
+	req.Header.Set("User-Agent", userAgent)
+	tracker.PageView(req, 0, Options{})
+	tracker.Stop()
+	pageViews := client.GetPageViews()
+	assert.Len(t, pageViews, 3)
+	assert.Equal(t, "Google Ads", pageViews[0].AdNetwork)
+	assert.Equal(t, "google", pageViews[0].UTMSource)
+	assert.Equal(t, "cpc", pageViews[0].UTMMedium)
+	assert.Equal(t, referrer.ChannelPaidSearch, pageViews[0].Channel)
+	assert.Equal(t, pageViews[0].SessionID, pageViews[1].SessionID)
+	assert.NotEqual(t, pageViews[0].SessionID, pageViews[2].SessionID)
+	assert.Equal(t, "Meta Ads", pageViews[2].AdNetwork)
+	assert.Equal(t, "newsletter", pageViews[2].UTMSource)
+	assert.Equal(t, "paid_social", pageViews[2].UTMMedium)
+	assert.Equal(t, referrer.ChannelSocial, pageViews[2].Channel)
+
+	for _, s := range client.GetSessions() {
+		assert.NotContains(t, s.String(), "abc")
+	}
+}
+
+func TestTracker_PageViewAdNetworkSessionFromStore(t *testing.T) {
+	client := db.NewClientMock()
+	tracker := NewTracker(Config{
+		Store:        client,
+		SessionCache: session.NewMemCache(client, 10),
+	})
+	req := httptest.NewRequest(http.MethodGet, "/?gclid=abc", nil)
+	req.Header.Set("User-Agent", userAgent)
+	tracker.PageView(req, 0, Options{})
+	tracker.Stop()
+	sessions := client.GetSessions()
+	assert.Len(t, sessions, 1)
+
+	// the click ID hash is not stored, so it's missing for sessions loaded from the database
+	stored := sessions[0]
+	stored.ClickIDHash = 0
+	client.ReturnSession = &stored
+	tracker = NewTracker(Config{
+		Store:        client,
+		SessionCache: session.NewMemCache(client, 10),
+	})
+	req = httptest.NewRequest(http.MethodGet, "/foo?gclid=abc", nil)
+	req.Header.Set("User-Agent", userAgent)
+	tracker.PageView(req, 0, Options{})
+	tracker.Stop()
+	pageViews := client.GetPageViews()
+	assert.Len(t, pageViews, 2)
+	assert.Equal(t, pageViews[0].SessionID, pageViews[1].SessionID)
+	assert.Equal(t, "Google Ads", pageViews[1].AdNetwork)
+}
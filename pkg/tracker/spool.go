@@ -0,0 +1,173 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/util"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	spoolFileExt        = ".json"
+	spoolTmpFileExt     = ".tmp"
+	spoolCorruptFileExt = ".corrupt"
+)
+
+// spool stores batches that could not be saved in a local directory, so that they can be replayed later on.
+type spool struct {
+	dir     string
+	logger  *slog.Logger
+	pending atomic.Int64
+	m       sync.Mutex
+}
+
+func newSpool(dir string, logger *slog.Logger) (*spool, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	s := &spool{dir: dir, logger: logger}
+	files, err := s.files()
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.pending.Store(int64(len(files)))
+	return s, nil
+}
+
+// write stores the batch in a new file.
+// The file is written to a temporary location and synced first, and renamed afterward, so that a crash never leaves a partial batch behind.
+func (s *spool) write(batch *Batch) error {
+	data, err := json.Marshal(batch)
+
+	if err != nil {
+		return err
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	name := fmt.Sprintf("%020d_%d", time.Now().UnixNano(), util.RandUint32())
+	tmp := filepath.Join(s.dir, name+spoolTmpFileExt)
+
+	if err := writeFileSync(tmp, data); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, filepath.Join(s.dir, name+spoolFileExt)); err != nil {
+		return err
+	}
+
+	s.pending.Add(1)
+	return nil
+}
+
+// replay reads the spooled batches in the order they have been written and passes them to save.
+// save must return the part of the batch that could not be saved. In that case, the remainder is written back and the replay stops.
+// Files that cannot be read are renamed to .corrupt and skipped, so that they don't block the following batches.
+func (s *spool) replay(save func(*Batch) (*Batch, error)) error {
+	if s.pending.Load() == 0 {
+		return nil
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	files, err := s.files()
+
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		path := filepath.Join(s.dir, file)
+		content, err := os.ReadFile(path)
+
+		if err != nil {
+			return err
+		}
+
+		var batch Batch
+
+		if err := json.Unmarshal(content, &batch); err != nil {
+			s.logger.Error("error reading spooled batch, skipping it", "err", err, "file", file)
+
+			if err := os.Rename(path, strings.TrimSuffix(path, spoolFileExt)+spoolCorruptFileExt); err != nil {
+				return err
+			}
+
+			s.pending.Add(-1)
+			continue
+		}
+
+		failed, err := save(&batch)
+
+		if err != nil {
+			content, e := json.Marshal(failed)
+
+			if e != nil {
+				return e
+			}
+
+			if e := writeFileSync(path, content); e != nil {
+				return e
+			}
+
+			return err
+		}
+
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+
+		s.pending.Add(-1)
+	}
+
+	return nil
+}
+
+func (s *spool) files() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), spoolFileExt) {
+			files = append(files, entry.Name())
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+func writeFileSync(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,178 @@
+package tracker
+
+import (
+	"expvar"
+	"fmt"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/session"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const statsPrefix = "pirsch_tracker_"
+
+// Stats is a snapshot of the Tracker runtime statistics.
+// All counters are totals since the Tracker has been created.
+type Stats struct {
+	PageViews           uint64            `json:"page_views"`
+	Events              uint64            `json:"events"`
+	SessionExtensions   uint64            `json:"session_extensions"`
+	Ignored             map[string]uint64 `json:"ignored"`
+	Bots                uint64            `json:"bots"`
+	Dropped             uint64            `json:"dropped"`
+	SessionsCreated     uint64            `json:"sessions_created"`
+	SessionsContinued   uint64            `json:"sessions_continued"`
+	CacheHits           uint64            `json:"cache_hits"`
+	CacheMisses         uint64            `json:"cache_misses"`
+	CacheStoreFallbacks uint64            `json:"cache_store_fallbacks"`
+	BatchesFlushed      uint64            `json:"batches_flushed"`
+	BatchesFailed       uint64            `json:"batches_failed"`
+	FlushDuration       time.Duration     `json:"flush_duration"`
+	LastFlushDuration   time.Duration     `json:"last_flush_duration"`
+	QueueDepth          int               `json:"queue_depth"`
+	QueueCapacity       int               `json:"queue_capacity"`
+}
+
+type stats struct {
+	pageViews         atomic.Uint64
+	events            atomic.Uint64
+	sessionExtensions atomic.Uint64
+	ignored           sync.Map // reason -> *atomic.Uint64
+	bots              atomic.Uint64
+	sessionsCreated   atomic.Uint64
+	sessionsContinued atomic.Uint64
+	batchesFlushed    atomic.Uint64
+	batchesFailed     atomic.Uint64
+	flushDuration     atomic.Int64
+	lastFlushDuration atomic.Int64
+}
+
+func (stats *stats) ignore(reason string) {
+	counter, ok := stats.ignored.Load(reason)
+
+	if !ok {
+		counter, _ = stats.ignored.LoadOrStore(reason, new(atomic.Uint64))
+	}
+
+	counter.(*atomic.Uint64).Add(1)
+}
+
+func (stats *stats) flushed(d time.Duration, failed bool) {
+	stats.batchesFlushed.Add(1)
+	stats.flushDuration.Add(int64(d))
+	stats.lastFlushDuration.Store(int64(d))
+
+	if failed {
+		stats.batchesFailed.Add(1)
+	}
+}
+
+// Stats returns a snapshot of the runtime statistics.
+// The session cache statistics are only available if the cache implements the session.StatsProvider interface.
+func (tracker *Tracker) Stats() Stats {
+	s := Stats{
+		PageViews:         tracker.stats.pageViews.Load(),
+		Events:            tracker.stats.events.Load(),
+		SessionExtensions: tracker.stats.sessionExtensions.Load(),
+		Ignored:           make(map[string]uint64),
+		Bots:              tracker.stats.bots.Load(),
+		Dropped:           tracker.dropped.Load(),
+		SessionsCreated:   tracker.stats.sessionsCreated.Load(),
+		SessionsContinued: tracker.stats.sessionsContinued.Load(),
+		BatchesFlushed:    tracker.stats.batchesFlushed.Load(),
+		BatchesFailed:     tracker.stats.batchesFailed.Load(),
+		FlushDuration:     time.Duration(tracker.stats.flushDuration.Load()),
+		LastFlushDuration: time.Duration(tracker.stats.lastFlushDuration.Load()),
+		QueueDepth:        len(tracker.data),
+		QueueCapacity:     cap(tracker.data),
+	}
+	tracker.stats.ignored.Range(func(key, value any) bool {
+		s.Ignored[key.(string)] = value.(*atomic.Uint64).Load()
+		return true
+	})
+
+	if cache, ok := tracker.config.SessionCache.(session.StatsProvider); ok {
+		cacheStats := cache.Stats()
+		s.CacheHits = cacheStats.Hits
+		s.CacheMisses = cacheStats.Misses
+		s.CacheStoreFallbacks = cacheStats.StoreFallbacks
+	}
+
+	return s
+}
+
+// StatsHandler returns an http.Handler serving the runtime statistics in the Prometheus text format.
+func (tracker *Tracker) StatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		tracker.Stats().WritePrometheus(w)
+	})
+}
+
+// PublishExpvar publishes the runtime statistics as an expvar variable with given name.
+// Like expvar.Publish, this panics if the name is already in use.
+func (tracker *Tracker) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return tracker.Stats()
+	}))
+}
+
+// WritePrometheus writes the statistics in the Prometheus text format.
+func (s Stats) WritePrometheus(w io.Writer) {
+	writeMetric(w, "page_views_total", "counter", "Accepted page views.", s.PageViews)
+	writeMetric(w, "events_total", "counter", "Accepted events.", s.Events)
+	writeMetric(w, "session_extensions_total", "counter", "Accepted session extensions.", s.SessionExtensions)
+	writeMetricHeader(w, "ignored_total", "counter", "Ignored requests by reason.")
+	reasons := make([]string, 0, len(s.Ignored))
+
+	for reason := range s.Ignored {
+		reasons = append(reasons, reason)
+	}
+
+	sort.Strings(reasons)
+
+	for _, reason := range reasons {
+		fmt.Fprintf(w, "%signored_total{reason=\"%s\"} %d\n", statsPrefix, escapeLabelValue(reason), s.Ignored[reason])
+	}
+
+	writeMetric(w, "bots_total", "counter", "Bot page views and events.", s.Bots)
+	writeMetric(w, "dropped_total", "counter", "Data dropped due to back-pressure.", s.Dropped)
+	writeMetric(w, "sessions_created_total", "counter", "Sessions created.", s.SessionsCreated)
+	writeMetric(w, "sessions_continued_total", "counter", "Sessions continued.", s.SessionsContinued)
+	writeMetric(w, "session_cache_hits_total", "counter", "Sessions found in the cache.", s.CacheHits)
+	writeMetric(w, "session_cache_misses_total", "counter", "Sessions not found in the cache.", s.CacheMisses)
+	writeMetric(w, "session_cache_store_fallbacks_total", "counter", "Sessions looked up in the store on a cache miss.", s.CacheStoreFallbacks)
+	writeMetric(w, "batches_failed_total", "counter", "Batches that could not be saved to the store.", s.BatchesFailed)
+	writeMetricHeader(w, "flush_duration_seconds", "summary", "Time spent saving batches to the store.")
+	fmt.Fprintf(w, "%sflush_duration_seconds_sum %g\n", statsPrefix, s.FlushDuration.Seconds())
+	fmt.Fprintf(w, "%sflush_duration_seconds_count %d\n", statsPrefix, s.BatchesFlushed)
+	writeMetric(w, "last_flush_duration_seconds", "gauge", "Time spent saving the last batch to the store.", s.LastFlushDuration.Seconds())
+	writeMetric(w, "queue_depth", "gauge", "Data waiting to be processed by the workers.", s.QueueDepth)
+	writeMetric(w, "queue_capacity", "gauge", "Maximum number of data waiting to be processed by the workers.", s.QueueCapacity)
+}
+
+func writeMetric(w io.Writer, name, metricType, help string, value any) {
+	writeMetricHeader(w, name, metricType, help)
+
+	if f, ok := value.(float64); ok {
+		fmt.Fprintf(w, "%s%s %g\n", statsPrefix, name, f)
+	} else {
+		fmt.Fprintf(w, "%s%s %d\n", statsPrefix, name, value)
+	}
+}
+
+// labelValueReplacer escapes label values as specified by the Prometheus text format.
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
+
+func writeMetricHeader(w io.Writer, name, metricType, help string) {
+	fmt.Fprintf(w, "# HELP %s%s %s\n", statsPrefix, name, help)
+	fmt.Fprintf(w, "# TYPE %s%s %s\n", statsPrefix, name, metricType)
+}
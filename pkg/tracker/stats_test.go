@@ -0,0 +1,67 @@
+package tracker
+
+import (
+	"bytes"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/session"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTracker_Stats(t *testing.T) {
+	store := db.NewClientMock()
+	tracker := NewTracker(Config{
+		Store:        store,
+		SessionCache: session.NewMemCache(store, 100),
+	})
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("User-Agent", userAgent)
+		tracker.PageView(req, 0, Options{})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", userAgent)
+	tracker.Event(req, 0, EventOptions{Name: "event"}, Options{})
+	tracker.ExtendSession(req, 0, Options{})
+	req.Header.Set("DNT", "1")
+	tracker.PageView(req, 0, Options{})
+	req.Header.Set("DNT", "0")
+	req.Header.Set("User-Agent", "bot")
+	tracker.PageView(req, 0, Options{})
+	tracker.Flush()
+	stats := tracker.Stats()
+	assert.Equal(t, uint64(3), stats.PageViews)
+	assert.Equal(t, uint64(1), stats.Events)
+	assert.Equal(t, uint64(1), stats.SessionExtensions)
+	assert.Equal(t, map[string]uint64{ReasonDoNotTrack: 1, ReasonUserAgentLength: 1}, stats.Ignored)
+	assert.Equal(t, uint64(2), stats.Bots)
+	assert.Equal(t, uint64(1), stats.SessionsCreated)
+	assert.Equal(t, uint64(4), stats.SessionsContinued)
+	assert.Equal(t, uint64(4), stats.CacheHits)
+	assert.Equal(t, stats.CacheMisses, stats.CacheStoreFallbacks)
+	assert.NotZero(t, stats.CacheStoreFallbacks)
+	assert.NotZero(t, stats.BatchesFlushed)
+	assert.Zero(t, stats.BatchesFailed)
+	assert.Zero(t, stats.QueueDepth)
+	assert.Equal(t, defaultWorkerBufferSize, stats.QueueCapacity)
+	w := httptest.NewRecorder()
+	tracker.StatsHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "# TYPE pirsch_tracker_page_views_total counter\npirsch_tracker_page_views_total 3\n")
+	assert.Contains(t, body, "pirsch_tracker_ignored_total{reason=\"dnt\"} 1\npirsch_tracker_ignored_total{reason=\"user_agent_length\"} 1\n")
+	assert.Contains(t, body, "pirsch_tracker_queue_capacity 500\n")
+	assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain"))
+	tracker.Stop()
+}
+
+func TestStats_WritePrometheusEscape(t *testing.T) {
+	var buffer bytes.Buffer
+	Stats{Ignored: map[string]uint64{"cüstom \"rule\"\\\n": 1}}.WritePrometheus(&buffer)
+	assert.Contains(t, buffer.String(), "pirsch_tracker_ignored_total{reason=\"cüstom \\\"rule\\\"\\\\\\n\"} 1\n")
+}
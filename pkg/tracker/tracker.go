@@ -2,18 +2,17 @@ package tracker
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/dchest/siphash"
 	"github.com/emvi/iso-639-1"
-	"github.com/pirsch-analytics/pirsch/v6/pkg"
 	"github.com/pirsch-analytics/pirsch/v6/pkg/model"
-	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/ip"
 	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/referrer"
-	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/ua"
 	util2 "github.com/pirsch-analytics/pirsch/v6/pkg/util"
-	"log"
 	"math"
-	"net"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -21,13 +20,6 @@ import (
 )
 
 const (
-	minChromeVersion  = 70 // late 2019
-	minFirefoxVersion = 68 // mid 2019
-	minSafariVersion  = 12 // late 2018
-	minOperaVersion   = 65 // late 2019
-	minEdgeVersion    = 88 // late 2020
-	minIEVersion      = 11 // late 2013
-
 	sessionMaxAge = time.Minute * 30
 
 	pageView = eventType(iota)
@@ -61,85 +53,156 @@ type data struct {
 	event         *model.Event
 	ua            *model.UserAgent
 	bot           *model.Bot
+	walRef        walRef
 }
 
 // Tracker tracks page views, events, and updates sessions.
 type Tracker struct {
-	config  Config
-	data    chan data
-	cancel  context.CancelFunc
-	done    chan bool
-	stopped atomic.Bool
+	config    Config
+	data      chan data
+	cancel    context.CancelFunc
+	done      chan bool
+	stopped   atomic.Bool
+	spool     *spool
+	replaying atomic.Bool
+	wal       *wal
+	slots     chan struct{}
+	dropped   atomic.Uint64
+	stats     stats
+	settings  *settingsCache
+
+	// referrerParams are the query parameters used for the referrer, see CampaignParams.
+	referrerParams []string
 }
 
 // NewTracker creates a new tracker for given client, salt and config.
 func NewTracker(config Config) *Tracker {
 	config.validate()
 	tracker := &Tracker{
-		config: config,
-		data:   make(chan data, config.WorkerBufferSize),
-		done:   make(chan bool),
+		config:   config,
+		data:     make(chan data, config.WorkerBufferSize),
+		done:     make(chan bool),
+		slots:    make(chan struct{}, config.WorkerBufferSize),
+		settings: newSettingsCache(config.SettingsCacheTTL),
 	}
+	tracker.referrerParams = config.CampaignParams.referrerParams()
+
+	if config.SpoolDir != "" {
+		s, err := newSpool(config.SpoolDir, config.Logger)
+
+		if err != nil {
+			config.Logger.Error("error opening spool directory, failed batches won't be spooled", "err", err, "dir", config.SpoolDir)
+		} else {
+			tracker.spool = s
+		}
+	}
+
+	if config.WALDir != "" {
+		tracker.openWAL()
+	}
+
 	tracker.startWorker()
 	return tracker
 }
 
 // PageView tracks a page view.
 func (tracker *Tracker) PageView(r *http.Request, clientID uint64, options Options) {
-	if tracker.stopped.Load() {
+	if tracker.stopped.Load() || !tracker.acquire() {
 		return
 	}
 
+	tracker.enqueue(tracker.pageView(r, clientID, options))
+}
+
+// Event tracks an event.
+func (tracker *Tracker) Event(r *http.Request, clientID uint64, eventOptions EventOptions, options Options) {
+	if tracker.stopped.Load() || !tracker.acquire() {
+		return
+	}
+
+	tracker.enqueue(tracker.event(r, clientID, eventOptions, options))
+}
+
+// ExtendSession extends an existing session.
+func (tracker *Tracker) ExtendSession(r *http.Request, clientID uint64, options Options) {
+	if tracker.stopped.Load() || !tracker.acquire() {
+		return
+	}
+
+	tracker.enqueue(tracker.extendSession(r, clientID, options))
+}
+
+func (tracker *Tracker) pageView(r *http.Request, clientID uint64, options Options) *data {
 	now := time.Now().UTC()
-	userAgent, ipAddress, ignore := tracker.ignore(r)
+	settings := tracker.clientSettings(clientID)
 	options.validate(r)
+	options.Path = util2.ShortenString(NormalizePath(options.Path, *settings.PathRules), 2000)
+
+	if reason := settings.drop(&options); reason != "" {
+		tracker.stats.ignore(reason)
+		return nil
+	}
+
+	req, reason := tracker.ignore(r, clientID, &settings, options.query)
 
 	if !options.Time.IsZero() {
 		now = options.Time
 	}
 
-	if !ignore {
-		session, cancelSession, timeOnPage, bounced := tracker.getSession(pageView, clientID, r, now, userAgent, ipAddress, 1, options)
+	if reason == "" {
+		session, cancelSession, timeOnPage, bounced := tracker.getSession(pageView, clientID, req, now, 1, options, &settings)
 		var saveUserAgent *model.UserAgent
 
 		if session != nil {
 			if cancelSession == nil {
+				userAgent := req.UserAgent()
 				saveUserAgent = &userAgent
 			}
 
 			var pv *model.PageView
 
 			if !bounced {
+				queryKeys, queryValues := settings.queryParams(options.query)
 				pv = &model.PageView{
-					ClientID:        session.ClientID,
-					VisitorID:       session.VisitorID,
-					SessionID:       session.SessionID,
-					Time:            session.Time,
-					DurationSeconds: timeOnPage,
-					Path:            session.ExitPath,
-					Title:           session.ExitTitle,
-					Language:        session.Language,
-					CountryCode:     session.CountryCode,
-					City:            session.City,
-					Referrer:        session.Referrer,
-					ReferrerName:    session.ReferrerName,
-					ReferrerIcon:    session.ReferrerIcon,
-					OS:              session.OS,
-					OSVersion:       session.OSVersion,
-					Browser:         session.Browser,
-					BrowserVersion:  session.BrowserVersion,
-					Desktop:         session.Desktop,
-					Mobile:          session.Mobile,
-					ScreenClass:     session.ScreenClass,
-					UTMSource:       session.UTMSource,
-					UTMMedium:       session.UTMMedium,
-					UTMCampaign:     session.UTMCampaign,
-					UTMContent:      session.UTMContent,
-					UTMTerm:         session.UTMTerm,
+					ClientID:         session.ClientID,
+					VisitorID:        session.VisitorID,
+					SessionID:        session.SessionID,
+					Time:             session.Time,
+					DurationSeconds:  timeOnPage,
+					Hostname:         options.Hostname,
+					Path:             session.ExitPath,
+					Title:            session.ExitTitle,
+					Language:         session.Language,
+					CountryCode:      session.CountryCode,
+					Region:           session.Region,
+					City:             session.City,
+					Referrer:         session.Referrer,
+					ReferrerName:     session.ReferrerName,
+					ReferrerIcon:     session.ReferrerIcon,
+					Channel:          session.Channel,
+					AdNetwork:        session.AdNetwork,
+					ConnectionType:   session.ConnectionType,
+					OS:               session.OS,
+					OSVersion:        session.OSVersion,
+					Browser:          session.Browser,
+					BrowserVersion:   session.BrowserVersion,
+					Desktop:          session.Desktop,
+					Mobile:           session.Mobile,
+					ScreenClass:      session.ScreenClass,
+					UTMSource:        session.UTMSource,
+					UTMMedium:        session.UTMMedium,
+					UTMCampaign:      session.UTMCampaign,
+					UTMContent:       session.UTMContent,
+					UTMTerm:          session.UTMTerm,
+					QueryParamKeys:   queryKeys,
+					QueryParamValues: queryValues,
+					SearchTerm:       settings.searchTerm(options.query),
+					StatusCode:       options.StatusCode,
 				}
 			}
 
-			tracker.data <- data{
+			tracker.stats.pageViews.Add(1)
+			return &data{
 				session:       session,
 				cancelSession: cancelSession,
 				pageView:      pv,
@@ -147,121 +210,147 @@ func (tracker *Tracker) PageView(r *http.Request, clientID uint64, options Optio
 			}
 		}
 	} else {
-		tracker.data <- data{
+		tracker.stats.bots.Add(1)
+		return &data{
 			bot: &model.Bot{
 				ClientID:  clientID,
-				VisitorID: tracker.fingerprint(tracker.config.Salt, userAgent.UserAgent, ipAddress, now),
+				VisitorID: tracker.fingerprint(tracker.config.Salt, "", "", now),
 				Time:      now,
 				UserAgent: r.UserAgent(),
 				Path:      options.Path,
+				Reason:    reason,
 			},
 		}
 	}
-}
 
-// Event tracks an event.
-func (tracker *Tracker) Event(r *http.Request, clientID uint64, eventOptions EventOptions, options Options) {
-	if tracker.stopped.Load() {
-		return
-	}
+	return nil
+}
 
+func (tracker *Tracker) event(r *http.Request, clientID uint64, eventOptions EventOptions, options Options) *data {
 	now := time.Now().UTC()
 	eventOptions.validate()
 
 	if eventOptions.Name != "" {
-		userAgent, ipAddress, ignore := tracker.ignore(r)
+		settings := tracker.clientSettings(clientID)
 		options.validate(r)
+		options.Path = util2.ShortenString(NormalizePath(options.Path, *settings.PathRules), 2000)
+
+		if reason := settings.drop(&options); reason != "" {
+			tracker.stats.ignore(reason)
+			return nil
+		}
+
+		req, reason := tracker.ignore(r, clientID, &settings, options.query)
 
 		if !options.Time.IsZero() {
 			now = options.Time
 		}
 
-		if !ignore {
-			session, cancelSession, _, _ := tracker.getSession(event, clientID, r, now, userAgent, ipAddress, 0, options)
+		if reason == "" {
+			session, cancelSession, _, _ := tracker.getSession(event, clientID, req, now, 0, options, &settings)
 			var saveUserAgent *model.UserAgent
 
 			if session != nil {
 				if cancelSession == nil {
+					userAgent := req.UserAgent()
 					saveUserAgent = &userAgent
 				}
 
 				metaKeys, metaValues := eventOptions.getMetaData()
-				tracker.data <- data{
+				queryKeys, queryValues := settings.queryParams(options.query)
+				tracker.stats.events.Add(1)
+				return &data{
 					session:       session,
 					cancelSession: cancelSession,
 					event: &model.Event{
-						ClientID:        clientID,
-						VisitorID:       session.VisitorID,
-						Time:            session.Time,
-						SessionID:       session.SessionID,
-						DurationSeconds: eventOptions.Duration,
-						Name:            eventOptions.Name,
-						MetaKeys:        metaKeys,
-						MetaValues:      metaValues,
-						Path:            session.ExitPath,
-						Title:           session.ExitTitle,
-						Language:        session.Language,
-						CountryCode:     session.CountryCode,
-						City:            session.City,
-						Referrer:        session.Referrer,
-						ReferrerName:    session.ReferrerName,
-						ReferrerIcon:    session.ReferrerIcon,
-						OS:              session.OS,
-						OSVersion:       session.OSVersion,
-						Browser:         session.Browser,
-						BrowserVersion:  session.BrowserVersion,
-						Desktop:         session.Desktop,
-						Mobile:          session.Mobile,
-						ScreenClass:     session.ScreenClass,
-						UTMSource:       session.UTMSource,
-						UTMMedium:       session.UTMMedium,
-						UTMCampaign:     session.UTMCampaign,
-						UTMContent:      session.UTMContent,
-						UTMTerm:         session.UTMTerm,
+						ClientID:         clientID,
+						VisitorID:        session.VisitorID,
+						Time:             session.Time,
+						SessionID:        session.SessionID,
+						DurationSeconds:  eventOptions.Duration,
+						Name:             eventOptions.Name,
+						MetaKeys:         metaKeys,
+						MetaValues:       metaValues,
+						Hostname:         options.Hostname,
+						Path:             session.ExitPath,
+						Title:            session.ExitTitle,
+						Language:         session.Language,
+						CountryCode:      session.CountryCode,
+						Region:           session.Region,
+						City:             session.City,
+						Referrer:         session.Referrer,
+						ReferrerName:     session.ReferrerName,
+						ReferrerIcon:     session.ReferrerIcon,
+						Channel:          session.Channel,
+						AdNetwork:        session.AdNetwork,
+						ConnectionType:   session.ConnectionType,
+						OS:               session.OS,
+						OSVersion:        session.OSVersion,
+						Browser:          session.Browser,
+						BrowserVersion:   session.BrowserVersion,
+						Desktop:          session.Desktop,
+						Mobile:           session.Mobile,
+						ScreenClass:      session.ScreenClass,
+						UTMSource:        session.UTMSource,
+						UTMMedium:        session.UTMMedium,
+						UTMCampaign:      session.UTMCampaign,
+						UTMContent:       session.UTMContent,
+						UTMTerm:          session.UTMTerm,
+						QueryParamKeys:   queryKeys,
+						QueryParamValues: queryValues,
 					},
 					ua: saveUserAgent,
 				}
 			}
 		} else {
-			tracker.data <- data{
+			tracker.stats.bots.Add(1)
+			return &data{
 				bot: &model.Bot{
 					ClientID:  clientID,
-					VisitorID: tracker.fingerprint(tracker.config.Salt, userAgent.UserAgent, ipAddress, now),
+					VisitorID: tracker.fingerprint(tracker.config.Salt, "", "", now),
 					Time:      now,
 					UserAgent: r.UserAgent(),
 					Path:      options.Path,
 					Event:     eventOptions.Name,
+					Reason:    reason,
 				},
 			}
 		}
 	}
-}
 
-// ExtendSession extends an existing session.
-func (tracker *Tracker) ExtendSession(r *http.Request, clientID uint64, options Options) {
-	if tracker.stopped.Load() {
-		return
-	}
+	return nil
+}
 
+func (tracker *Tracker) extendSession(r *http.Request, clientID uint64, options Options) *data {
 	now := time.Now().UTC()
-	userAgent, ipAddress, ignore := tracker.ignore(r)
+	settings := tracker.clientSettings(clientID)
+	options.validate(r)
+	options.Path = util2.ShortenString(NormalizePath(options.Path, *settings.PathRules), 2000)
 
-	if !ignore {
-		options.validate(r)
+	if reason := settings.drop(&options); reason != "" {
+		tracker.stats.ignore(reason)
+		return nil
+	}
+
+	req, reason := tracker.ignore(r, clientID, &settings, options.query)
 
+	if reason == "" {
 		if !options.Time.IsZero() {
 			now = options.Time
 		}
 
-		session, cancelSession, _, _ := tracker.getSession(sessionUpdate, clientID, r, now, userAgent, ipAddress, 0, options)
+		session, cancelSession, _, _ := tracker.getSession(sessionUpdate, clientID, req, now, 0, options, &settings)
 
 		if session != nil {
-			tracker.data <- data{
+			tracker.stats.sessionExtensions.Add(1)
+			return &data{
 				session:       session,
 				cancelSession: cancelSession,
 			}
 		}
 	}
+
+	return nil
 }
 
 // Flush flushes all buffered data.
@@ -277,108 +366,60 @@ func (tracker *Tracker) Stop() {
 		tracker.stopped.Store(true)
 		tracker.stopWorker()
 		tracker.flushData()
-	}
-}
 
-func (tracker *Tracker) ignore(r *http.Request) (model.UserAgent, string, bool) {
-	// respect do not track header
-	if r.Header.Get("DNT") == "1" {
-		return model.UserAgent{}, "", true
-	}
-
-	// empty User-Agents are usually bots
-	rawUserAgent := r.UserAgent()
-	userAgent := strings.TrimSpace(strings.ToLower(rawUserAgent))
-
-	if userAgent == "" || len(userAgent) < 10 || len(userAgent) > 300 || util2.ContainsNonASCIICharacters(userAgent) {
-		return model.UserAgent{}, "", true
-	}
-
-	// ignore User-Agents that are an IP address
-	host := rawUserAgent
-
-	if net.ParseIP(host) != nil {
-		return model.UserAgent{}, "", true
-	}
-
-	if strings.Contains(host, ":") {
-		host, _, _ = net.SplitHostPort(rawUserAgent)
-	}
-
-	if net.ParseIP(host) != nil {
-		return model.UserAgent{}, "", true
-	}
-
-	// ignore browsers pre-fetching data
-	xPurpose := r.Header.Get("X-Purpose")
-	purpose := r.Header.Get("Purpose")
-
-	if r.Header.Get("X-Moz") == "prefetch" ||
-		xPurpose == "prefetch" ||
-		xPurpose == "preview" ||
-		purpose == "prefetch" ||
-		purpose == "preview" {
-		return model.UserAgent{}, "", true
-	}
-
-	// filter referrer spammers
-	if referrer.Ignore(r) {
-		return model.UserAgent{}, "", true
-	}
-
-	userAgentResult := ua.Parse(r)
-
-	if tracker.ignoreBrowserVersion(userAgentResult.Browser, userAgentResult.BrowserVersion) {
-		return model.UserAgent{}, "", true
-	}
-
-	// filter for bot keywords
-	for _, botUserAgent := range ua.Blacklist {
-		if strings.Contains(userAgent, botUserAgent) {
-			return model.UserAgent{}, "", true
+		if tracker.wal != nil {
+			if err := tracker.wal.close(); err != nil {
+				tracker.config.Logger.Error("error closing WAL", "err", err)
+			}
 		}
 	}
-
-	ipAddress := ip.Get(r, tracker.config.HeaderParser, tracker.config.AllowedProxySubnets)
-
-	if tracker.config.IPFilter != nil && tracker.config.IPFilter.Ignore(ipAddress) {
-		return model.UserAgent{}, "", true
-	}
-
-	return userAgentResult, ipAddress, false
 }
 
-func (tracker *Tracker) ignoreBrowserVersion(browser, version string) bool {
-	return version != "" &&
-		browser == pkg.BrowserChrome && tracker.browserVersionBefore(version, minChromeVersion) ||
-		browser == pkg.BrowserFirefox && tracker.browserVersionBefore(version, minFirefoxVersion) ||
-		browser == pkg.BrowserSafari && tracker.browserVersionBefore(version, minSafariVersion) ||
-		browser == pkg.BrowserOpera && tracker.browserVersionBefore(version, minOperaVersion) ||
-		browser == pkg.BrowserEdge && tracker.browserVersionBefore(version, minEdgeVersion) ||
-		browser == pkg.BrowserIE && tracker.browserVersionBefore(version, minIEVersion)
+// Dropped returns the number of page views, events, and session extensions that have been dropped due to the BackPressure policy.
+func (tracker *Tracker) Dropped() uint64 {
+	return tracker.dropped.Load()
 }
 
-func (tracker *Tracker) browserVersionBefore(version string, min int) bool {
-	i := strings.Index(version, ".")
-
-	if i >= 0 {
-		version = version[:i]
-	}
-
-	v, err := strconv.Atoi(version)
+// QueueDepth returns the number of data items waiting to be processed by the workers.
+func (tracker *Tracker) QueueDepth() int {
+	return len(tracker.data)
+}
 
-	if err != nil {
-		return false
+// ignore applies the rules and returns the reason in case the request should be ignored.
+// The returned Request caches the User-Agent, IP, and autonomous system looked up by the rules, so that they are not looked up twice.
+// The query of the tracked page is used to read the referrer. If nil, the query of the request URL is used.
+func (tracker *Tracker) ignore(r *http.Request, clientID uint64, settings *ClientSettings, query url.Values) (*Request, string) {
+	if query == nil {
+		query = r.URL.Query()
+	}
+
+	req := &Request{
+		Request:             r,
+		ClientID:            clientID,
+		Settings:            settings,
+		headerParser:        tracker.config.HeaderParser,
+		allowedProxySubnets: tracker.config.AllowedProxySubnets,
+		referrerParams:      tracker.referrerParams,
+		query:               query,
+		asnDB:               tracker.config.ASNDB,
+	}
+
+	for _, rule := range tracker.config.Rules {
+		if reason := rule.Ignore(req); reason != "" {
+			tracker.stats.ignore(reason)
+			return req, reason
+		}
 	}
 
-	return v < min
+	return req, ""
 }
 
-func (tracker *Tracker) getSession(t eventType, clientID uint64, r *http.Request, now time.Time, ua model.UserAgent, ip string, pageViews uint16, options Options) (*model.Session, *model.Session, uint32, bool) {
+func (tracker *Tracker) getSession(t eventType, clientID uint64, req *Request, now time.Time, pageViews uint16, options Options, settings *ClientSettings) (*model.Session, *model.Session, uint32, bool) {
+	ua, ip := req.UserAgent(), req.IP()
 	fingerprint := tracker.fingerprint(tracker.config.Salt, ua.UserAgent, ip, now)
 	m := tracker.config.SessionCache.NewMutex(clientID, fingerprint)
 	m.Lock()
-	maxAge := now.Add(-sessionMaxAge)
+	maxAge := now.Add(-settings.SessionTimeout)
 	session := tracker.config.SessionCache.Get(clientID, fingerprint, maxAge)
 
 	// if the maximum session age reaches yesterday, we also need to check for the previous day (different fingerprint)
@@ -408,11 +449,12 @@ func (tracker *Tracker) getSession(t eventType, clientID uint64, r *http.Request
 	bounced := false // bounced not including session creation
 	var cancelSession *model.Session
 
-	if session == nil || tracker.referrerOrCampaignChanged(r, session, options.Referrer, options.Hostname) {
-		session = tracker.newSession(clientID, r, fingerprint, now, ua, ip, pageViews, options)
+	if session == nil || (!settings.DisableSessionSplit && tracker.referrerOrCampaignChanged(req.Request, session, options.Referrer, options.Hostname, options.query)) {
+		session = tracker.newSession(clientID, req, fingerprint, now, pageViews, options, settings)
 		tracker.config.SessionCache.Put(clientID, fingerprint, session)
+		tracker.stats.sessionsCreated.Add(1)
 	} else {
-		if tracker.config.MaxPageViews > 0 && session.PageViews >= tracker.config.MaxPageViews {
+		if settings.MaxPageViews > 0 && session.PageViews >= settings.MaxPageViews {
 			return nil, nil, 0, false
 		}
 
@@ -421,32 +463,46 @@ func (tracker *Tracker) getSession(t eventType, clientID uint64, r *http.Request
 		cancelSession.Sign = -1
 		timeOnPage, bounced = tracker.updateSession(t, session, now, options.Path, options.Title)
 		tracker.config.SessionCache.Put(clientID, fingerprint, session)
+		tracker.stats.sessionsContinued.Add(1)
 	}
 
 	return session, cancelSession, timeOnPage, bounced
 }
 
-func (tracker *Tracker) newSession(clientID uint64, r *http.Request, fingerprint uint64, now time.Time, ua model.UserAgent, ip string, pageViews uint16, options Options) *model.Session {
+func (tracker *Tracker) newSession(clientID uint64, req *Request, fingerprint uint64, now time.Time, pageViews uint16, options Options, settings *ClientSettings) *model.Session {
+	r, ua, ip := req.Request, req.UserAgent(), req.IP()
 	ua.OS = util2.ShortenString(ua.OS, 20)
 	ua.OSVersion = util2.ShortenString(ua.OSVersion, 20)
 	ua.Browser = util2.ShortenString(ua.Browser, 20)
 	ua.BrowserVersion = util2.ShortenString(ua.BrowserVersion, 20)
 	lang := util2.ShortenString(tracker.getLanguage(r), 10)
-	ref, referrerName, referrerIcon := referrer.Get(r, options.Referrer, options.Hostname)
+	ref, referrerName, referrerIcon := referrer.GetWithParams(r, options.query, options.Referrer, options.Hostname, tracker.referrerParams)
 	ref = util2.ShortenString(ref, 200)
 	referrerName = util2.ShortenString(referrerName, 200)
 	referrerIcon = util2.ShortenString(referrerIcon, 2000)
 	screenClass := tracker.getScreenClass(r, options.ScreenWidth)
-	query := r.URL.Query()
-	utmSource := strings.TrimSpace(query.Get("utm_source"))
-	utmMedium := strings.TrimSpace(query.Get("utm_medium"))
-	utmCampaign := strings.TrimSpace(query.Get("utm_campaign"))
-	utmContent := strings.TrimSpace(query.Get("utm_content"))
-	utmTerm := strings.TrimSpace(query.Get("utm_term"))
-	countryCode, city := "", ""
+	utm := tracker.config.CampaignParams.campaign(options.query)
+	clickID, clickIDValue := referrer.GetClickID(options.query)
+
+	if utm.source == "" {
+		utm.source = clickID.Source
+	}
+
+	if utm.medium == "" {
+		utm.medium = clickID.Medium
+	}
+
+	channel := referrer.Channel(referrer.ChannelData{
+		Referrer:     ref,
+		ReferrerName: referrerName,
+		UTMSource:    utm.source,
+		UTMMedium:    utm.medium,
+		ClickID:      clickID.Param,
+	}, settings.ChannelRules...)
+	countryCode, region, city := "", "", ""
 
 	if tracker.config.GeoDB != nil {
-		countryCode, city = tracker.config.GeoDB.GetLocation(ip)
+		countryCode, region, city = tracker.config.GeoDB.GetLocationWithRegion(ip)
 	}
 
 	return &model.Session{
@@ -456,6 +512,7 @@ func (tracker *Tracker) newSession(clientID uint64, r *http.Request, fingerprint
 		SessionID:      util2.RandUint32(),
 		Time:           now,
 		Start:          now,
+		Hostname:       options.Hostname,
 		EntryPath:      options.Path,
 		ExitPath:       options.Path,
 		PageViews:      pageViews,
@@ -464,10 +521,14 @@ func (tracker *Tracker) newSession(clientID uint64, r *http.Request, fingerprint
 		ExitTitle:      options.Title,
 		Language:       lang,
 		CountryCode:    countryCode,
+		Region:         region,
 		City:           city,
 		Referrer:       ref,
 		ReferrerName:   referrerName,
 		ReferrerIcon:   referrerIcon,
+		Channel:        channel,
+		AdNetwork:      clickID.Network,
+		ConnectionType: tracker.getConnectionType(req),
 		OS:             ua.OS,
 		OSVersion:      ua.OSVersion,
 		Browser:        ua.Browser,
@@ -475,11 +536,12 @@ func (tracker *Tracker) newSession(clientID uint64, r *http.Request, fingerprint
 		Desktop:        ua.IsDesktop(),
 		Mobile:         ua.IsMobile(),
 		ScreenClass:    screenClass,
-		UTMSource:      utmSource,
-		UTMMedium:      utmMedium,
-		UTMCampaign:    utmCampaign,
-		UTMContent:     utmContent,
-		UTMTerm:        utmTerm,
+		UTMSource:      utm.source,
+		UTMMedium:      utm.medium,
+		UTMCampaign:    utm.campaign,
+		UTMContent:     utm.content,
+		UTMTerm:        utm.term,
+		ClickIDHash:    tracker.hashClickID(clickIDValue),
 	}
 }
 
@@ -569,24 +631,39 @@ func (tracker *Tracker) getScreenWidthFromHeader(r *http.Request, header string)
 	return 0
 }
 
-func (tracker *Tracker) referrerOrCampaignChanged(r *http.Request, session *model.Session, ref, hostname string) bool {
-	ref, _, _ = referrer.Get(r, ref, hostname)
+func (tracker *Tracker) referrerOrCampaignChanged(r *http.Request, session *model.Session, ref, hostname string, query url.Values) bool {
+	ref, _, _ = referrer.GetWithParams(r, query, ref, hostname, tracker.referrerParams)
 
 	if ref != "" && ref != session.Referrer {
 		return true
 	}
 
-	query := r.URL.Query()
-	utmSource := strings.TrimSpace(query.Get("utm_source"))
-	utmMedium := strings.TrimSpace(query.Get("utm_medium"))
-	utmCampaign := strings.TrimSpace(query.Get("utm_campaign"))
-	utmContent := strings.TrimSpace(query.Get("utm_content"))
-	utmTerm := strings.TrimSpace(query.Get("utm_term"))
-	return (utmSource != "" && utmSource != session.UTMSource) ||
-		(utmMedium != "" && utmMedium != session.UTMMedium) ||
-		(utmCampaign != "" && utmCampaign != session.UTMCampaign) ||
-		(utmContent != "" && utmContent != session.UTMContent) ||
-		(utmTerm != "" && utmTerm != session.UTMTerm)
+	if clickID, value := referrer.GetClickID(query); value != "" {
+		// the hash is not stored and missing for sessions loaded from the database, so the ad network is compared instead
+		if session.ClickIDHash != 0 {
+			if tracker.hashClickID(value) != session.ClickIDHash {
+				return true
+			}
+		} else if clickID.Network != session.AdNetwork {
+			return true
+		}
+	}
+
+	utm := tracker.config.CampaignParams.campaign(query)
+	return (utm.source != "" && utm.source != session.UTMSource) ||
+		(utm.medium != "" && utm.medium != session.UTMMedium) ||
+		(utm.campaign != "" && utm.campaign != session.UTMCampaign) ||
+		(utm.content != "" && utm.content != session.UTMContent) ||
+		(utm.term != "" && utm.term != session.UTMTerm)
+}
+
+// hashClickID returns the hash of given click ID, so that new ad clicks can be detected without keeping the click ID itself.
+func (tracker *Tracker) hashClickID(clickID string) uint64 {
+	if clickID == "" {
+		return 0
+	}
+
+	return siphash.Hash(tracker.config.FingerprintKey0, tracker.config.FingerprintKey1, []byte(clickID))
 }
 
 func (tracker *Tracker) fingerprint(salt, ua, ip string, now time.Time) uint64 {
@@ -615,58 +692,109 @@ func (tracker *Tracker) stopWorker() {
 	}
 }
 
-func (tracker *Tracker) flushData() {
-	bufferSize := tracker.config.WorkerBufferSize
-	sessions := make([]model.Session, 0, bufferSize*2)
-	pageViews := make([]model.PageView, 0, bufferSize)
-	events := make([]model.Event, 0, bufferSize)
-	userAgents := make([]model.UserAgent, 0, bufferSize)
-	bots := make([]model.Bot, 0, bufferSize)
-
-	for {
-		stop := false
+// acquire reserves space in the buffer for a data item, depending on the BackPressure policy.
+// It returns false in case the data item must be dropped.
+func (tracker *Tracker) acquire() bool {
+	switch tracker.config.BackPressure {
+	case BackPressureDrop:
+		select {
+		case tracker.slots <- struct{}{}:
+			return true
+		default:
+		}
+	case BackPressureTimeout:
+		timer := time.NewTimer(tracker.config.BackPressureTimeout)
+		defer timer.Stop()
 
 		select {
-		case data := <-tracker.data:
-			if data.cancelSession != nil {
-				sessions = append(sessions, *data.cancelSession)
-			}
+		case tracker.slots <- struct{}{}:
+			return true
+		case <-timer.C:
+		}
+	default:
+		tracker.slots <- struct{}{}
+		return true
+	}
 
-			if data.session != nil {
-				sessions = append(sessions, *data.session)
-			}
+	tracker.dropped.Add(1)
+	return false
+}
 
-			if data.pageView != nil {
-				pageViews = append(pageViews, *data.pageView)
-			}
+// release frees the space reserved by acquire.
+func (tracker *Tracker) release() {
+	<-tracker.slots
+}
 
-			if data.event != nil {
-				events = append(events, *data.event)
-			}
+// enqueue writes the data item to the WAL (if enabled) and hands it to the workers.
+// The space reserved by acquire is released in case the data item is nil.
+func (tracker *Tracker) enqueue(data *data) {
+	if data == nil {
+		tracker.release()
+		return
+	}
 
-			if data.ua != nil {
-				userAgents = append(userAgents, *data.ua)
-			}
+	d := *data
 
-			if data.bot != nil {
-				bots = append(bots, *data.bot)
-			}
+	if tracker.wal != nil {
+		ref, err := tracker.wal.append(d)
+
+		if err != nil {
+			tracker.config.Logger.Error("error writing to WAL", "err", err)
+		}
 
-			if len(sessions)+2 >= bufferSize*2 ||
-				len(pageViews)+1 >= bufferSize ||
-				len(events)+1 >= bufferSize ||
-				len(userAgents)+1 >= bufferSize ||
-				len(bots)+1 >= bufferSize {
-				tracker.saveSessions(sessions)
-				tracker.savePageViews(pageViews)
-				tracker.saveEvents(events)
-				tracker.saveUserAgents(userAgents)
-				tracker.saveBots(bots)
-				sessions = sessions[:0]
-				pageViews = pageViews[:0]
-				events = events[:0]
-				userAgents = userAgents[:0]
-				bots = bots[:0]
+		d.walRef = ref
+	}
+
+	tracker.data <- d
+}
+
+// openWAL opens the WAL and saves all data that hasn't been saved before the Tracker was stopped the last time.
+func (tracker *Tracker) openWAL() {
+	w, replay, files, err := openWAL(tracker.config.WALDir)
+
+	if err != nil {
+		tracker.config.Logger.Error("error opening WAL, data won't be written ahead", "err", err, "dir", tracker.config.WALDir)
+		return
+	}
+
+	tracker.wal = w
+
+	if len(replay) > 0 {
+		tracker.config.Logger.Info("replaying WAL", "entries", len(replay))
+		batch := newBatch(0)
+
+		for _, d := range replay {
+			batch.add(d)
+		}
+
+		batch.Sessions = collapseSessions(batch.Sessions)
+
+		// keep the segments to replay them again on the next start in case the data is lost otherwise
+		if !tracker.saveBatch(batch) {
+			tracker.config.Logger.Error("error replaying WAL, the segments are kept for the next start", "dir", tracker.config.WALDir)
+			return
+		}
+	}
+
+	if err := w.removeReplayed(files); err != nil {
+		tracker.config.Logger.Error("error removing replayed WAL segments", "err", err)
+	}
+}
+
+func (tracker *Tracker) flushData() {
+	batch := newBatch(tracker.config.WorkerBufferSize)
+
+	for {
+		stop := false
+
+		select {
+		case data := <-tracker.data:
+			tracker.release()
+			batch.add(data)
+
+			if batch.full(tracker.config.WorkerBufferSize) {
+				tracker.saveBatch(batch)
+				batch.reset()
 			}
 		default:
 			stop = true
@@ -677,20 +805,11 @@ func (tracker *Tracker) flushData() {
 		}
 	}
 
-	tracker.saveSessions(sessions)
-	tracker.savePageViews(pageViews)
-	tracker.saveEvents(events)
-	tracker.saveUserAgents(userAgents)
-	tracker.saveBots(bots)
+	tracker.saveBatch(batch)
 }
 
 func (tracker *Tracker) aggregateData(ctx context.Context) {
-	bufferSize := tracker.config.WorkerBufferSize
-	sessions := make([]model.Session, 0, bufferSize*2)
-	pageViews := make([]model.PageView, 0, bufferSize)
-	events := make([]model.Event, 0, bufferSize)
-	userAgents := make([]model.UserAgent, 0, bufferSize)
-	bots := make([]model.Bot, 0, bufferSize)
+	batch := newBatch(tracker.config.WorkerBufferSize)
 	timer := time.NewTimer(tracker.config.WorkerTimeout)
 	defer timer.Stop()
 
@@ -699,105 +818,157 @@ func (tracker *Tracker) aggregateData(ctx context.Context) {
 
 		select {
 		case data := <-tracker.data:
-			if data.cancelSession != nil {
-				sessions = append(sessions, *data.cancelSession)
-			}
+			tracker.release()
+			batch.add(data)
 
-			if data.session != nil {
-				sessions = append(sessions, *data.session)
+			if batch.full(tracker.config.WorkerBufferSize) {
+				tracker.saveBatch(batch)
+				batch.reset()
 			}
+		case <-timer.C:
+			tracker.saveBatch(batch)
+			batch.reset()
+		case <-ctx.Done():
+			tracker.saveBatch(batch)
+			tracker.done <- true
+			return
+		}
+	}
+}
 
-			if data.pageView != nil {
-				pageViews = append(pageViews, *data.pageView)
-			}
+// saveBatch saves the batch to the Store, retrying failed writes.
+// Anything that still cannot be saved is spooled to disk or passed to the error callback.
+// Once the Store accepts writes again, previously spooled batches are replayed.
+// It returns whether the batch has been persisted (saved, spooled, passed to the error callback, or kept in the WAL).
+// In case parts of the batch could not be persisted otherwise, they are written to the WAL again
+// and the original entries are acknowledged, so that only the failed parts are replayed on the next start.
+func (tracker *Tracker) saveBatch(batch *Batch) bool {
+	start := time.Now()
+	failed, err := tracker.save(batch, tracker.config.StoreRetries)
 
-			if data.event != nil {
-				events = append(events, *data.event)
-			}
+	if !batch.empty() {
+		tracker.stats.flushed(time.Since(start), err != nil)
+	}
 
-			if data.ua != nil {
-				userAgents = append(userAgents, *data.ua)
-			}
+	persisted := true
 
-			if data.bot != nil {
-				bots = append(bots, *data.bot)
-			}
+	if err != nil {
+		persisted = tracker.storeFailed(failed, err)
+	} else if tracker.spool != nil {
+		tracker.replaySpool()
+	}
+
+	if tracker.wal != nil {
+		if !persisted {
+			persisted = tracker.appendWAL(failed)
+		}
 
-			if len(sessions)+2 >= bufferSize*2 ||
-				len(pageViews)+1 >= bufferSize ||
-				len(events)+1 >= bufferSize ||
-				len(userAgents)+1 >= bufferSize ||
-				len(bots)+1 >= bufferSize {
-				tracker.saveSessions(sessions)
-				tracker.savePageViews(pageViews)
-				tracker.saveEvents(events)
-				tracker.saveUserAgents(userAgents)
-				tracker.saveBots(bots)
-				sessions = sessions[:0]
-				pageViews = pageViews[:0]
-				events = events[:0]
-				userAgents = userAgents[:0]
-				bots = bots[:0]
+		if persisted {
+			if err := tracker.wal.ack(batch.walRefs); err != nil {
+				tracker.config.Logger.Error("error acknowledging WAL entries", "err", err)
 			}
-		case <-timer.C:
-			tracker.saveSessions(sessions)
-			tracker.savePageViews(pageViews)
-			tracker.saveEvents(events)
-			tracker.saveUserAgents(userAgents)
-			tracker.saveBots(bots)
-			sessions = sessions[:0]
-			pageViews = pageViews[:0]
-			events = events[:0]
-			userAgents = userAgents[:0]
-			bots = bots[:0]
-		case <-ctx.Done():
-			tracker.saveSessions(sessions)
-			tracker.savePageViews(pageViews)
-			tracker.saveEvents(events)
-			tracker.saveUserAgents(userAgents)
-			tracker.saveBots(bots)
-			tracker.done <- true
-			return
 		}
 	}
+
+	return persisted
 }
 
-func (tracker *Tracker) savePageViews(pageViews []model.PageView) {
-	if len(pageViews) > 0 {
-		if err := tracker.config.Store.SavePageViews(pageViews); err != nil {
-			log.Panicf("error saving page views: %s", err)
+// appendWAL writes the parts of a batch that could not be saved to the WAL and returns whether all of them have been written.
+func (tracker *Tracker) appendWAL(batch *Batch) bool {
+	for _, d := range batch.items() {
+		if _, err := tracker.wal.append(d); err != nil {
+			tracker.config.Logger.Error("error writing failed batch to WAL", "err", err)
+			return false
 		}
 	}
+
+	return true
 }
 
-func (tracker *Tracker) saveSessions(sessions []model.Session) {
-	if len(sessions) > 0 {
-		if err := tracker.config.Store.SaveSessions(sessions); err != nil {
-			log.Panicf("error saving sessions: %s", err)
-		}
+// save saves all parts of the batch and returns those that could not be saved after given number of retries.
+func (tracker *Tracker) save(batch *Batch, retries int) (*Batch, error) {
+	if batch.empty() {
+		return nil, nil
 	}
+
+	failed := new(Batch)
+	var errs [5]error
+	failed.Sessions, errs[0] = saveWithRetry(tracker, "sessions", batch.Sessions, tracker.config.Store.SaveSessions, retries)
+	failed.PageViews, errs[1] = saveWithRetry(tracker, "page views", batch.PageViews, tracker.config.Store.SavePageViews, retries)
+	failed.Events, errs[2] = saveWithRetry(tracker, "events", batch.Events, tracker.config.Store.SaveEvents, retries)
+	failed.UserAgents, errs[3] = saveWithRetry(tracker, "user agents", batch.UserAgents, tracker.config.Store.SaveUserAgents, retries)
+	failed.Bots, errs[4] = saveWithRetry(tracker, "bots", batch.Bots, tracker.config.Store.SaveBots, retries)
+
+	if err := errors.Join(errs[:]...); err != nil {
+		return failed, err
+	}
+
+	return nil, nil
 }
 
-func (tracker *Tracker) saveEvents(events []model.Event) {
-	if len(events) > 0 {
-		if err := tracker.config.Store.SaveEvents(events); err != nil {
-			log.Panicf("error saving events: %s", err)
+// storeFailed spools the batch or passes it to the error callback and returns whether it has been handed off.
+func (tracker *Tracker) storeFailed(batch *Batch, err error) bool {
+	if tracker.spool != nil {
+		spoolErr := tracker.spool.write(batch)
+
+		if spoolErr == nil {
+			return true
 		}
+
+		tracker.config.Logger.Error("error spooling batch", "err", spoolErr)
+	}
+
+	if tracker.config.OnStoreError != nil {
+		tracker.config.OnStoreError(*batch, err)
+		return true
+	}
+
+	msg := "discarding batch after failing to save it"
+
+	if tracker.wal != nil {
+		msg = "failed to save batch, it will be replayed from the WAL on the next start"
 	}
+
+	tracker.config.Logger.Error(msg, "err", err,
+		"sessions", len(batch.Sessions),
+		"page_views", len(batch.PageViews),
+		"events", len(batch.Events))
+	return false
 }
 
-func (tracker *Tracker) saveUserAgents(userAgents []model.UserAgent) {
-	if len(userAgents) > 0 {
-		if err := tracker.config.Store.SaveUserAgents(userAgents); err != nil {
-			tracker.config.Logger.Error("error saving user agents", "err", err)
-		}
+func (tracker *Tracker) replaySpool() {
+	if !tracker.replaying.CompareAndSwap(false, true) {
+		return
+	}
+
+	defer tracker.replaying.Store(false)
+
+	if err := tracker.spool.replay(func(batch *Batch) (*Batch, error) {
+		return tracker.save(batch, 0)
+	}); err != nil {
+		tracker.config.Logger.Error("error replaying spooled batches", "err", err)
 	}
 }
 
-func (tracker *Tracker) saveBots(bots []model.Bot) {
-	if len(bots) > 0 {
-		if err := tracker.config.Store.SaveBots(bots); err != nil {
-			tracker.config.Logger.Error("error saving bots", "err", err)
-		}
+func saveWithRetry[T any](tracker *Tracker, name string, items []T, save func([]T) error, retries int) ([]T, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	backoff := tracker.config.StoreRetryBackoff
+	err := save(items)
+
+	for i := 0; i < retries && err != nil; i++ {
+		tracker.config.Logger.Warn(fmt.Sprintf("error saving %s, retrying", name), "err", err, "attempt", i+1, "backoff", backoff)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxStoreRetryBackoff)
+		err = save(items)
+	}
+
+	if err != nil {
+		tracker.config.Logger.Error(fmt.Sprintf("error saving %s", name), "err", err)
+		return slices.Clone(items), err
 	}
+
+	return nil, nil
 }
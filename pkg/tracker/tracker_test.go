@@ -1,6 +1,7 @@
 package tracker
 
 import (
+	"errors"
 	"fmt"
 	"github.com/go-redis/redis/v8"
 	"github.com/pirsch-analytics/pirsch/v6/pkg"
@@ -11,8 +12,11 @@ import (
 	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/session"
 	"github.com/pirsch-analytics/pirsch/v6/pkg/tracker/ua"
 	"github.com/stretchr/testify/assert"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -104,6 +108,7 @@ func TestTracker_PageView(t *testing.T) {
 	assert.Equal(t, "fr", sessions[0].Language)
 	assert.Equal(t, "gb", sessions[0].CountryCode)
 	assert.Equal(t, "London", sessions[0].City)
+	assert.Equal(t, "England", sessions[0].Region)
 	assert.Equal(t, "https://google.com", sessions[0].Referrer)
 	assert.Equal(t, "Google", sessions[0].ReferrerName)
 	assert.Equal(t, pkg.OSLinux, sessions[0].OS)
@@ -127,6 +132,7 @@ func TestTracker_PageView(t *testing.T) {
 	assert.Equal(t, "fr", pageViews[0].Language)
 	assert.Equal(t, "gb", pageViews[0].CountryCode)
 	assert.Equal(t, "London", pageViews[0].City)
+	assert.Equal(t, "England", pageViews[0].Region)
 	assert.Equal(t, "https://google.com", pageViews[0].Referrer)
 	assert.Equal(t, "Google", pageViews[0].ReferrerName)
 	assert.Equal(t, pkg.OSLinux, pageViews[0].OS)
@@ -459,6 +465,7 @@ func TestTracker_Event(t *testing.T) {
 	assert.Equal(t, "fr", events[0].Language)
 	assert.Equal(t, "gb", events[0].CountryCode)
 	assert.Equal(t, "London", events[0].City)
+	assert.Equal(t, "England", events[0].Region)
 	assert.Equal(t, "https://google.com", events[0].Referrer)
 	assert.Equal(t, "Google", events[0].ReferrerName)
 	assert.Equal(t, pkg.OSLinux, events[0].OS)
@@ -664,6 +671,105 @@ func TestTracker_Flush(t *testing.T) {
 	assert.Equal(t, 10, count)
 }
 
+func TestTracker_StoreErrorSpool(t *testing.T) {
+	store := db.NewClientMock()
+	store.SetSaveError(errors.New("store unavailable"))
+	tracker := NewTracker(Config{
+		Store:             store,
+		StoreRetries:      1,
+		StoreRetryBackoff: time.Millisecond,
+		SpoolDir:          t.TempDir(),
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", userAgent)
+	tracker.PageView(req, 0, Options{})
+	tracker.Flush()
+	assert.Empty(t, store.GetSessions())
+	assert.Empty(t, store.GetPageViews())
+	assert.EqualValues(t, 1, tracker.spool.pending.Load())
+	store.SetSaveError(nil)
+	tracker.Stop()
+	assert.Len(t, store.GetSessions(), 1)
+	assert.Len(t, store.GetPageViews(), 1)
+	assert.Len(t, store.GetUserAgents(), 1)
+	assert.EqualValues(t, 0, tracker.spool.pending.Load())
+	files, err := tracker.spool.files()
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestSpool_ReplayCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "00000000000000000001_1.json"), []byte(`{"page_views":[{"pa`), 0644))
+	s, err := newSpool(dir, slog.Default())
+	assert.NoError(t, err)
+	assert.NoError(t, s.write(&Batch{PageViews: []model.PageView{{Path: "/"}}}))
+	assert.EqualValues(t, 2, s.pending.Load())
+	var saved []Batch
+	assert.NoError(t, s.replay(func(batch *Batch) (*Batch, error) {
+		saved = append(saved, *batch)
+		return nil, nil
+	}))
+	assert.Len(t, saved, 1)
+	assert.Equal(t, "/", saved[0].PageViews[0].Path)
+	assert.EqualValues(t, 0, s.pending.Load())
+	files, err := s.files()
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+	assert.FileExists(t, filepath.Join(dir, "00000000000000000001_1.corrupt"))
+}
+
+func TestTracker_StoreErrorCallback(t *testing.T) {
+	store := db.NewClientMock()
+	store.SetSaveError(errors.New("store unavailable"))
+	var failed []Batch
+	tracker := NewTracker(Config{
+		Store:        store,
+		StoreRetries: -1,
+		OnStoreError: func(batch Batch, err error) {
+			assert.EqualError(t, err, "store unavailable\nstore unavailable\nstore unavailable")
+			failed = append(failed, batch)
+		},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", userAgent)
+	tracker.PageView(req, 0, Options{})
+	tracker.Stop()
+	assert.Len(t, failed, 1)
+	assert.Len(t, failed[0].Sessions, 1)
+	assert.Len(t, failed[0].PageViews, 1)
+	assert.Len(t, failed[0].UserAgents, 1)
+	assert.Empty(t, failed[0].Events)
+}
+
+func TestTracker_BackPressure(t *testing.T) {
+	for _, policy := range []BackPressure{BackPressureDrop, BackPressureTimeout} {
+		store := db.NewClientMock()
+		tracker := NewTracker(Config{
+			Store:               store,
+			Worker:              1,
+			WorkerBufferSize:    5,
+			BackPressure:        policy,
+			BackPressureTimeout: time.Millisecond * 10,
+		})
+		tracker.stopWorker()
+
+		for i := 0; i < 7; i++ {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = fmt.Sprintf("187.65.23.%d", i)
+			req.Header.Set("User-Agent", userAgent)
+			tracker.PageView(req, 0, Options{})
+		}
+
+		assert.Equal(t, 5, tracker.QueueDepth())
+		assert.Equal(t, uint64(2), tracker.Dropped())
+		tracker.startWorker()
+		tracker.Stop()
+		assert.Equal(t, 0, tracker.QueueDepth())
+		assert.Len(t, store.GetPageViews(), 5)
+	}
+}
+
 func TestTrackerBots(t *testing.T) {
 	store := db.NewClientMock()
 	tracker := NewTracker(Config{
@@ -704,6 +810,8 @@ func TestTrackerBots(t *testing.T) {
 	assert.Equal(t, "Bot", bots[1].UserAgent)
 	assert.Equal(t, "Bot", bots[2].UserAgent)
 	assert.Equal(t, "Event Bot", bots[3].UserAgent)
+	assert.Equal(t, ReasonUserAgentLength, bots[0].Reason)
+	assert.Equal(t, ReasonUserAgentLength, bots[3].Reason)
 	assert.Equal(t, "/path", bots[0].Path)
 	assert.Equal(t, "/path", bots[1].Path)
 	assert.Equal(t, "/path", bots[2].Path)
@@ -720,39 +828,39 @@ func TestTracker_ignorePrefetch(t *testing.T) {
 	req.Header.Add("User-Agent", userAgent)
 	req.Header.Set("X-Moz", "prefetch")
 
-	if _, _, ignore := tracker.ignore(req); !ignore {
+	if _, ignore := tracker.ignore(req, 0, nil, nil); ignore == "" {
 		t.Fatal("Session with X-Moz header must be ignored")
 	}
 
 	req.Header.Del("X-Moz")
 	req.Header.Set("X-Purpose", "prefetch")
 
-	if _, _, ignore := tracker.ignore(req); !ignore {
+	if _, ignore := tracker.ignore(req, 0, nil, nil); ignore == "" {
 		t.Fatal("Session with X-Purpose header must be ignored")
 	}
 
 	req.Header.Set("X-Purpose", "preview")
 
-	if _, _, ignore := tracker.ignore(req); !ignore {
+	if _, ignore := tracker.ignore(req, 0, nil, nil); ignore == "" {
 		t.Fatal("Session with X-Purpose header must be ignored")
 	}
 
 	req.Header.Del("X-Purpose")
 	req.Header.Set("Purpose", "prefetch")
 
-	if _, _, ignore := tracker.ignore(req); !ignore {
+	if _, ignore := tracker.ignore(req, 0, nil, nil); ignore == "" {
 		t.Fatal("Session with Purpose header must be ignored")
 	}
 
 	req.Header.Set("Purpose", "preview")
 
-	if _, _, ignore := tracker.ignore(req); !ignore {
+	if _, ignore := tracker.ignore(req, 0, nil, nil); ignore == "" {
 		t.Fatal("Session with Purpose header must be ignored")
 	}
 
 	req.Header.Del("Purpose")
 
-	if _, _, ignore := tracker.ignore(req); ignore {
+	if _, ignore := tracker.ignore(req, 0, nil, nil); ignore != "" {
 		t.Fatal("Session must not be ignored")
 	}
 }
@@ -782,7 +890,7 @@ func TestTracker_ignoreUserAgent(t *testing.T) {
 	for _, userAgent := range userAgents {
 		req.Header.Set("User-Agent", userAgent.userAgent)
 
-		if _, _, ignore := tracker.ignore(req); ignore != userAgent.ignore {
+		if _, ignore := tracker.ignore(req, 0, nil, nil); (ignore != "") != userAgent.ignore {
 			if userAgent.ignore {
 				t.Fatalf("Request with User-Agent '%s' must be ignored", userAgent.userAgent)
 			} else {
@@ -799,7 +907,7 @@ func TestTracker_ignoreBotUserAgent(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
 		req.Header.Set("User-Agent", botUserAgent)
 
-		if _, _, ignore := tracker.ignore(req); !ignore {
+		if _, ignore := tracker.ignore(req, 0, nil, nil); ignore == "" {
 			t.Fatalf("Request with user agent '%v' must have been ignored", botUserAgent)
 		}
 	}
@@ -815,7 +923,7 @@ func TestTracker_ignoreBotUserAgent(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
 		req.Header.Set("User-Agent", userAgent)
 
-		if _, _, ignore := tracker.ignore(req); !ignore {
+		if _, ignore := tracker.ignore(req, 0, nil, nil); ignore == "" {
 			t.Fatalf("Request with user agent '%v' must have been ignored", botUserAgent)
 		}
 	}
@@ -827,19 +935,19 @@ func TestTracker_ignoreReferrer(t *testing.T) {
 	req.Header.Set("User-Agent", "ua")
 	req.Header.Set("Referer", "2your.site")
 
-	if _, _, ignore := tracker.ignore(req); !ignore {
+	if _, ignore := tracker.ignore(req, 0, nil, nil); ignore == "" {
 		t.Fatal("Request must have been ignored")
 	}
 
 	req.Header.Set("Referer", "subdomain.2your.site")
 
-	if _, _, ignore := tracker.ignore(req); !ignore {
+	if _, ignore := tracker.ignore(req, 0, nil, nil); ignore == "" {
 		t.Fatal("Request for subdomain must have been ignored")
 	}
 
 	req = httptest.NewRequest(http.MethodGet, "/?ref=2your.site", nil)
 
-	if _, _, ignore := tracker.ignore(req); !ignore {
+	if _, ignore := tracker.ignore(req, 0, nil, nil); ignore == "" {
 		t.Fatal("Request must have been ignored")
 	}
 }
@@ -849,14 +957,14 @@ func TestTracker_ignoreBrowserVersion(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	req.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/61.0.4147.135 Safari/537.36")
 
-	if _, _, ignore := tracker.ignore(req); !ignore {
+	if _, ignore := tracker.ignore(req, 0, nil, nil); ignore == "" {
 		t.Fatal("Request must have been ignored")
 	}
 
 	req = httptest.NewRequest(http.MethodGet, "/", nil)
 	req.Header.Set("User-Agent", userAgent)
 
-	if _, _, ignore := tracker.ignore(req); ignore {
+	if _, ignore := tracker.ignore(req, 0, nil, nil); ignore != "" {
 		t.Fatal("Request must not have been ignored")
 	}
 }
@@ -866,13 +974,13 @@ func TestTracker_ignoreDoNotTrack(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	req.Header.Set("User-Agent", userAgent)
 
-	if _, _, ignore := tracker.ignore(req); ignore {
+	if _, ignore := tracker.ignore(req, 0, nil, nil); ignore != "" {
 		t.Fatal("Request must not have been ignored")
 	}
 
 	req.Header.Set("DNT", "1")
 
-	if _, _, ignore := tracker.ignore(req); !ignore {
+	if _, ignore := tracker.ignore(req, 0, nil, nil); ignore == "" {
 		t.Fatal("Request must have been ignored")
 	}
 }
@@ -886,17 +994,52 @@ func TestTracker_ignoreIP(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	req.Header.Set("User-Agent", userAgent)
 
-	if _, _, ignore := tracker.ignore(req); ignore {
+	if _, ignore := tracker.ignore(req, 0, nil, nil); ignore != "" {
 		t.Fatal("Request must not have been ignored")
 	}
 
 	req.RemoteAddr = "90.154.29.38"
 
-	if _, _, ignore := tracker.ignore(req); !ignore {
+	if _, ignore := tracker.ignore(req, 0, nil, nil); ignore == "" {
 		t.Fatal("Request must have been ignored")
 	}
 }
 
+func TestTracker_ignoreRules(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/61.0.4147.135 Safari/537.36")
+	req.Header.Set("DNT", "1")
+	tracker := NewTracker(Config{})
+	_, reason := tracker.ignore(req, 0, nil, nil)
+	assert.Equal(t, ReasonDoNotTrack, reason)
+	tracker = NewTracker(Config{
+		Rules: []Rule{
+			DefaultBrowserVersionRule(),
+			DoNotTrackRule{},
+		},
+	})
+	_, reason = tracker.ignore(req, 0, nil, nil)
+	assert.Equal(t, ReasonBrowserVersion, reason)
+	tracker = NewTracker(Config{
+		Rules: []Rule{
+			BrowserVersionRule{Chrome: 50},
+			RuleFunc(func(r *Request) string {
+				if r.ClientID == 42 && r.UserAgent().Browser == pkg.BrowserChrome {
+					return "custom"
+				}
+
+				return ""
+			}),
+		},
+	})
+	request, reason := tracker.ignore(req, 0, nil, nil)
+	assert.Empty(t, reason)
+	assert.Equal(t, pkg.BrowserChrome, request.UserAgent().Browser)
+	assert.Equal(t, "192.0.2.1", request.IP())
+	_, reason = tracker.ignore(req, 42, nil, nil)
+	assert.Equal(t, "custom", reason)
+}
+
 func TestTracker_ignorePageViews(t *testing.T) {
 	client := db.NewClientMock()
 	cache := session.NewMemCache(client, 10)
@@ -971,14 +1114,25 @@ func TestTracker_referrerOrCampaignChanged(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/test", nil)
 	req.Header.Set("Referer", "https://referrer.com")
 	s := &model.Session{Referrer: "https://referrer.com"}
-	assert.False(t, tracker.referrerOrCampaignChanged(req, s, "", ""))
+	assert.False(t, tracker.referrerOrCampaignChanged(req, s, "", "", req.URL.Query()))
 	s.Referrer = ""
-	assert.True(t, tracker.referrerOrCampaignChanged(req, s, "", ""))
+	assert.True(t, tracker.referrerOrCampaignChanged(req, s, "", "", req.URL.Query()))
 	s.Referrer = "https://referrer.com"
 	req = httptest.NewRequest(http.MethodGet, "/test?ref=https://different.com", nil)
-	assert.True(t, tracker.referrerOrCampaignChanged(req, s, "", ""))
+	assert.True(t, tracker.referrerOrCampaignChanged(req, s, "", "", req.URL.Query()))
 	req = httptest.NewRequest(http.MethodGet, "/test?utm_source=Referrer", nil)
-	assert.True(t, tracker.referrerOrCampaignChanged(req, s, "", ""))
+	assert.True(t, tracker.referrerOrCampaignChanged(req, s, "", "", req.URL.Query()))
 	s.UTMSource = "Referrer"
-	assert.False(t, tracker.referrerOrCampaignChanged(req, s, "", ""))
+	assert.False(t, tracker.referrerOrCampaignChanged(req, s, "", "", req.URL.Query()))
+	req = httptest.NewRequest(http.MethodGet, "/test?gclid=abc", nil)
+	assert.True(t, tracker.referrerOrCampaignChanged(req, s, "", "", req.URL.Query()))
+	s.ClickIDHash = tracker.hashClickID("abc")
+	assert.False(t, tracker.referrerOrCampaignChanged(req, s, "", "", req.URL.Query()))
+	req = httptest.NewRequest(http.MethodGet, "/test?gclid=def", nil)
+	assert.True(t, tracker.referrerOrCampaignChanged(req, s, "", "", req.URL.Query()))
+	s.ClickIDHash = 0
+	s.AdNetwork = "Google Ads"
+	assert.False(t, tracker.referrerOrCampaignChanged(req, s, "", "", req.URL.Query()))
+	req = httptest.NewRequest(http.MethodGet, "/test?fbclid=def", nil)
+	assert.True(t, tracker.referrerOrCampaignChanged(req, s, "", "", req.URL.Query()))
 }
@@ -0,0 +1,360 @@
+package tracker
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/model"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	walFileExt           = ".wal"
+	walMaxSegmentEntries = 10_000
+	walMaxLineSize       = 1024 * 1024
+)
+
+// walEntry is a single line in a WAL segment.
+// It either contains a data item or a list of sequence numbers that have been saved (acknowledged).
+type walEntry struct {
+	Seq           uint64           `json:"seq,omitempty"`
+	Session       *model.Session   `json:"session,omitempty"`
+	CancelSession *model.Session   `json:"cancel_session,omitempty"`
+	PageView      *model.PageView  `json:"page_view,omitempty"`
+	Event         *model.Event     `json:"event,omitempty"`
+	UserAgent     *model.UserAgent `json:"user_agent,omitempty"`
+	Bot           *model.Bot       `json:"bot,omitempty"`
+	Ack           []uint64         `json:"ack,omitempty"`
+}
+
+// walRef references a data item written to the WAL.
+type walRef struct {
+	segment uint64
+	seq     uint64
+}
+
+type walSegment struct {
+	id      uint64
+	written int
+	acked   int
+}
+
+// wal is a write-ahead log for data that has been accepted by the Tracker but not saved to the Store yet.
+// Each data item is appended before it is handed to the workers and acknowledged once it has been saved,
+// spooled, or passed to the Config.OnStoreError callback. The parts of a batch that would be lost otherwise
+// are appended again as new entries before the original entries are acknowledged, so that parts that have been saved are not replayed.
+// Segments are deleted in order as soon as all of their entries have been acknowledged.
+// After a crash, NewTracker replays all data items that haven't been acknowledged.
+//
+// Entries are written to the OS without calling fsync, so that they survive the process being killed, but not a power loss.
+// A crash between saving a batch and writing the acknowledgement results in that batch being saved twice.
+type wal struct {
+	dir      string
+	file     *os.File
+	segments []walSegment
+	seq      uint64
+	m        sync.Mutex
+}
+
+// openWAL opens the WAL in given directory.
+// It returns the data items that haven't been acknowledged in the existing segments,
+// as well as the segment files, which must be removed by calling removeReplayed once the data has been saved.
+func openWAL(dir string) (*wal, []data, []string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, nil, err
+	}
+
+	files, err := walFiles(dir)
+
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var lastSegment uint64
+	entries := make([]walEntry, 0)
+	acked := make(map[uint64]struct{})
+
+	for _, file := range files {
+		var id uint64
+
+		if _, err := fmt.Sscanf(strings.TrimSuffix(file, walFileExt), "%d", &id); err == nil && id > lastSegment {
+			lastSegment = id
+		}
+
+		if err := readWALSegment(filepath.Join(dir, file), &entries, acked); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	replay := make([]data, 0, len(entries))
+
+	// continue the sequence, as segments that couldn't be replayed are kept
+	var lastSeq uint64
+
+	for seq := range acked {
+		lastSeq = max(lastSeq, seq)
+	}
+
+	for _, entry := range entries {
+		lastSeq = max(lastSeq, entry.Seq)
+
+		if _, ok := acked[entry.Seq]; !ok {
+			replay = append(replay, data{
+				session:       entry.Session,
+				cancelSession: entry.CancelSession,
+				pageView:      entry.PageView,
+				event:         entry.Event,
+				ua:            entry.UserAgent,
+				bot:           entry.Bot,
+			})
+		}
+	}
+
+	w := &wal{dir: dir, seq: lastSeq}
+
+	if err := w.newSegment(lastSegment + 1); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return w, replay, files, nil
+}
+
+// append writes the data item to the current segment and returns a reference to acknowledge it later on.
+func (w *wal) append(d data) (walRef, error) {
+	w.m.Lock()
+	defer w.m.Unlock()
+	w.seq++
+	segment := &w.segments[len(w.segments)-1]
+
+	if err := w.write(walEntry{
+		Seq:           w.seq,
+		Session:       d.session,
+		CancelSession: d.cancelSession,
+		PageView:      d.pageView,
+		Event:         d.event,
+		UserAgent:     d.ua,
+		Bot:           d.bot,
+	}); err != nil {
+		return walRef{}, err
+	}
+
+	segment.written++
+	ref := walRef{segment: segment.id, seq: w.seq}
+
+	if segment.written >= walMaxSegmentEntries {
+		if err := w.newSegment(segment.id + 1); err != nil {
+			return ref, err
+		}
+	}
+
+	return ref, nil
+}
+
+// ack marks the referenced data items as saved and removes all segments that have been fully acknowledged.
+func (w *wal) ack(refs []walRef) error {
+	if len(refs) == 0 {
+		return nil
+	}
+
+	w.m.Lock()
+	defer w.m.Unlock()
+	seqs := make([]uint64, 0, len(refs))
+
+	for _, ref := range refs {
+		seqs = append(seqs, ref.seq)
+
+		for i := range w.segments {
+			if w.segments[i].id == ref.segment {
+				w.segments[i].acked++
+				break
+			}
+		}
+	}
+
+	if err := w.write(walEntry{Ack: seqs}); err != nil {
+		return err
+	}
+
+	// Segments must be removed in order, as acknowledgements are written to the current segment.
+	// Removing a newer segment first could lose acknowledgements for entries in older segments.
+	for len(w.segments) > 1 && w.segments[0].acked >= w.segments[0].written {
+		if err := os.Remove(w.segmentPath(w.segments[0].id)); err != nil {
+			return err
+		}
+
+		w.segments = w.segments[1:]
+	}
+
+	return nil
+}
+
+// removeReplayed removes the segment files returned by openWAL.
+func (w *wal) removeReplayed(files []string) error {
+	for _, file := range files {
+		if err := os.Remove(filepath.Join(w.dir, file)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// close closes the current segment and removes it in case all entries have been acknowledged.
+func (w *wal) close() error {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+
+	w.file = nil
+
+	for _, segment := range w.segments {
+		if segment.acked < segment.written {
+			return nil
+		}
+	}
+
+	for _, segment := range w.segments {
+		if err := os.Remove(w.segmentPath(segment.id)); err != nil {
+			return err
+		}
+	}
+
+	w.segments = w.segments[:0]
+	return nil
+}
+
+func (w *wal) newSegment(id uint64) error {
+	file, err := os.OpenFile(w.segmentPath(id), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+
+	if err != nil {
+		return err
+	}
+
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+	}
+
+	w.file = file
+	w.segments = append(w.segments, walSegment{id: id})
+	return nil
+}
+
+func (w *wal) write(entry walEntry) error {
+	if w.file == nil {
+		return os.ErrClosed
+	}
+
+	line, err := json.Marshal(entry)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = w.file.Write(append(line, '\n'))
+	return err
+}
+
+func (w *wal) segmentPath(id uint64) string {
+	return filepath.Join(w.dir, fmt.Sprintf("%020d%s", id, walFileExt))
+}
+
+func walFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), walFileExt) {
+			files = append(files, entry.Name())
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+func readWALSegment(path string, entries *[]walEntry, acked map[uint64]struct{}) error {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), walMaxLineSize)
+
+	for scanner.Scan() {
+		var entry walEntry
+
+		// the last line might be incomplete if the process has been killed while writing it
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+
+		if len(entry.Ack) > 0 {
+			for _, seq := range entry.Ack {
+				acked[seq] = struct{}{}
+			}
+		} else if entry.Seq != 0 {
+			*entries = append(*entries, entry)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// collapseSessions removes session states that are canceled within the same list of sessions.
+// A session that has been updated several times is reduced to the cancellation of the state that has been saved before (if any)
+// and the latest state, so that sign pairs stay consistent.
+func collapseSessions(sessions []model.Session) []model.Session {
+	type key struct {
+		clientID  uint64
+		visitorID uint64
+		sessionID uint32
+	}
+
+	latest := make(map[key]int)
+	removed := make([]bool, len(sessions))
+
+	for i, session := range sessions {
+		k := key{session.ClientID, session.VisitorID, session.SessionID}
+
+		if session.Sign < 0 {
+			if j, ok := latest[k]; ok {
+				removed[i] = true
+				removed[j] = true
+				delete(latest, k)
+			}
+		} else {
+			latest[k] = i
+		}
+	}
+
+	result := make([]model.Session, 0, len(sessions))
+
+	for i, session := range sessions {
+		if !removed[i] {
+			result = append(result, session)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,207 @@
+package tracker
+
+import (
+	"errors"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/db"
+	"github.com/pirsch-analytics/pirsch/v6/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestTracker_WALReplay(t *testing.T) {
+	dir := t.TempDir()
+	w, replay, files, err := openWAL(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, replay)
+	assert.Empty(t, files)
+	now := time.Now().UTC()
+	first := model.Session{Sign: 1, ClientID: 1, VisitorID: 2, SessionID: 3, Time: now, PageViews: 1}
+	cancelFirst := first
+	cancelFirst.Sign = -1
+	second := first
+	second.PageViews = 2
+	second.Time = now.Add(time.Second)
+	cancelSecond := second
+	cancelSecond.Sign = -1
+	third := first
+	third.PageViews = 3
+	third.Time = now.Add(time.Second * 2)
+	ref, err := w.append(data{session: &first, pageView: &model.PageView{ClientID: 1, Time: first.Time, Path: "/"}})
+	assert.NoError(t, err)
+	assert.NoError(t, w.ack([]walRef{ref}))
+	_, err = w.append(data{session: &second, cancelSession: &cancelFirst, pageView: &model.PageView{ClientID: 1, Time: second.Time, Path: "/foo"}})
+	assert.NoError(t, err)
+	_, err = w.append(data{session: &third, cancelSession: &cancelSecond, pageView: &model.PageView{ClientID: 1, Time: third.Time, Path: "/bar"}})
+	assert.NoError(t, err)
+
+	// simulate a torn write
+	_, err = w.file.WriteString(`{"seq":4,"session":{"sign":1`)
+	assert.NoError(t, err)
+
+	// simulate a crash by not closing the WAL
+	store := db.NewClientMock()
+	tracker := NewTracker(Config{
+		Store:  store,
+		WALDir: dir,
+	})
+	sessions := store.GetSessions()
+	assert.Len(t, sessions, 2)
+	assert.Equal(t, int8(-1), sessions[0].Sign)
+	assert.Equal(t, uint16(1), sessions[0].PageViews)
+	assert.Equal(t, int8(1), sessions[1].Sign)
+	assert.Equal(t, uint16(3), sessions[1].PageViews)
+	pageViews := store.GetPageViews()
+	assert.Len(t, pageViews, 2)
+	assert.Equal(t, "/foo", pageViews[0].Path)
+	assert.Equal(t, "/bar", pageViews[1].Path)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", userAgent)
+	tracker.PageView(req, 0, Options{})
+	tracker.Stop()
+	assert.Len(t, store.GetPageViews(), 3)
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestTracker_WALStoreError(t *testing.T) {
+	dir := t.TempDir()
+	store := db.NewClientMock()
+	store.SetSaveError(errors.New("store unavailable"))
+	tracker := NewTracker(Config{
+		Store:        store,
+		StoreRetries: -1,
+		WALDir:       dir,
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", userAgent)
+	tracker.PageView(req, 0, Options{})
+	tracker.Stop()
+	assert.Empty(t, store.GetPageViews())
+	files, err := walFiles(dir)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, files)
+
+	// the replay fails as well, so the data must be kept in the WAL
+	tracker = NewTracker(Config{
+		Store:        store,
+		StoreRetries: -1,
+		WALDir:       dir,
+	})
+	assert.NotZero(t, tracker.wal.seq)
+	assert.Empty(t, store.GetPageViews())
+
+	// acknowledging new data must not acknowledge the data kept from the previous run
+	store.SetSaveError(nil)
+	req = httptest.NewRequest(http.MethodGet, "/foo", nil)
+	req.Header.Set("User-Agent", userAgent)
+	tracker.PageView(req, 0, Options{})
+	tracker.Stop()
+	assert.Len(t, store.GetPageViews(), 1)
+	files, err = walFiles(dir)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, files)
+
+	tracker = NewTracker(Config{
+		Store:  store,
+		WALDir: dir,
+	})
+	tracker.Stop()
+	pageViews := store.GetPageViews()
+	assert.Len(t, pageViews, 2)
+	assert.ElementsMatch(t, []string{"/", "/foo"}, []string{pageViews[0].Path, pageViews[1].Path})
+	files, err = walFiles(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+type eventErrorStore struct {
+	*db.ClientMock
+	err error
+}
+
+func (store *eventErrorStore) SaveEvents(events []model.Event) error {
+	if store.err != nil {
+		return store.err
+	}
+
+	return store.ClientMock.SaveEvents(events)
+}
+
+func TestTracker_WALPartialStoreError(t *testing.T) {
+	dir := t.TempDir()
+	store := &eventErrorStore{ClientMock: db.NewClientMock(), err: errors.New("store unavailable")}
+	tracker := NewTracker(Config{
+		Store:        store,
+		StoreRetries: -1,
+		WALDir:       dir,
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", userAgent)
+	tracker.PageView(req, 0, Options{})
+	tracker.Event(req, 0, EventOptions{Name: "event"}, Options{})
+	tracker.Stop()
+	assert.Len(t, store.GetPageViews(), 1)
+	assert.Empty(t, store.GetEvents())
+	sessions := len(store.GetSessions())
+
+	// only the events are replayed
+	store.err = nil
+	tracker = NewTracker(Config{
+		Store:  store,
+		WALDir: dir,
+	})
+	tracker.Stop()
+	assert.Len(t, store.GetPageViews(), 1)
+	assert.Len(t, store.GetSessions(), sessions)
+	assert.Len(t, store.GetEvents(), 1)
+	files, err := walFiles(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestTracker_WALRotate(t *testing.T) {
+	w, _, _, err := openWAL(t.TempDir())
+	assert.NoError(t, err)
+	refs := make([]walRef, 0, walMaxSegmentEntries+1)
+
+	for i := 0; i < walMaxSegmentEntries+1; i++ {
+		ref, err := w.append(data{bot: &model.Bot{ClientID: 1}})
+		assert.NoError(t, err)
+		refs = append(refs, ref)
+	}
+
+	assert.Len(t, w.segments, 2)
+	assert.NoError(t, w.ack(refs[:walMaxSegmentEntries-1]))
+	assert.Len(t, w.segments, 2)
+	assert.NoError(t, w.ack(refs[walMaxSegmentEntries-1:]))
+	assert.Len(t, w.segments, 1)
+	files, err := walFiles(w.dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	assert.NoError(t, w.close())
+	files, err = walFiles(w.dir)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestCollapseSessions(t *testing.T) {
+	sessions := []model.Session{
+		{Sign: -1, VisitorID: 1, PageViews: 1},
+		{Sign: 1, VisitorID: 1, PageViews: 2},
+		{Sign: 1, VisitorID: 2, PageViews: 1},
+		{Sign: -1, VisitorID: 1, PageViews: 2},
+		{Sign: 1, VisitorID: 1, PageViews: 3},
+		{Sign: -1, VisitorID: 2, PageViews: 1},
+		{Sign: 1, VisitorID: 2, PageViews: 2},
+	}
+	collapsed := collapseSessions(sessions)
+	assert.Len(t, collapsed, 3)
+	assert.Equal(t, model.Session{Sign: -1, VisitorID: 1, PageViews: 1}, collapsed[0])
+	assert.Equal(t, model.Session{Sign: 1, VisitorID: 1, PageViews: 3}, collapsed[1])
+	assert.Equal(t, model.Session{Sign: 1, VisitorID: 2, PageViews: 2}, collapsed[2])
+}